@@ -0,0 +1,105 @@
+// Command reconciliar compara tachos y centros entre MySQL y Neo4j e informa
+// nodos o filas huérfanas, coordenadas que no coinciden y custom ids
+// duplicados. Con -corregir arregla las inconsistencias de tachos y centros
+// tomando como verdadera la base indicada en -fuente. Usa las mismas
+// variables de entorno que la API para conectarse; no migra el esquema.
+//
+//	go run ./cmd/reconciliar
+//	go run ./cmd/reconciliar -corregir -fuente mysql -tipos sin_fila,coordenadas
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+)
+
+func main() {
+	corregir := flag.Bool("corregir", false, "corregir las inconsistencias de tachos y centros (sin esto solo informa)")
+	fuente := flag.String("fuente", "", "base verdadera al corregir: mysql o neo4j")
+	tipos := flag.String("tipos", "", "corregir solo estos tipos, separados por coma: sin_nodo, sin_fila, coordenadas, duplicado")
+	idTipo := flag.Int("id-tipo", 0, "id_tipo para crear en MySQL los tachos que solo están en Neo4j")
+	idEstado := flag.Int("id-estado", 0, "id_estado para crear en MySQL los tachos que solo están en Neo4j")
+	idTipoCentro := flag.Int("id-tipo-centro", 0, "id_tipo para crear en MySQL los centros que solo están en Neo4j")
+	salidaJSON := flag.Bool("json", false, "imprimir el reporte completo en JSON")
+	flag.Parse()
+
+	var request *services.ReconciliarRequest
+	if *corregir {
+		request = &services.ReconciliarRequest{Fuente: *fuente, IDTipo: *idTipo, IDEstado: *idEstado, IDTipoCentro: *idTipoCentro}
+		if *tipos != "" {
+			request.Tipos = strings.Split(*tipos, ",")
+		}
+		if err := services.ValidarReconciliar(request); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	config.ConnectDatabase()
+	if _, err := config.GetNeo4jDriver(); err != nil {
+		log.Fatalf("Error conectando a Neo4j: %v", err)
+	}
+	defer config.CloseNeo4jDriver()
+
+	reporte, err := services.Reconciliar(request)
+	if err != nil {
+		log.Fatalf("Error reconciliando: %v", err)
+	}
+
+	if *salidaJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reporte); err != nil {
+			log.Fatalf("Error serializando reporte: %v", err)
+		}
+		return
+	}
+	imprimirReporte(os.Stdout, reporte)
+}
+
+// imprimirReporte muestra el resumen y una fila por inconsistencia
+func imprimirReporte(w io.Writer, reporte *services.ReporteReconciliacion) {
+	fmt.Fprintf(w, "Tachos: %d en MySQL, %d en Neo4j. Centros: %d en MySQL, %d en Neo4j.\n",
+		reporte.TachosMySQL, reporte.TachosNeo4j, reporte.CentrosMySQL, reporte.CentrosNeo4j)
+	if reporte.Total == 0 {
+		fmt.Fprintln(w, "Sin inconsistencias.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Entidad\tTipo\tCustom ID\tDetalle\tResultado\n")
+	for _, i := range reporte.Inconsistencias {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", i.Entidad, i.Tipo, i.CustomID, i.Detalle, resultado(reporte.Fuente, i))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d inconsistencias", reporte.Total)
+	if reporte.Fuente != "" {
+		fmt.Fprintf(w, ", %d corregidas con %s como fuente", reporte.Corregidas, reporte.Fuente)
+	}
+	fmt.Fprintln(w, ".")
+}
+
+// resultado resume qué pasó con una inconsistencia
+func resultado(fuente string, i services.Inconsistencia) string {
+	switch {
+	case i.Corregida:
+		return "corregida"
+	case i.Error != "":
+		return "error: " + i.Error
+	case !i.Corregible:
+		return "solo informe"
+	case fuente != "":
+		return "sin corregir"
+	default:
+		return "-"
+	}
+}
@@ -0,0 +1,255 @@
+// Command simular compara estrategias de ruteo sobre datos históricos o
+// sintéticos, sin tocar las bases de producción. Carga tachos, centros y
+// camiones de archivos JSON o CSV (con encabezado, mismas columnas que los
+// campos JSON), reparte los tachos con el mismo planificador que la API e
+// imprime km totales, paradas y el balance entre camiones.
+//
+//	go run ./cmd/simular -tachos tachos.csv -camiones camiones.json \
+//		-centros centros.json -algoritmo todos -geojson rutas.geojson
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+)
+
+// algoritmoTodos corre la simulación con todos los algoritmos para compararlos
+const algoritmoTodos = "todos"
+
+func main() {
+	tachosPath := flag.String("tachos", "", "archivo de tachos (.json o .csv): id, lat, lng, capacidad, prioridad, ...")
+	camionesPath := flag.String("camiones", "", "archivo de camiones (.json o .csv): id_camion, id_estado, id_tipo")
+	centrosPath := flag.String("centros", "", "archivo de centros de descarga (.json o .csv): id_centro, nombre_tipo, latitud, longitud")
+	tiposPath := flag.String("tipos", "", "archivo de tipos de camión (.json o .csv): id_tipo, nombre_tipo, capacidad_litros")
+	algoritmo := flag.String("algoritmo", services.AlgoritmoPorDefecto, "simple, vecino, 2opt, completo o todos")
+	minCapacidad := flag.Float64("min-capacidad", 0, "omitir tachos con menor llenado (%), salvo los urgentes")
+	pesoPrioridad := flag.Float64("peso-prioridad", -1, "visitar primero los urgentes con este peso de prioridad (0-1); negativo no prioriza")
+	maxCamiones := flag.Int("max-camiones", 0, "cantidad máxima de camiones a usar (0 = todos)")
+	origen := flag.String("origen", "", "punto de partida lat,lng")
+	destino := flag.String("destino", "", "destino final lat,lng")
+	geojsonPath := flag.String("geojson", "", "escribir las rutas en GeoJSON (con -algoritmo todos, un archivo por algoritmo)")
+	salidaJSON := flag.Bool("json", false, "imprimir el resultado completo en JSON")
+	flag.Parse()
+
+	if *tachosPath == "" || *camionesPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	escenario, err := cargarEscenario(*tachosPath, *camionesPath, *centrosPath, *tiposPath)
+	if err != nil {
+		log.Fatalf("Error cargando escenario: %v", err)
+	}
+
+	opciones := services.RutaOpciones{MinCapacidad: *minCapacidad}
+	if *pesoPrioridad >= 0 {
+		opciones.Priorizar = true
+		opciones.PesoPrioridad = *pesoPrioridad
+	}
+	if opciones.Origen, err = parsePosicion(*origen, services.TipoPuntoOrigen); err != nil {
+		log.Fatalf("Origen inválido: %v", err)
+	}
+	if opciones.Destino, err = parsePosicion(*destino, services.TipoPuntoDestino); err != nil {
+		log.Fatalf("Destino inválido: %v", err)
+	}
+
+	algoritmos := []string{*algoritmo}
+	if *algoritmo == algoritmoTodos {
+		algoritmos = []string{services.AlgoritmoSimple, services.AlgoritmoVecino, services.Algoritmo2Opt, services.AlgoritmoCompleto}
+	}
+
+	resultados := make([]*services.ResultadoSimulacion, 0, len(algoritmos))
+	for _, alg := range algoritmos {
+		opciones.Algoritmo = alg
+		resultado, err := services.SimularFlota(*escenario, opciones, *maxCamiones)
+		if err != nil {
+			log.Fatalf("Error simulando con %s: %v", alg, err)
+		}
+		resultados = append(resultados, resultado)
+
+		if *geojsonPath != "" {
+			path := *geojsonPath
+			if len(algoritmos) > 1 {
+				ext := filepath.Ext(path)
+				path = strings.TrimSuffix(path, ext) + "-" + alg + ext
+			}
+			if err := escribirGeoJSON(path, resultado.Flota); err != nil {
+				log.Fatalf("Error escribiendo GeoJSON: %v", err)
+			}
+		}
+	}
+
+	if *salidaJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(resultados); err != nil {
+			log.Fatalf("Error serializando resultado: %v", err)
+		}
+		return
+	}
+	imprimirResultados(os.Stdout, len(escenario.Tachos), resultados)
+}
+
+// cargarEscenario lee los archivos de la simulación; centros y tipos son opcionales
+func cargarEscenario(tachosPath, camionesPath, centrosPath, tiposPath string) (*services.Escenario, error) {
+	escenario := &services.Escenario{}
+	if err := cargarFixture(tachosPath, &escenario.Tachos); err != nil {
+		return nil, err
+	}
+	if err := cargarFixture(camionesPath, &escenario.Camiones); err != nil {
+		return nil, err
+	}
+	if centrosPath != "" {
+		if err := cargarFixture(centrosPath, &escenario.Centros); err != nil {
+			return nil, err
+		}
+	}
+	if tiposPath != "" {
+		var tipos []services.TipoCamion
+		if err := cargarFixture(tiposPath, &tipos); err != nil {
+			return nil, err
+		}
+		escenario.Tipos = make(map[int]services.TipoCamion, len(tipos))
+		for _, tipo := range tipos {
+			escenario.Tipos[tipo.IDTipo] = tipo
+		}
+	}
+	return escenario, nil
+}
+
+// cargarFixture lee un slice desde un archivo JSON (array de objetos) o CSV.
+// Las columnas del CSV son los nombres JSON de los campos de destino.
+func cargarFixture(path string, destino interface{}) error {
+	archivo, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer archivo.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = leerCSV(archivo, destino)
+	} else {
+		err = json.NewDecoder(archivo).Decode(destino)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// leerCSV convierte cada fila en un objeto JSON según el tipo de cada campo
+// del destino y lo decodifica con las mismas reglas que un fixture JSON
+func leerCSV(r io.Reader, destino interface{}) error {
+	filas, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(filas) == 0 {
+		return fmt.Errorf("archivo vacío")
+	}
+
+	tipos := tiposColumnas(destino)
+	encabezado := filas[0]
+	objetos := make([]map[string]interface{}, 0, len(filas)-1)
+	for n, fila := range filas[1:] {
+		objeto := make(map[string]interface{}, len(encabezado))
+		for i, columna := range encabezado {
+			columna = strings.TrimSpace(columna)
+			valor := strings.TrimSpace(fila[i])
+			kind, ok := tipos[columna]
+			switch {
+			case !ok || valor == "":
+				continue
+			case kind == reflect.String:
+				objeto[columna] = valor
+			default:
+				numero, err := strconv.ParseFloat(valor, 64)
+				if err != nil {
+					return fmt.Errorf("fila %d, columna %s: número inválido %q", n+2, columna, valor)
+				}
+				objeto[columna] = numero
+			}
+		}
+		objetos = append(objetos, objeto)
+	}
+
+	raw, err := json.Marshal(objetos)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, destino)
+}
+
+// tiposColumnas devuelve el tipo de cada campo (por nombre JSON) del slice destino
+func tiposColumnas(destino interface{}) map[string]reflect.Kind {
+	elemento := reflect.TypeOf(destino).Elem().Elem()
+	tipos := make(map[string]reflect.Kind, elemento.NumField())
+	for i := 0; i < elemento.NumField(); i++ {
+		campo := elemento.Field(i)
+		nombre := strings.Split(campo.Tag.Get("json"), ",")[0]
+		if nombre == "" || nombre == "-" {
+			continue
+		}
+		tipos[nombre] = campo.Type.Kind()
+	}
+	return tipos
+}
+
+// parsePosicion lee un punto lat,lng; vacío devuelve nil
+func parsePosicion(valor, tipo string) (*services.Point, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	partes := strings.Split(valor, ",")
+	if len(partes) != 2 {
+		return nil, fmt.Errorf("%q: formato lat,lng", valor)
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(partes[0]), 64)
+	lng, errLng := strconv.ParseFloat(strings.TrimSpace(partes[1]), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("%q: coordenadas inválidas", valor)
+	}
+	return &services.Point{Tipo: tipo, Nombre: tipo, Lat: lat, Lng: lng}, nil
+}
+
+// escribirGeoJSON guarda las rutas de la flota en un FeatureCollection
+func escribirGeoJSON(path string, flota *services.RutasFlota) error {
+	contenido, err := services.ExportarFlotaGeoJSON(flota)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contenido, 0o644)
+}
+
+// imprimirResultados muestra una tabla comparativa y el balance por camión
+func imprimirResultados(w io.Writer, tachos int, resultados []*services.ResultadoSimulacion) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Algoritmo\tCamiones\tParadas\tOmitidos\tTotal km\tMáx km\tPromedio km\n")
+	for _, r := range resultados {
+		fmt.Fprintf(tw, "%s\t%d\t%d/%d\t%d\t%.2f\t%.2f\t%.2f\n", r.Flota.Algoritmo, r.Flota.Camiones,
+			r.Paradas, tachos, r.Flota.Omitidos, r.Flota.DistanciaTotal, r.Flota.DistanciaMaxima, r.Promedio)
+	}
+	tw.Flush()
+
+	for _, r := range resultados {
+		fmt.Fprintf(w, "\nBalance %s (%s)\n", r.Flota.Algoritmo, services.TipoDistancia())
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Camión\tTachos\tDescargas\tkm\tDesvío\n")
+		for _, b := range r.Balance {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%.2f\t%+.1f%%\n", b.IDCamion, b.Tachos, b.Descargas, b.Distancia, b.Desvio)
+		}
+		tw.Flush()
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCargarEscenario(t *testing.T) {
+	escenario, err := cargarEscenario("testdata/tachos.csv", "testdata/camiones.json", "testdata/centros.json", "testdata/tipos.csv")
+	require.NoError(t, err)
+
+	require.Len(t, escenario.Tachos, 8)
+	assert.Equal(t, 3, escenario.Tachos[2].ID)
+	assert.Equal(t, "Jorge Newbery 3800", escenario.Tachos[2].Direccion)
+	assert.Equal(t, -34.5820, escenario.Tachos[2].Lat)
+	assert.Equal(t, 4, escenario.Tachos[2].Prioridad)
+	assert.Len(t, escenario.Camiones, 3)
+	assert.Len(t, escenario.Centros, 2)
+	assert.Equal(t, 6000.0, escenario.Tipos[2].CapacidadLitros)
+}
+
+func TestLeerCSVNumeroInvalido(t *testing.T) {
+	var tachos []services.Point
+	err := leerCSV(strings.NewReader("id,lat,lng\n1,-34.6,abc\n"), &tachos)
+	assert.ErrorContains(t, err, "fila 2, columna lng")
+}
+
+func TestSimularEscenario(t *testing.T) {
+	escenario, err := cargarEscenario("testdata/tachos.csv", "testdata/camiones.json", "testdata/centros.json", "testdata/tipos.csv")
+	require.NoError(t, err)
+
+	resultado, err := services.SimularFlota(*escenario, services.RutaOpciones{Algoritmo: services.AlgoritmoCompleto, MinCapacidad: 50}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, resultado.Flota.Camiones, "el camión fuera de servicio no se usa")
+	assert.Equal(t, 2, resultado.Flota.Omitidos)
+	assert.Equal(t, 6, resultado.Paradas)
+	require.Len(t, resultado.Balance, 2)
+	assert.InDelta(t, 0, resultado.Balance[0].Desvio+resultado.Balance[1].Desvio, 0.2)
+
+	var salida bytes.Buffer
+	imprimirResultados(&salida, len(escenario.Tachos), []*services.ResultadoSimulacion{resultado})
+	assert.Contains(t, salida.String(), "6/8")
+}
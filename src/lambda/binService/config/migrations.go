@@ -0,0 +1,74 @@
+package config
+
+import (
+	"log"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+)
+
+// barriosIniciales es el mapeo zona -> barrio que antes estaba fijo en el código.
+// Se usa solo para poblar Zona_barrio la primera vez.
+var barriosIniciales = map[int64]string{
+	1: "CHACARITA",
+	2: "MONTE CASTRO",
+	3: "BOEDO",
+	4: "VILLA CRESPO",
+}
+
+// MigrateDatabase crea las tablas auxiliares que maneja la aplicación
+func MigrateDatabase() {
+	if DB == nil {
+		log.Println("⚠️  DB no disponible, se omiten las migraciones")
+		return
+	}
+
+	if err := DB.AutoMigrate(&models.ZonaBarrio{}, &models.ZonaLimite{}, &models.ZonaCamion{}, &models.TachoZona{},
+		&models.RutaPlan{}, &models.RutaParada{}, &models.RutaRevision{}, &models.TachoVentana{},
+		&models.ZonaCalendario{}, &models.Feriado{}, &models.OutboxEvento{}); err != nil {
+		log.Printf("❌ Error migrando tablas: %v", err)
+		return
+	}
+
+	agregarCapacidadTipoCamion()
+	seedZonaBarrios()
+}
+
+// agregarCapacidadTipoCamion agrega a Tipo_camion la capacidad de carga en
+// litros. Tipo_camion no la maneja la aplicación, así que solo se agrega la
+// columna; 0 significa que se usa la capacidad por defecto.
+func agregarCapacidadTipoCamion() {
+	if DB.Migrator().HasColumn("Tipo_camion", "capacidad") {
+		return
+	}
+	if err := DB.Exec("ALTER TABLE Tipo_camion ADD COLUMN capacidad DOUBLE NOT NULL DEFAULT 0").Error; err != nil {
+		log.Printf("❌ Error agregando capacidad a Tipo_camion: %v", err)
+		return
+	}
+	log.Println("✅ Tipo_camion.capacidad agregada")
+}
+
+// seedZonaBarrios carga el mapeo inicial si la tabla Zona_barrio está vacía
+func seedZonaBarrios() {
+	var count int64
+	if err := DB.Model(&models.ZonaBarrio{}).Count(&count).Error; err != nil || count > 0 {
+		return
+	}
+
+	zonas, err := getZonasDisponibles()
+	if err != nil {
+		log.Printf("Error getting zones for Zona_barrio seed: %v", err)
+		return
+	}
+
+	for _, zona := range zonas {
+		barrio, ok := barriosIniciales[int64(zona.ID)]
+		if !ok {
+			continue
+		}
+		if err := DB.Create(&models.ZonaBarrio{IDZona: int64(zona.ID), Barrio: barrio}).Error; err != nil {
+			log.Printf("Error seeding barrio %s for zona %d: %v", barrio, zona.ID, err)
+		}
+	}
+
+	log.Println("✅ Zona_barrio inicializada")
+}
@@ -73,7 +73,7 @@ func InitializePersonsData() {
 	log.Println("Initializing personas data from MySQL...")
 
 	// Obtener camiones operativos desde MySQL
-	camionesOperativos, err := getCamionesOperativos()
+	camionesOperativos, err := GetCamionesOperativos()
 	if err != nil {
 		log.Printf("Error getting operational trucks: %v", err)
 		return
@@ -186,8 +186,8 @@ func showPersonsSummary() {
 	log.Println("====================================")
 }
 
-// getCamionesOperativos obtiene camiones operativos con JOIN desde MySQL usando GORM
-func getCamionesOperativos() ([]CamionOperativo, error) {
+// GetCamionesOperativos obtiene camiones operativos con JOIN desde MySQL usando GORM
+func GetCamionesOperativos() ([]CamionOperativo, error) {
 	if DB == nil {
 		return nil, fmt.Errorf("database connection not available")
 	}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+	// Incluye la base de zonas horarias: la imagen del contenedor no la trae
+	_ "time/tzdata"
+)
+
+// zonaHorariaPorDefecto es la de la ciudad donde operan los camiones
+const zonaHorariaPorDefecto = "America/Argentina/Buenos_Aires"
+
+var (
+	zonaHoraria     *time.Location
+	zonaHorariaOnce sync.Once
+)
+
+// ZonaHoraria es la zona en la que se leen los calendarios, los turnos y las
+// fechas de la API. Se toma de TZ; el contenedor corre en UTC, así que sin TZ
+// se usa la de Buenos Aires.
+func ZonaHoraria() *time.Location {
+	zonaHorariaOnce.Do(func() {
+		nombre := os.Getenv("TZ")
+		if nombre == "" {
+			nombre = zonaHorariaPorDefecto
+		}
+		loc, err := time.LoadLocation(nombre)
+		if err != nil {
+			log.Printf("⚠️  Zona horaria %q inválida, usando %s: %v", nombre, zonaHorariaPorDefecto, err)
+			loc, _ = time.LoadLocation(zonaHorariaPorDefecto)
+		}
+		zonaHoraria = loc
+	})
+	return zonaHoraria
+}
@@ -24,21 +24,41 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/camiones": {
+        "/admin/outbox": {
             "get": {
-                "description": "Obtiene una lista de todos los camiones con información de tipo y estado mediante JOINs",
+                "description": "Devuelve los últimos eventos de sincronización con Neo4j y el resumen (pendientes, fallidos y lag). Los pendientes se reintentan solos con espera creciente; los fallidos (dead-letter) agotaron los reintentos y retienen los eventos siguientes de su recurso hasta que se reintenten o se descarten.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Camiones"
+                    "Admin"
+                ],
+                "summary": "Listar eventos del outbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filtrar por estado (pendiente, aplicado, fallido, descartado)",
+                        "name": "estado",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Obtener todos los camiones",
                 "responses": {
                     "200": {
-                        "description": "Lista de camiones obtenida exitosamente",
+                        "description": "Eventos del outbox",
                         "schema": {
-                            "$ref": "#/definitions/services.CamionesResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.EventoOutbox"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Estado inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
@@ -53,20 +73,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/camiones/{id}": {
-            "get": {
-                "description": "Obtiene la información completa de un camión específico mediante su ID, incluyendo tipo y estado",
+        "/admin/outbox/{id}/descartar": {
+            "post": {
+                "description": "Marca como descartado un evento fallido (dead-letter) para que se apliquen los eventos siguientes del mismo recurso. Lo que el evento no aplicó se puede corregir con /admin/reconciliacion.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Camiones"
+                    "Admin"
                 ],
-                "summary": "Obtener un camión por ID",
+                "summary": "Descartar evento fallido del outbox",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID del camión",
+                        "description": "ID del evento",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -74,13 +94,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Camión obtenido exitosamente",
+                        "description": "Evento descartado",
                         "schema": {
-                            "$ref": "#/definitions/services.CamionResponse"
+                            "$ref": "#/definitions/services.EventoOutbox"
                         }
                     },
                     "400": {
-                        "description": "ID de camión inválido",
+                        "description": "ID inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -89,7 +109,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Camión no encontrado",
+                        "description": "Evento no encontrado",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -97,34 +117,14 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Error interno del servidor",
+                    "409": {
+                        "description": "El evento no está fallido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/centros": {
-            "get": {
-                "description": "Obtiene una lista de todos los centros con información de tipo (MySQL) y datos adicionales como nombre, barrio, dirección, coordenadas (Neo4j)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Centros"
-                ],
-                "summary": "Obtener todos los centros",
-                "responses": {
-                    "200": {
-                        "description": "Lista de centros obtenida exitosamente",
-                        "schema": {
-                            "$ref": "#/definitions/services.CentrosResponse"
-                        }
                     },
                     "500": {
                         "description": "Error interno del servidor",
@@ -138,20 +138,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/centros/{id}": {
-            "get": {
-                "description": "Obtiene la información completa de un centro específico mediante su ID, incluyendo tipo (MySQL) y datos adicionales como nombre, barrio, dirección, coordenadas (Neo4j)",
+        "/admin/outbox/{id}/reintentar": {
+            "post": {
+                "description": "Vuelve a poner en cola un evento pendiente o fallido y lo aplica ahora en Neo4j, después de los eventos anteriores del mismo recurso",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Centros"
+                    "Admin"
                 ],
-                "summary": "Obtener un centro por ID",
+                "summary": "Reintentar evento del outbox",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID del centro",
+                        "description": "ID del evento",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -159,13 +159,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Centro obtenido exitosamente",
+                        "description": "Evento reintentado",
                         "schema": {
-                            "$ref": "#/definitions/services.CentroResponse"
+                            "$ref": "#/definitions/services.EventoOutbox"
                         }
                     },
                     "400": {
-                        "description": "ID de centro inválido",
+                        "description": "ID inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -174,7 +174,16 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Centro no encontrado",
+                        "description": "Evento no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El evento ya está aplicado",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -194,9 +203,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/enviar-emergencia": {
+        "/admin/reconciliacion": {
+            "get": {
+                "description": "Informa filas de MySQL sin nodo en Neo4j, nodos sin fila, nodos sin ubicación o con una ubicación distinta de la registrada en MySQL y custom ids duplicados. No modifica nada.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reporte de consistencia MySQL/Neo4j",
+                "responses": {
+                    "200": {
+                        "description": "Inconsistencias encontradas",
+                        "schema": {
+                            "$ref": "#/definitions/services.ReporteReconciliacion"
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Registra una nueva emergencia en el sistema",
+                "description": "Corrige las inconsistencias de tachos y centros tomando como verdadera la base indicada en fuente (mysql o neo4j): borra o recrea el lado que sobra o falta y vuelve a escribir la ubicación. Los cambios en Neo4j pasan por el outbox. Los duplicados solo se corrigen en la otra base: se conserva la fila más antigua o el nodo de menor elementId. Para crear en MySQL lo que solo está en Neo4j hacen falta id_tipo e id_estado (tachos) o id_tipo_centro (centros). El reporte indica el resultado de cada inconsistencia.",
                 "consumes": [
                     "application/json"
                 ],
@@ -204,29 +240,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Emergencias"
+                    "Admin"
                 ],
-                "summary": "Enviar emergencia",
+                "summary": "Corregir inconsistencias MySQL/Neo4j",
                 "parameters": [
                     {
-                        "description": "Datos de la emergencia",
-                        "name": "emergencia",
+                        "description": "Fuente de verdad y tipos a corregir",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.RequestBody"
+                            "$ref": "#/definitions/services.ReconciliarRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Emergencia enviada correctamente",
+                        "description": "Inconsistencias y resultado de la corrección",
                         "schema": {
-                            "$ref": "#/definitions/handlers.ResponseBody"
+                            "$ref": "#/definitions/services.ReporteReconciliacion"
                         }
                     },
                     "400": {
-                        "description": "Datos inválidos",
+                        "description": "Fuente o tipo inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -237,27 +282,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/personas": {
+        "/camiones": {
             "get": {
-                "description": "Devuelve la lista completa de personas con sus asignaciones",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Obtiene una lista de todos los camiones con información de tipo y estado mediante JOINs",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Personas"
+                    "Camiones"
                 ],
-                "summary": "Obtener todas las personas",
+                "summary": "Obtener todos los camiones",
                 "responses": {
                     "200": {
-                        "description": "Lista de personas",
+                        "description": "Lista de camiones obtenida exitosamente",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handlers.PersonaResponse"
-                            }
+                            "$ref": "#/definitions/services.CamionesResponse"
                         }
                     },
                     "500": {
@@ -272,35 +311,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/personas/zona/{zona}": {
+        "/camiones/{id}": {
             "get": {
-                "description": "Devuelve todas las personas asignadas a una zona específica",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Obtiene la información completa de un camión específico mediante su ID, incluyendo tipo y estado",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Personas"
+                    "Camiones"
                 ],
-                "summary": "Obtener personas por zona",
+                "summary": "Obtener un camión por ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Número de zona",
-                        "name": "zona",
+                        "description": "ID del camión",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Personas de la zona",
+                        "description": "Camión obtenido exitosamente",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handlers.PersonaResponse"
+                            "$ref": "#/definitions/services.CamionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de camión inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Camión no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -316,42 +367,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/personas/{id}": {
+        "/centros": {
             "get": {
-                "description": "Devuelve los datos de una persona específica",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Obtiene una lista de todos los centros con información de tipo (MySQL) y datos adicionales como nombre, barrio, dirección, coordenadas (Neo4j)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Personas"
-                ],
-                "summary": "Obtener persona por ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID de la persona",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Centros"
                 ],
+                "summary": "Obtener todos los centros",
                 "responses": {
                     "200": {
-                        "description": "Datos de la persona",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.PersonaResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Persona no encontrada",
+                        "description": "Lista de centros obtenida exitosamente",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/services.CentrosResponse"
                         }
                     },
                     "500": {
@@ -366,41 +396,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/ruta-optima": {
+        "/centros/{id}": {
             "get": {
-                "description": "Devuelve la ruta óptima y distancias para la zona de la persona asociada al email",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Obtiene la información completa de un centro específico mediante su ID, incluyendo tipo (MySQL) y datos adicionales como nombre, barrio, dirección, coordenadas (Neo4j)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rutas"
+                    "Centros"
                 ],
-                "summary": "Obtener ruta óptima por email",
+                "summary": "Obtener un centro por ID",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "email del usuario",
-                        "name": "email",
-                        "in": "header",
+                        "type": "integer",
+                        "description": "ID del centro",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lista de puntos con distancias",
+                        "description": "Centro obtenido exitosamente",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "$ref": "#/definitions/services.CentroResponse"
                         }
                     },
                     "400": {
-                        "description": "Email faltante o inválido",
+                        "description": "ID de centro inválido",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -409,7 +432,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Usuario o persona no encontrada",
+                        "description": "Centro no encontrado",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -429,9 +452,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/ruta-optima/{zonaID}": {
-            "get": {
-                "description": "Devuelve la ruta óptima y distancias para una zona específica",
+        "/enviar-emergencia": {
+            "post": {
+                "description": "Registra una nueva emergencia en el sistema. Con id_zona se reoptimiza la ruta en curso de la zona como en /rutas/{id}/reoptimizar: el tacho indicado (id_tacho) pasa a urgente y, si no se envía la posición del camión (lat, lng), se usa la de la última parada atendida. Si la zona no tiene ruta en curso solo se registra la emergencia.",
                 "consumes": [
                     "application/json"
                 ],
@@ -439,31 +462,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Rutas"
+                    "Emergencias"
                 ],
-                "summary": "Obtener ruta óptima",
+                "summary": "Enviar emergencia",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID de la zona",
-                        "name": "zonaID",
-                        "in": "path",
-                        "required": true
+                        "description": "Datos de la emergencia",
+                        "name": "emergencia",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RequestBody"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Lista de puntos con distancias",
+                        "description": "Emergencia enviada correctamente",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "$ref": "#/definitions/handlers.ResponseBody"
                         }
                     },
                     "400": {
-                        "description": "zonaID inválido",
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "La ruta ya no admite cambios",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -483,26 +522,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/tachos": {
+        "/feriados": {
             "get": {
-                "description": "Devuelve todos los tachos con barrio, dirección, latitud, longitud, estado y capacidad",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Devuelve los feriados desde hoy. Con id_zona, solo los de esa zona y los generales (id_zona 0).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tachos"
+                    "Zonas"
+                ],
+                "summary": "Listar feriados",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id_zona",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Obtener todos los tachos",
                 "responses": {
                     "200": {
-                        "description": "Lista de todos los tachos",
+                        "description": "Feriados",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/services.TachoCompleto"
+                                "$ref": "#/definitions/services.FeriadoRequest"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -518,7 +571,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Crea un tacho guardándolo tanto en MySQL como en Neo4j",
+                "description": "Marca un día sin recolección para una zona o, con id_zona 0, para todas. Las rutas ya generadas para ese día que no salieron se cancelan.",
                 "consumes": [
                     "application/json"
                 ],
@@ -526,29 +579,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tachos"
+                    "Zonas"
                 ],
-                "summary": "Crear un nuevo tacho",
+                "summary": "Cargar feriado",
                 "parameters": [
                     {
-                        "description": "Datos del tacho a crear",
-                        "name": "tacho",
+                        "description": "Fecha (YYYY-MM-DD), zona y descripción",
+                        "name": "feriado",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/services.CreateTachoRequest"
+                            "$ref": "#/definitions/services.FeriadoRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Tacho creado exitosamente",
+                        "description": "Feriado guardado",
                         "schema": {
-                            "$ref": "#/definitions/services.CreateTachoResponse"
+                            "$ref": "#/definitions/services.FeriadoRequest"
                         }
                     },
                     "400": {
-                        "description": "Datos de entrada inválidos",
+                        "description": "Fecha inválida",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -566,42 +628,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/feriados/{fecha}": {
             "delete": {
-                "description": "Elimina un tacho tanto de MySQL como de Neo4j usando query parameters (custom_id O direccion+barrio)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Elimina el feriado de la fecha para la zona indicada (0 o sin id_zona para el general). Las corridas de ese día vuelven a generarse.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tachos"
+                    "Zonas"
                 ],
-                "summary": "Eliminar un tacho",
+                "summary": "Eliminar feriado",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID personalizado del tacho (direccion|barrio)",
-                        "name": "custom_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Dirección del tacho (requiere también barrio)",
-                        "name": "direccion",
-                        "in": "query"
+                        "description": "Fecha (YYYY-MM-DD)",
+                        "name": "fecha",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Barrio del tacho (requerido si se pasa direccion)",
-                        "name": "barrio",
+                        "type": "integer",
+                        "default": 0,
+                        "description": "ID de la zona",
+                        "name": "id_zona",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Tacho eliminado exitosamente",
+                        "description": "Feriado eliminado",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -610,7 +667,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Parámetros inválidos",
+                        "description": "Fecha o zona inválida",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -619,7 +676,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Tacho no encontrado en ninguna base",
+                        "description": "Feriado no encontrado",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -639,9 +696,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tachos/{id_tacho}/prioridad": {
-            "put": {
-                "description": "Actualiza el campo prioridad de un tacho en Neo4j",
+        "/personas": {
+            "get": {
+                "description": "Devuelve la lista completa de personas con sus asignaciones",
                 "consumes": [
                     "application/json"
                 ],
@@ -649,36 +706,106 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tachos"
+                    "Personas"
                 ],
-                "summary": "Actualizar prioridad del tacho",
+                "summary": "Obtener todas las personas",
+                "responses": {
+                    "200": {
+                        "description": "Lista de personas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.PersonaResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/personas/zona/{zona}": {
+            "get": {
+                "description": "Devuelve todas las personas asignadas a una zona específica",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personas"
+                ],
+                "summary": "Obtener personas por zona",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID del tacho",
-                        "name": "id_tacho",
+                        "description": "Número de zona",
+                        "name": "zona",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Personas de la zona",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.PersonaResponse"
+                            }
+                        }
                     },
-                    {
-                        "description": "Nueva prioridad del tacho",
-                        "name": "prioridad",
-                        "in": "body",
-                        "required": true,
+                    "500": {
+                        "description": "Error interno del servidor",
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdatePrioridadRequest"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/personas/{id}": {
+            "get": {
+                "description": "Devuelve los datos de una persona específica",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personas"
+                ],
+                "summary": "Obtener persona por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la persona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Prioridad actualizada correctamente",
+                        "description": "Datos de la persona",
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdatePrioridadResponse"
+                            "$ref": "#/definitions/handlers.PersonaResponse"
                         }
                     },
-                    "400": {
-                        "description": "Datos inválidos",
+                    "404": {
+                        "description": "Persona no encontrada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -687,7 +814,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error interno",
+                        "description": "Error interno del servidor",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -698,9 +825,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tachos/{id}/capacidad": {
+        "/personas/{id}/turno": {
             "put": {
-                "description": "Actualiza el campo capacidad de un tacho en MySQL",
+                "description": "Guarda el horario de trabajo (HH:MM) de la persona. La agenda de recorrido solo planifica paradas dentro del turno.",
                 "consumes": [
                     "application/json"
                 ],
@@ -708,36 +835,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tachos"
+                    "Personas"
                 ],
-                "summary": "Actualizar capacidad del tacho",
+                "summary": "Actualizar turno de una persona",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID del tacho",
+                        "description": "ID de la persona",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Nueva capacidad del tacho",
-                        "name": "capacidad",
+                        "description": "Inicio y fin del turno",
+                        "name": "turno",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdateCapacidadRequest"
+                            "$ref": "#/definitions/services.TurnoRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Capacidad actualizada correctamente",
+                        "description": "Turno actualizado",
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdateCapacidadResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Datos inválidos",
+                        "description": "ID u horario inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Persona no encontrada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -746,7 +885,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Error interno",
+                        "description": "Error interno del servidor",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -756,261 +895,4197 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.PersonaResponse": {
-            "type": "object",
-            "properties": {
-                "camion_id": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "zona_id": {
-                    "type": "string"
+        },
+        "/red-vial": {
+            "get": {
+                "description": "Indica si las rutas se calculan por calles (vial) o en línea recta (lineal) y el tamaño de la red cargada",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Estado de la red vial",
+                "responses": {
+                    "200": {
+                        "description": "Estado de la red vial",
+                        "schema": {
+                            "$ref": "#/definitions/services.EstadoRedVial"
+                        }
+                    }
                 }
             }
         },
-        "handlers.RequestBody": {
-            "type": "object",
-            "properties": {
-                "descripcion": {
-                    "type": "string",
-                    "example": "Incendio en edificio de oficinas"
-                },
-                "tipo": {
-                    "type": "string",
-                    "example": "incendio"
+        "/red-vial/importar": {
+            "post": {
+                "description": "Reemplaza la red de calles en Neo4j con un extracto OSM en XML o PBF (intersecciones como nodos, cuadras como relaciones con largo y mano). Acepta el archivo en el campo multipart \"archivo\" o como cuerpo del request. La red nueva se carga aparte y reemplaza a la anterior solo si la importación termina bien.",
+                "consumes": [
+                    "text/xml",
+                    "multipart/form-data",
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Importar red vial",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Extracto OSM (XML o PBF)",
+                        "name": "archivo",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Red vial importada",
+                        "schema": {
+                            "$ref": "#/definitions/services.ImportacionRedVial"
+                        }
+                    },
+                    "400": {
+                        "description": "Extracto inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ruta-optima": {
+            "get": {
+                "description": "Devuelve la ruta óptima y distancias para la zona de la persona asociada al email. Comparte la caché de Redis con /ruta-optima/{zonaID}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/geo+json",
+                    "application/gpx+xml",
+                    "application/vnd.google-earth.kml+xml"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Obtener ruta óptima por email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "email del usuario",
+                        "name": "email",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "completo",
+                        "description": "Algoritmo de ordenamiento (simple, vecino, 2opt, completo)",
+                        "name": "algoritmo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Omite tachos con menor llenado (%), salvo los de prioridad urgente",
+                        "name": "min_capacidad",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Visita primero los tachos urgentes; peso (0-1) de la prioridad frente al llenado",
+                        "name": "peso_prioridad",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "Formato de salida (json, geojson, gpx, kml); sin él se usa el header Accept",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lista de puntos con distancias",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        },
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "HIT si la ruta salió de la caché, MISS si se calculó"
+                            },
+                            "X-Computed-At": {
+                                "type": "string",
+                                "description": "Momento en que se calculó la ruta (RFC 3339)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Email faltante o inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Usuario, persona o zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ruta-optima/{zonaID}": {
+            "get": {
+                "description": "Devuelve la ruta óptima y la distancia total para una zona específica. La ruta se cachea en Redis hasta que cambian los tachos de la zona o vence el TTL.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/geo+json",
+                    "application/gpx+xml",
+                    "application/vnd.google-earth.kml+xml"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Obtener ruta óptima",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "zonaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "completo",
+                        "description": "Algoritmo de ordenamiento (simple, vecino, 2opt, completo)",
+                        "name": "algoritmo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del centro desde donde parte el camión",
+                        "name": "origen_centro",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitud de partida (requiere origen_lng)",
+                        "name": "origen_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitud de partida (requiere origen_lat)",
+                        "name": "origen_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del centro de descarga final",
+                        "name": "destino_centro",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del camión; activa el control de capacidad con descargas intermedias",
+                        "name": "camion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Omite tachos con menor llenado (%), salvo los de prioridad urgente",
+                        "name": "min_capacidad",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Visita primero los tachos urgentes; peso (0-1) de la prioridad frente al llenado",
+                        "name": "peso_prioridad",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "Formato de salida (json, geojson, gpx, kml); sin él se usa el header Accept",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta ordenada con distancia total",
+                        "schema": {
+                            "$ref": "#/definitions/services.Ruta"
+                        },
+                        "headers": {
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "HIT si la ruta salió de la caché, MISS si se calculó"
+                            },
+                            "X-Computed-At": {
+                                "type": "string",
+                                "description": "Momento en que se calculó la ruta (RFC 3339)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "zonaID, algoritmo, formato, camión u origen/destino inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona, centro o camión no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ruta-optima/{zonaID}/agenda": {
+            "get": {
+                "description": "Arma el recorrido de la zona con llegada, espera e inicio estimados por parada, respetando las ventanas horarias de los tachos y el turno de la persona. Informa los tachos que no entran y el motivo (fuera_de_ventana, fuera_de_turno). Si la fecha es hoy el recorrido arranca ahora. El orden lo deciden los horarios, así que no acepta algoritmo, camion ni peso_prioridad.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Obtener agenda de recorrido",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "zonaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID de la persona cuyo turno se respeta (sin ella se usa el día completo)",
+                        "name": "persona",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Día del recorrido (YYYY-MM-DD), por defecto hoy",
+                        "name": "fecha",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del centro desde donde parte el camión",
+                        "name": "origen_centro",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitud de partida (requiere origen_lng)",
+                        "name": "origen_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitud de partida (requiere origen_lat)",
+                        "name": "origen_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del centro de descarga final",
+                        "name": "destino_centro",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Omite tachos con menor llenado (%), salvo los de prioridad urgente",
+                        "name": "min_capacidad",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recorrido con horarios",
+                        "schema": {
+                            "$ref": "#/definitions/services.Agenda"
+                        }
+                    },
+                    "400": {
+                        "description": "Parámetros inválidos o que no se aplican a la agenda",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona, centro o persona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/ruta-optima/{zonaID}/camiones": {
+            "get": {
+                "description": "Divide los tachos de la zona entre los camiones operativos asignados a la zona (o todos los operativos si la zona no tiene camiones asignados) y devuelve una ruta balanceada por camión",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Obtener rutas por camión",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "zonaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad máxima de camiones a usar (por defecto todos los disponibles para la zona)",
+                        "name": "camiones",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "completo",
+                        "description": "Algoritmo de ordenamiento (simple, vecino, 2opt, completo)",
+                        "name": "algoritmo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del centro desde donde parten los camiones",
+                        "name": "origen_centro",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitud de partida (requiere origen_lng)",
+                        "name": "origen_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitud de partida (requiere origen_lat)",
+                        "name": "origen_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del centro de descarga final",
+                        "name": "destino_centro",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Omite tachos con menor llenado (%), salvo los de prioridad urgente",
+                        "name": "min_capacidad",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Visita primero los tachos urgentes; peso (0-1) de la prioridad frente al llenado",
+                        "name": "peso_prioridad",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rutas por camión",
+                        "schema": {
+                            "$ref": "#/definitions/services.RutasFlota"
+                        }
+                    },
+                    "400": {
+                        "description": "Parámetros inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona o centro de origen/destino no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas": {
+            "get": {
+                "description": "Lista las rutas guardadas (sin paradas), de la más nueva a la más vieja, con filtros opcionales",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Listar rutas planificadas",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Día de creación (YYYY-MM-DD)",
+                        "name": "fecha",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "zona",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID del camión",
+                        "name": "camion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID de la persona",
+                        "name": "persona",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Estado (planificada, en_curso, completada, cancelada)",
+                        "name": "estado",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rutas guardadas",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Filtro inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Calcula la ruta de una zona y la guarda como planificada, asignada a un camión y una persona. Si se indica persona, su zona y camión se usan cuando no vienen en el pedido. La ruta sale ahora: los tachos que no entran en sus ventanas horarias o en el turno de la persona quedan omitidos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Planificar una ruta",
+                "parameters": [
+                    {
+                        "description": "Zona, asignación y opciones de cálculo",
+                        "name": "ruta",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CrearPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Ruta planificada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona, camión, centro o persona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}": {
+            "get": {
+                "description": "Devuelve una ruta guardada con su estado, paradas en orden y polilínea",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Obtener una ruta planificada",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta guardada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de ruta inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}/cancelar": {
+            "post": {
+                "description": "Cancela una ruta planificada o en curso. Las rutas completadas o ya canceladas no se pueden cancelar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Cancelar una ruta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo de la cancelación",
+                        "name": "cancelacion",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/services.CancelarPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta cancelada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de ruta inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "La ruta no se puede cancelar en su estado actual",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}/paradas/{secuencia}/bloquear": {
+            "post": {
+                "description": "Marca la parada de un tacho como bloqueada (no se pudo acceder), con el motivo. Una parada bloqueada se puede recolectar u omitir después; la ruta no se completa mientras tenga paradas bloqueadas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Bloquear parada",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número de parada en la ruta",
+                        "name": "secuencia",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo",
+                        "name": "parada",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.MarcarParadaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "Parámetros inválidos, falta el motivo o la parada no es un tacho",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta o parada no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "La ruta o la parada ya no admite cambios",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}/paradas/{secuencia}/omitir": {
+            "post": {
+                "description": "Marca la parada de un tacho como omitida, con el motivo. Se actualizan el progreso y la hora estimada de llegada.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Omitir parada",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número de parada en la ruta",
+                        "name": "secuencia",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo",
+                        "name": "parada",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.MarcarParadaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "Parámetros inválidos, falta el motivo o la parada no es un tacho",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta o parada no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "La ruta o la parada ya no admite cambios",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}/paradas/{secuencia}/recolectar": {
+            "post": {
+                "description": "Marca la parada de un tacho como recolectada y deja la capacidad del tacho en 0. La primera parada marcada inicia la ruta y la última la completa; se actualizan el progreso y la hora estimada de llegada.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Marcar parada recolectada",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Número de parada en la ruta",
+                        "name": "secuencia",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta actualizada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "Parámetros inválidos o la parada no es un tacho",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta o parada no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "La ruta o la parada ya no admite cambios",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}/reoptimizar": {
+            "post": {
+                "description": "Ante una emergencia o una calle bloqueada, recalcula el resto de la ruta desde la posición actual del camión. Los tachos urgentes (id_tacho) se visitan primero y se agregan si no estaban en la ruta; los quitados y las paradas bloqueadas salen del recorrido. Las paradas ya atendidas se conservan; si la ruta tiene control de capacidad los viajes de descarga se recalculan partiendo de la carga actual del camión. Cada reoptimización queda guardada como una nueva revisión.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Reoptimizar ruta en curso",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Posición actual y tachos urgentes o quitados",
+                        "name": "cambios",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ReoptimizarRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ruta reoptimizada",
+                        "schema": {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    },
+                    "400": {
+                        "description": "Posición o tachos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta o tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "La ruta ya no admite cambios",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rutas/{id}/revisiones": {
+            "get": {
+                "description": "Devuelve todas las versiones del recorrido de la ruta: el plan original y cada reoptimización, con su motivo, la posición del camión y las paradas completas.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rutas"
+                ],
+                "summary": "Historial de revisiones de una ruta",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la ruta",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revisiones de la ruta",
+                        "schema": {
+                            "$ref": "#/definitions/services.RevisionesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Ruta no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tachos": {
+            "get": {
+                "description": "Devuelve todos los tachos con barrio, dirección, latitud, longitud, estado y capacidad",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Obtener todos los tachos",
+                "responses": {
+                    "200": {
+                        "description": "Lista de todos los tachos",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.TachoCompleto"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Crea el tacho en MySQL y deja su alta en Neo4j en el outbox, en la misma transacción. El nodo se crea enseguida si Neo4j responde (neo_node_id); si no, lo crea el worker del outbox con reintentos (ver /admin/outbox).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Crear un nuevo tacho",
+                "parameters": [
+                    {
+                        "description": "Datos del tacho a crear",
+                        "name": "tacho",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CreateTachoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Tacho creado exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/services.CreateTachoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos de entrada inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Ya existe un tacho en esa dirección y barrio",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Elimina un tacho por id_tacho o por custom id exacto (custom_id O direccion+barrio). La baja en Neo4j pasa por el outbox: si Neo4j no responde la aplica después el worker (ver /admin/outbox). Al borrar por id_tacho, el nodo se conserva si otra fila usa el mismo custom id. Con dry_run=true solo informa qué se borraría. La respuesta informa el resultado en cada base.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Eliminar un tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho en MySQL",
+                        "name": "id_tacho",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID personalizado del tacho (direccion|barrio)",
+                        "name": "custom_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dirección del tacho (requiere también barrio)",
+                        "name": "direccion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Barrio del tacho (requerido si se pasa direccion)",
+                        "name": "barrio",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Solo mostrar qué se borraría",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tacho eliminado (o vista previa)",
+                        "schema": {
+                            "$ref": "#/definitions/services.DeleteTachoResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Eliminado de MySQL; la baja en Neo4j quedó pendiente",
+                        "schema": {
+                            "$ref": "#/definitions/services.DeleteTachoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Parámetros inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado en ninguna base",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tachos/{id_tacho}": {
+            "get": {
+                "description": "Devuelve el tacho con los datos de MySQL (tipo, estado, capacidad) y de Neo4j (ubicación, prioridad). en_neo4j indica si tiene nodo y sincronizacion_pendiente si hay cambios en el outbox que Neo4j todavía no tiene.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Obtener un tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id_tacho",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tacho",
+                        "schema": {
+                            "$ref": "#/definitions/services.TachoDetalle"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Cambia tipo, estado, capacidad (0-100), prioridad (1-5) o dirección. Los campos que no se envían quedan igual. Cambiar la dirección cambia el custom id (direccion|barrio) en MySQL, Neo4j, la zona asignada, las ventanas horarias y las paradas de los planes. Los cambios de Neo4j pasan por el outbox (ver /admin/outbox).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Actualizar un tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id_tacho",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Campos a cambiar",
+                        "name": "cambios",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.UpdateTachoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tacho actualizado",
+                        "schema": {
+                            "$ref": "#/definitions/services.TachoDetalle"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El nuevo custom id ya existe o el tacho no tiene nodo en Neo4j",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tachos/{id_tacho}/prioridad": {
+            "put": {
+                "description": "Actualiza el campo prioridad de un tacho (1-5). Como PATCH /tachos/{id_tacho}, el cambio pasa por MySQL y el outbox (ver /admin/outbox).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Actualizar prioridad del tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id_tacho",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nueva prioridad del tacho",
+                        "name": "prioridad",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdatePrioridadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Prioridad actualizada correctamente",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdatePrioridadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El tacho no tiene nodo en Neo4j",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tachos/{id_tacho}/reubicar": {
+            "post": {
+                "description": "Mueve el tacho a la nueva ubicación y recalcula su zona. Si se indican dirección o barrio el custom id cambia con ellos en MySQL, Neo4j, la zona asignada, las ventanas horarias y las paradas de los planes. Se invalidan las rutas de la zona anterior y de la nueva.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Reubicar un tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id_tacho",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nueva ubicación",
+                        "name": "ubicacion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ReubicarTachoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tacho reubicado",
+                        "schema": {
+                            "$ref": "#/definitions/services.TachoDetalle"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "El nuevo custom id ya existe o el tacho no tiene nodo en Neo4j",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tachos/{id_tacho}/ventanas": {
+            "get": {
+                "description": "Devuelve los horarios (HH:MM) en los que se puede vaciar el tacho. Sin ventanas se puede vaciar a cualquier hora.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Obtener ventanas horarias del tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id_tacho",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ventanas del tacho",
+                        "schema": {
+                            "$ref": "#/definitions/services.VentanasRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "ID inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Reemplaza los horarios (HH:MM) en los que se puede vaciar el tacho. Una lista vacía lo deja sin restricción. Las ventanas que cruzan la medianoche se cargan como dos.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Actualizar ventanas horarias del tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id_tacho",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nuevas ventanas del tacho",
+                        "name": "ventanas",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.VentanasRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ventanas actualizadas",
+                        "schema": {
+                            "$ref": "#/definitions/services.VentanasRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Tacho no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tachos/{id}/capacidad": {
+            "put": {
+                "description": "Actualiza el campo capacidad de un tacho en MySQL",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tachos"
+                ],
+                "summary": "Actualizar capacidad del tacho",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID del tacho",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Nueva capacidad del tacho",
+                        "name": "capacidad",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateCapacidadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Capacidad actualizada correctamente",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateCapacidadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas": {
+            "get": {
+                "description": "Obtiene todas las zonas con sus barrios, límite GeoJSON y camiones asignados",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Obtener todas las zonas",
+                "responses": {
+                    "200": {
+                        "description": "Lista de zonas obtenida exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonasResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Crea una zona con nombre, barrios, límite GeoJSON opcional (Polygon o MultiPolygon) y camiones asignados",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Crear una zona",
+                "parameters": [
+                    {
+                        "description": "Datos de la zona",
+                        "name": "zona",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Zona creada exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Camión no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/asignacion": {
+            "get": {
+                "description": "Calcula sin guardar la zona de cada tacho según los límites y lista los tachos fuera de toda zona o dentro de más de una",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Reporte de asignación de tachos a zonas",
+                "responses": {
+                    "200": {
+                        "description": "Reporte de asignación",
+                        "schema": {
+                            "$ref": "#/definitions/services.AsignacionReporte"
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Recalcula la zona de cada tacho por punto en polígono sobre su ubicación en Neo4j y guarda la asignación",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Reasignar tachos a zonas",
+                "responses": {
+                    "200": {
+                        "description": "Tachos reasignados",
+                        "schema": {
+                            "$ref": "#/definitions/services.AsignacionReporte"
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/limites": {
+            "post": {
+                "description": "Recibe un GeoJSON FeatureCollection con un Feature (Polygon o MultiPolygon) por zona, identificada por properties.id_zona o properties.nombre. Guarda los límites y reasigna los tachos por ubicación.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Importar límites de zonas",
+                "parameters": [
+                    {
+                        "description": "FeatureCollection con los límites",
+                        "name": "limites",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Límites importados y tachos reasignados",
+                        "schema": {
+                            "$ref": "#/definitions/services.ImportacionLimites"
+                        }
+                    },
+                    "400": {
+                        "description": "GeoJSON inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/{id}": {
+            "get": {
+                "description": "Obtiene una zona con sus barrios, límite GeoJSON y camiones asignados",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Obtener una zona por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Zona obtenida exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Reemplaza nombre, barrios, límite GeoJSON y camiones asignados de una zona",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Actualizar una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Datos de la zona",
+                        "name": "zona",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Zona actualizada exitosamente",
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Datos inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona o camión no encontrado",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Elimina una zona junto con sus barrios, límite y camiones asignados",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Eliminar una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Zona eliminada exitosamente",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/{id}/calendario": {
+            "get": {
+                "description": "Devuelve las reglas recurrentes de la zona y sus corridas de los próximos días. Cada corrida está pendiente, generada (con su id_ruta) o en feriado.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Calendario de recolección de una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 14,
+                        "description": "Días hacia adelante (máximo 90)",
+                        "name": "dias",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Calendario de la zona",
+                        "schema": {
+                            "$ref": "#/definitions/services.CalendarioZona"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona o días inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Reemplaza las reglas recurrentes de la zona (días de la semana y hora de salida, con camión, persona y algoritmo opcionales). Las rutas ya generadas por las reglas anteriores que todavía no salieron se cancelan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Actualizar calendario de recolección de una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reglas de recolección",
+                        "name": "calendario",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/services.CalendarioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Calendario actualizado",
+                        "schema": {
+                            "$ref": "#/definitions/services.CalendarioZona"
+                        }
+                    },
+                    "400": {
+                        "description": "Reglas inválidas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/{id}/calendario/generar": {
+            "post": {
+                "description": "Calcula y guarda como planificadas las rutas de las corridas pendientes de los próximos días, con el llenado actual de los tachos. Cada ruta sale a la hora de su corrida y omite los tachos que no entran en sus ventanas horarias o en el turno de la persona. Las corridas ya generadas o en feriado no se tocan. El servicio también las genera solo cada hora para los próximos 2 días.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Generar rutas programadas de una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 2,
+                        "description": "Días hacia adelante (máximo 90)",
+                        "name": "dias",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Corridas procesadas",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.CorridaCalendario"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona o días inválidos",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/{id}/matriz-distancias": {
+            "get": {
+                "description": "Devuelve la matriz de distancias (km) y duraciones estimadas (min) entre los tachos de la zona, guardada en Redis. Se actualiza al crear o eliminar tachos; con refrescar=true se recalcula completa.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Obtener matriz de distancias de una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Recalcular la matriz completa",
+                        "name": "refrescar",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matriz de la zona",
+                        "schema": {
+                            "$ref": "#/definitions/services.MatrizZona"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/zonas/{id}/resumen": {
+            "get": {
+                "description": "Devuelve la zona con cantidad de tachos, llenado promedio y personas asignadas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Zonas"
+                ],
+                "summary": "Obtener resumen de una zona",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID de la zona",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resumen de la zona",
+                        "schema": {
+                            "$ref": "#/definitions/services.ZonaResumen"
+                        }
+                    },
+                    "400": {
+                        "description": "ID de zona inválido",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Zona no encontrada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Error interno del servidor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.PersonaResponse": {
+            "type": "object",
+            "properties": {
+                "camion_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "turno_fin": {
+                    "type": "string"
+                },
+                "turno_inicio": {
+                    "type": "string"
+                },
+                "zona_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RequestBody": {
+            "type": "object",
+            "properties": {
+                "descripcion": {
+                    "type": "string",
+                    "example": "Incendio en edificio de oficinas"
+                },
+                "id_tacho": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "id_zona": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "lat": {
+                    "type": "number",
+                    "example": -34.6037
+                },
+                "lng": {
+                    "type": "number",
+                    "example": -58.3816
+                },
+                "tipo": {
+                    "type": "string",
+                    "example": "incendio"
+                }
+            }
+        },
+        "handlers.ResponseBody": {
+            "type": "object",
+            "properties": {
+                "descripcion": {
+                    "type": "string",
+                    "example": "Incendio en edificio de oficinas"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Emergencia enviada correctamente"
+                },
+                "ruta": {
+                    "description": "Ruta es la ruta en curso de la zona ya reoptimizada, si la hay",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.PlanRuta"
+                        }
+                    ]
+                },
+                "tipo": {
+                    "type": "string",
+                    "example": "incendio"
+                }
+            }
+        },
+        "handlers.UpdateCapacidadRequest": {
+            "type": "object",
+            "properties": {
+                "capacidad": {
+                    "type": "number",
+                    "example": 90
+                }
+            }
+        },
+        "handlers.UpdateCapacidadResponse": {
+            "type": "object",
+            "properties": {
+                "capacidad": {
+                    "type": "number",
+                    "example": 90
+                },
+                "id_tacho": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Capacidad actualizada correctamente"
+                }
+            }
+        },
+        "handlers.UpdatePrioridadRequest": {
+            "type": "object",
+            "properties": {
+                "prioridad": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "handlers.UpdatePrioridadResponse": {
+            "type": "object",
+            "properties": {
+                "id_neo": {
+                    "type": "string"
+                },
+                "id_tacho": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "prioridad": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Agenda": {
+            "type": "object",
+            "properties": {
+                "distancia_total_km": {
+                    "type": "number"
+                },
+                "fin": {
+                    "type": "string"
+                },
+                "id_persona": {
+                    "type": "string"
+                },
+                "id_zona": {
+                    "type": "integer"
+                },
+                "no_entran": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ParadaNoEntra"
+                    }
+                },
+                "paradas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ParadaAgenda"
+                    }
+                },
+                "polilinea": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "tipo_distancia": {
+                    "type": "string"
+                },
+                "turno_fin": {
+                    "type": "string"
+                },
+                "turno_inicio": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.AsignacionReporte": {
+            "type": "object",
+            "properties": {
+                "asignados": {
+                    "type": "integer"
+                },
+                "sin_zona": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.TachoAsignacion"
+                    }
+                },
+                "superpuestos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.TachoAsignacion"
+                    }
+                },
+                "tachos_por_zona": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "total_tachos": {
+                    "type": "integer"
+                },
+                "zonas_sin_limite": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "services.CalendarioRequest": {
+            "type": "object",
+            "properties": {
+                "reglas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ReglaCalendario"
+                    }
+                }
+            }
+        },
+        "services.CalendarioZona": {
+            "type": "object",
+            "properties": {
+                "corridas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CorridaCalendario"
+                    }
+                },
+                "desde": {
+                    "type": "string"
+                },
+                "hasta": {
+                    "type": "string"
+                },
+                "id_zona": {
+                    "type": "integer"
+                },
+                "reglas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ReglaCalendario"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Camion": {
+            "type": "object",
+            "properties": {
+                "id_camion": {
+                    "type": "integer"
+                },
+                "id_tipo": {
+                    "type": "integer"
+                },
+                "nombre_tipo": {
+                    "type": "string"
+                },
+                "tipo_estado": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CamionResponse": {
+            "type": "object",
+            "properties": {
+                "camion": {
+                    "$ref": "#/definitions/services.Camion"
+                }
+            }
+        },
+        "services.CamionesResponse": {
+            "type": "object",
+            "properties": {
+                "camiones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Camion"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.CancelarPlanRequest": {
+            "type": "object",
+            "properties": {
+                "motivo": {
+                    "type": "string",
+                    "example": "Camión en el taller"
+                }
+            }
+        },
+        "services.Centro": {
+            "type": "object",
+            "properties": {
+                "barrio": {
+                    "type": "string"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "id_centro": {
+                    "type": "integer"
+                },
+                "id_neo": {
+                    "type": "string"
+                },
+                "latitud": {
+                    "type": "number"
+                },
+                "longitud": {
+                    "type": "number"
+                },
+                "nombre": {
+                    "description": "Información adicional de Neo4j",
+                    "type": "string"
+                },
+                "nombre_tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.CentroResponse": {
+            "type": "object",
+            "properties": {
+                "centro": {
+                    "$ref": "#/definitions/services.Centro"
+                }
+            }
+        },
+        "services.CentrosResponse": {
+            "type": "object",
+            "properties": {
+                "centros": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Centro"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.CorridaCalendario": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "fecha": {
+                    "type": "string"
+                },
+                "feriado": {
+                    "type": "string"
+                },
+                "id_calendario": {
+                    "type": "integer"
+                },
+                "id_ruta": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.CrearPlanRequest": {
+            "type": "object",
+            "properties": {
+                "algoritmo": {
+                    "type": "string",
+                    "example": "completo"
+                },
+                "destino_centro": {
+                    "type": "integer"
+                },
+                "id_camion": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "id_persona": {
+                    "type": "string",
+                    "example": "3"
+                },
+                "id_zona": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "min_capacidad": {
+                    "type": "number"
+                },
+                "origen_centro": {
+                    "type": "integer"
+                },
+                "peso_prioridad": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.CreateTachoRequest": {
+            "type": "object",
+            "properties": {
+                "barrio": {
+                    "description": "Datos para Neo4j",
+                    "type": "string"
+                },
+                "capacidad": {
+                    "type": "number"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "id_estado": {
+                    "type": "integer"
+                },
+                "id_tipo": {
+                    "description": "Datos para MySQL",
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "prioridad": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.CreateTachoResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "neo_node_id": {
+                    "type": "string"
+                },
+                "tacho_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.DeleteTachoResponse": {
+            "type": "object",
+            "properties": {
+                "custom_id": {
+                    "type": "string"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "mysql": {
+                    "$ref": "#/definitions/services.ResultadoEliminacion"
+                },
+                "neo4j": {
+                    "$ref": "#/definitions/services.ResultadoEliminacion"
+                },
+                "sincronizacion_pendiente": {
+                    "description": "Pendiente indica que la baja en Neo4j todavía no se aplicó",
+                    "type": "boolean"
+                }
+            }
+        },
+        "services.EstadoRedVial": {
+            "type": "object",
+            "properties": {
+                "intersecciones": {
+                    "type": "integer"
+                },
+                "tipo_distancia": {
+                    "type": "string"
+                },
+                "tramos": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.EventoOutbox": {
+            "type": "object",
+            "properties": {
+                "aplicado_en": {
+                    "type": "string"
+                },
+                "clave": {
+                    "type": "string"
+                },
+                "creado_en": {
+                    "type": "string"
+                },
+                "entidad": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "id_evento": {
+                    "type": "integer"
+                },
+                "intentos": {
+                    "type": "integer"
+                },
+                "operacion": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "proximo_intento": {
+                    "type": "string"
+                },
+                "ultimo_error": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.FeriadoRequest": {
+            "type": "object",
+            "properties": {
+                "descripcion": {
+                    "type": "string",
+                    "example": "Navidad"
+                },
+                "fecha": {
+                    "type": "string",
+                    "example": "2024-12-25"
+                },
+                "id_zona": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "services.ImportacionLimites": {
+            "type": "object",
+            "properties": {
+                "asignacion": {
+                    "$ref": "#/definitions/services.AsignacionReporte"
+                },
+                "zonas_importadas": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "zonas_no_encontradas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.ImportacionRedVial": {
+            "type": "object",
+            "properties": {
+                "intersecciones": {
+                    "type": "integer"
+                },
+                "tramos": {
+                    "type": "integer"
+                },
+                "tramos_una_mano": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Inconsistencia": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "Base donde está el problema en los duplicados (mysql o neo4j)",
+                    "type": "string"
+                },
+                "corregible": {
+                    "description": "Corregible indica si el reconciliador sabe corregirla",
+                    "type": "boolean"
+                },
+                "corregida": {
+                    "description": "Resultado de la corrección, si se pidió",
+                    "type": "boolean"
+                },
+                "custom_id": {
+                    "type": "string"
+                },
+                "detalle": {
+                    "type": "string"
+                },
+                "entidad": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "ids_mysql": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tipo": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.MarcarParadaRequest": {
+            "type": "object",
+            "properties": {
+                "motivo": {
+                    "type": "string",
+                    "example": "Auto estacionado delante del tacho"
+                }
+            }
+        },
+        "services.MatrizZona": {
+            "type": "object",
+            "properties": {
+                "actualizada_en": {
+                    "type": "string"
+                },
+                "distancias_km": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "duraciones_min": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "id_zona": {
+                    "type": "integer"
+                },
+                "posiciones": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "tachos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tipo_distancia": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ParadaAgenda": {
+            "type": "object",
+            "properties": {
+                "capacidad": {
+                    "description": "Llenado del tacho (%), prioridad y puntaje de urgencia combinado",
+                    "type": "number"
+                },
+                "carga_litros": {
+                    "type": "number"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "distancia_acumulada_km": {
+                    "type": "number"
+                },
+                "distancia_tramo_km": {
+                    "type": "number"
+                },
+                "espera_min": {
+                    "type": "number"
+                },
+                "fin": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "id_neo": {
+                    "type": "string"
+                },
+                "id_tacho": {
+                    "type": "integer"
+                },
+                "inicio": {
+                    "type": "string"
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "llegada": {
+                    "type": "string"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "prioridad": {
+                    "type": "integer"
+                },
+                "secuencia": {
+                    "description": "Orden de visita y distancias por calles o en línea recta (ver Ruta.TipoDistancia)",
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                },
+                "urgencia": {
+                    "type": "number"
+                },
+                "ventanas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Ventana"
+                    }
+                },
+                "volumen_litros": {
+                    "description": "Estimación de carga del camión en litros",
+                    "type": "number"
+                }
+            }
+        },
+        "services.ParadaNoEntra": {
+            "type": "object",
+            "properties": {
+                "capacidad": {
+                    "description": "Llenado del tacho (%), prioridad y puntaje de urgencia combinado",
+                    "type": "number"
+                },
+                "carga_litros": {
+                    "type": "number"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "distancia_acumulada_km": {
+                    "type": "number"
+                },
+                "distancia_tramo_km": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "id_neo": {
+                    "type": "string"
+                },
+                "id_tacho": {
+                    "type": "integer"
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "prioridad": {
+                    "type": "integer"
+                },
+                "secuencia": {
+                    "description": "Orden de visita y distancias por calles o en línea recta (ver Ruta.TipoDistancia)",
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                },
+                "urgencia": {
+                    "type": "number"
+                },
+                "ventanas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Ventana"
+                    }
+                },
+                "volumen_litros": {
+                    "description": "Estimación de carga del camión en litros",
+                    "type": "number"
+                }
+            }
+        },
+        "services.ParadaPlan": {
+            "type": "object",
+            "properties": {
+                "atendida_en": {
+                    "type": "string"
+                },
+                "capacidad": {
+                    "description": "Llenado del tacho (%), prioridad y puntaje de urgencia combinado",
+                    "type": "number"
+                },
+                "carga_litros": {
+                    "type": "number"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "distancia_acumulada_km": {
+                    "type": "number"
+                },
+                "distancia_tramo_km": {
+                    "type": "number"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "id_neo": {
+                    "type": "string"
+                },
+                "id_tacho": {
+                    "type": "integer"
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "prioridad": {
+                    "type": "integer"
+                },
+                "secuencia": {
+                    "description": "Orden de visita y distancias por calles o en línea recta (ver Ruta.TipoDistancia)",
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                },
+                "urgencia": {
+                    "type": "number"
+                },
+                "volumen_litros": {
+                    "description": "Estimación de carga del camión en litros",
+                    "type": "number"
+                }
+            }
+        },
+        "services.PlanRuta": {
+            "type": "object",
+            "properties": {
+                "actualizada_en": {
+                    "type": "string"
+                },
+                "algoritmo": {
+                    "type": "string"
+                },
+                "cantidad_paradas": {
+                    "type": "integer"
+                },
+                "capacidad_camion_litros": {
+                    "type": "number"
+                },
+                "creada_en": {
+                    "type": "string"
+                },
+                "descargas": {
+                    "type": "integer"
+                },
+                "distancia_total_km": {
+                    "type": "number"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "eta": {
+                    "type": "string"
+                },
+                "finalizada_en": {
+                    "type": "string"
+                },
+                "id_calendario": {
+                    "type": "integer"
+                },
+                "id_camion": {
+                    "type": "integer"
+                },
+                "id_persona": {
+                    "type": "string"
+                },
+                "id_ruta": {
+                    "type": "integer"
+                },
+                "id_zona": {
+                    "type": "integer"
+                },
+                "iniciada_en": {
+                    "type": "string"
+                },
+                "motivo_cancelacion": {
+                    "type": "string"
+                },
+                "paradas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ParadaPlan"
+                    }
+                },
+                "polilinea": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "programada_para": {
+                    "type": "string"
+                },
+                "progreso": {
+                    "$ref": "#/definitions/services.ProgresoPlan"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "tachos_omitidos": {
+                    "type": "integer"
+                },
+                "tipo_distancia": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.PlanesResponse": {
+            "type": "object",
+            "properties": {
+                "rutas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.PlanRuta"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Point": {
+            "type": "object",
+            "properties": {
+                "capacidad": {
+                    "description": "Llenado del tacho (%), prioridad y puntaje de urgencia combinado",
+                    "type": "number"
+                },
+                "carga_litros": {
+                    "type": "number"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "distancia_acumulada_km": {
+                    "type": "number"
+                },
+                "distancia_tramo_km": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "id_neo": {
+                    "type": "string"
+                },
+                "id_tacho": {
+                    "type": "integer"
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "nombre": {
+                    "type": "string"
+                },
+                "prioridad": {
+                    "type": "integer"
+                },
+                "secuencia": {
+                    "description": "Orden de visita y distancias por calles o en línea recta (ver Ruta.TipoDistancia)",
+                    "type": "integer"
+                },
+                "tipo": {
+                    "type": "string"
+                },
+                "urgencia": {
+                    "type": "number"
+                },
+                "volumen_litros": {
+                    "description": "Estimación de carga del camión en litros",
+                    "type": "number"
+                }
+            }
+        },
+        "services.ProgresoPlan": {
+            "type": "object",
+            "properties": {
+                "bloqueadas": {
+                    "type": "integer"
+                },
+                "distancia_recorrida_km": {
+                    "type": "number"
+                },
+                "distancia_restante_km": {
+                    "type": "number"
+                },
+                "omitidas": {
+                    "type": "integer"
+                },
+                "pendientes": {
+                    "type": "integer"
+                },
+                "porcentaje": {
+                    "type": "number"
+                },
+                "recolectadas": {
+                    "type": "integer"
+                },
+                "total_paradas": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.ReconciliarRequest": {
+            "type": "object",
+            "properties": {
+                "fuente": {
+                    "description": "Fuente es la base verdadera: mysql o neo4j",
+                    "type": "string"
+                },
+                "id_estado": {
+                    "type": "integer"
+                },
+                "id_tipo": {
+                    "description": "IDTipo e IDEstado se usan al crear en MySQL un tacho que solo existe en Neo4j",
+                    "type": "integer"
+                },
+                "id_tipo_centro": {
+                    "description": "IDTipoCentro se usa al crear en MySQL un centro que solo existe en Neo4j",
+                    "type": "integer"
+                },
+                "tipos": {
+                    "description": "Tipos limita la corrección a esos tipos de inconsistencia; vacío corrige todos",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.ReglaCalendario": {
+            "type": "object",
+            "properties": {
+                "activo": {
+                    "type": "boolean"
+                },
+                "algoritmo": {
+                    "type": "string",
+                    "example": "completo"
+                },
+                "dias": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "lun",
+                        "mie",
+                        "vie"
+                    ]
+                },
+                "hora": {
+                    "type": "string",
+                    "example": "06:00"
+                },
+                "id_calendario": {
+                    "type": "integer"
+                },
+                "id_camion": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "id_persona": {
+                    "type": "string",
+                    "example": "3"
+                }
+            }
+        },
+        "services.ReoptimizarRequest": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "number",
+                    "example": -34.6037
+                },
+                "lng": {
+                    "type": "number",
+                    "example": -58.3816
+                },
+                "motivo": {
+                    "type": "string",
+                    "example": "Emergencia: tacho desbordado"
+                },
+                "quitar": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "urgentes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "services.ReporteReconciliacion": {
+            "type": "object",
+            "properties": {
+                "centros_mysql": {
+                    "type": "integer"
+                },
+                "centros_neo4j": {
+                    "type": "integer"
+                },
+                "corregidas": {
+                    "type": "integer"
+                },
+                "fuente": {
+                    "type": "string"
+                },
+                "generado_en": {
+                    "type": "string"
+                },
+                "inconsistencias": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Inconsistencia"
+                    }
+                },
+                "tachos_mysql": {
+                    "type": "integer"
+                },
+                "tachos_neo4j": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.ResultadoEliminacion": {
+            "type": "object",
+            "properties": {
+                "detalle": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "ids_tacho": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "node_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.ReubicarTachoRequest": {
+            "type": "object",
+            "properties": {
+                "barrio": {
+                    "type": "string"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
+        "services.RevisionPlan": {
+            "type": "object",
+            "properties": {
+                "creada_en": {
+                    "type": "string"
+                },
+                "distancia_total_km": {
+                    "type": "number"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "paradas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.ParadaPlan"
+                    }
+                },
+                "polilinea": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "posicion": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "quitados": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "urgentes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "services.RevisionesResponse": {
+            "type": "object",
+            "properties": {
+                "id_ruta": {
+                    "type": "integer"
+                },
+                "revisiones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RevisionPlan"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.Ruta": {
+            "type": "object",
+            "properties": {
+                "algoritmo": {
+                    "type": "string"
+                },
+                "calculada_en": {
+                    "description": "CalculadaEn es el momento del cálculo; en una ruta cacheada es anterior a la consulta",
+                    "type": "string"
+                },
+                "capacidad_camion_litros": {
+                    "description": "Datos del control de capacidad (solo si la ruta se calculó para un camión)",
+                    "type": "number"
+                },
+                "descargas": {
+                    "type": "integer"
+                },
+                "distancia_total_km": {
+                    "type": "number"
+                },
+                "polilinea": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "puntos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Point"
+                    }
+                },
+                "tachos_omitidos": {
+                    "type": "integer"
+                },
+                "tachos_urgentes": {
+                    "description": "Resultado de la selección de paradas",
+                    "type": "integer"
+                },
+                "tipo_distancia": {
+                    "description": "TipoDistancia indica si la distancia es por calles (vial) o en línea recta;\nPolilinea es el recorrido como posiciones [lat, lng]",
+                    "type": "string"
+                }
+            }
+        },
+        "services.RutaCamion": {
+            "type": "object",
+            "properties": {
+                "algoritmo": {
+                    "type": "string"
+                },
+                "calculada_en": {
+                    "description": "CalculadaEn es el momento del cálculo; en una ruta cacheada es anterior a la consulta",
+                    "type": "string"
+                },
+                "cantidad_tachos": {
+                    "type": "integer"
+                },
+                "capacidad_camion_litros": {
+                    "description": "Datos del control de capacidad (solo si la ruta se calculó para un camión)",
+                    "type": "number"
+                },
+                "descargas": {
+                    "type": "integer"
+                },
+                "distancia_total_km": {
+                    "type": "number"
+                },
+                "id_camion": {
+                    "type": "integer"
+                },
+                "polilinea": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "puntos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Point"
+                    }
+                },
+                "tachos_omitidos": {
+                    "type": "integer"
+                },
+                "tachos_urgentes": {
+                    "description": "Resultado de la selección de paradas",
+                    "type": "integer"
+                },
+                "tipo_camion": {
+                    "type": "integer"
+                },
+                "tipo_distancia": {
+                    "description": "TipoDistancia indica si la distancia es por calles (vial) o en línea recta;\nPolilinea es el recorrido como posiciones [lat, lng]",
+                    "type": "string"
+                }
+            }
+        },
+        "services.RutasFlota": {
+            "type": "object",
+            "properties": {
+                "algoritmo": {
+                    "type": "string"
+                },
+                "camiones": {
+                    "type": "integer"
+                },
+                "distancia_maxima_km": {
+                    "type": "number"
+                },
+                "distancia_total_km": {
+                    "type": "number"
+                },
+                "rutas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RutaCamion"
+                    }
+                },
+                "tachos_omitidos": {
+                    "type": "integer"
+                },
+                "zona_id": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.ResponseBody": {
+        "services.TachoAsignacion": {
             "type": "object",
             "properties": {
-                "descripcion": {
-                    "type": "string",
-                    "example": "Incendio en edificio de oficinas"
+                "barrio": {
+                    "type": "string"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "Emergencia enviada correctamente"
+                "direccion": {
+                    "type": "string"
                 },
-                "tipo": {
-                    "type": "string",
-                    "example": "incendio"
+                "id": {
+                    "type": "string"
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "lng": {
+                    "type": "number"
+                },
+                "zonas": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "handlers.UpdateCapacidadRequest": {
+        "services.TachoCompleto": {
             "type": "object",
             "properties": {
+                "barrio": {
+                    "type": "string"
+                },
                 "capacidad": {
-                    "type": "number",
-                    "example": 90
+                    "type": "number"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "id_tacho": {
+                    "type": "integer"
+                },
+                "latitud": {
+                    "type": "number"
+                },
+                "longitud": {
+                    "type": "number"
                 }
             }
         },
-        "handlers.UpdateCapacidadResponse": {
+        "services.TachoDetalle": {
             "type": "object",
             "properties": {
+                "barrio": {
+                    "type": "string"
+                },
                 "capacidad": {
-                    "type": "number",
-                    "example": 90
+                    "type": "number"
+                },
+                "custom_id": {
+                    "type": "string"
+                },
+                "direccion": {
+                    "type": "string"
+                },
+                "en_neo4j": {
+                    "type": "boolean"
+                },
+                "estado": {
+                    "type": "string"
+                },
+                "id_estado": {
+                    "type": "integer"
                 },
                 "id_tacho": {
-                    "type": "integer",
-                    "example": 1
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "Capacidad actualizada correctamente"
+                "id_tipo": {
+                    "type": "integer"
+                },
+                "latitud": {
+                    "type": "number"
+                },
+                "longitud": {
+                    "type": "number"
+                },
+                "node_id": {
+                    "description": "Datos de Neo4j (vacíos si el tacho no tiene nodo)",
+                    "type": "string"
+                },
+                "prioridad": {
+                    "type": "integer"
+                },
+                "sincronizacion_pendiente": {
+                    "description": "SincronizacionPendiente indica cambios en el outbox que Neo4j todavía no tiene",
+                    "type": "boolean"
                 }
             }
         },
-        "handlers.UpdatePrioridadRequest": {
+        "services.TurnoRequest": {
             "type": "object",
             "properties": {
-                "prioridad": {
-                    "type": "integer",
-                    "example": 2
+                "fin": {
+                    "type": "string",
+                    "example": "14:00"
+                },
+                "inicio": {
+                    "type": "string",
+                    "example": "06:00"
                 }
             }
         },
-        "handlers.UpdatePrioridadResponse": {
+        "services.UpdateTachoRequest": {
             "type": "object",
             "properties": {
-                "id_neo": {
+                "capacidad": {
+                    "type": "number"
+                },
+                "direccion": {
+                    "description": "Direccion cambia también el custom id (direccion|barrio)",
                     "type": "string"
                 },
-                "id_tacho": {
+                "id_estado": {
                     "type": "integer"
                 },
-                "message": {
-                    "type": "string"
+                "id_tipo": {
+                    "type": "integer"
                 },
                 "prioridad": {
                     "type": "integer"
                 }
             }
         },
-        "services.Camion": {
+        "services.Ventana": {
             "type": "object",
             "properties": {
-                "id_camion": {
-                    "type": "integer"
-                },
-                "nombre_tipo": {
-                    "type": "string"
+                "desde": {
+                    "type": "string",
+                    "example": "08:00"
                 },
-                "tipo_estado": {
-                    "type": "string"
+                "hasta": {
+                    "type": "string",
+                    "example": "12:00"
                 }
             }
         },
-        "services.CamionResponse": {
+        "services.VentanasRequest": {
             "type": "object",
             "properties": {
-                "camion": {
-                    "$ref": "#/definitions/services.Camion"
+                "ventanas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Ventana"
+                    }
                 }
             }
         },
-        "services.CamionesResponse": {
+        "services.Zona": {
             "type": "object",
             "properties": {
+                "barrios": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "camiones": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/services.Camion"
+                        "type": "integer"
                     }
                 },
-                "total": {
+                "id_zona": {
                     "type": "integer"
+                },
+                "limite": {
+                    "type": "object"
+                },
+                "nombre": {
+                    "type": "string"
                 }
             }
         },
-        "services.Centro": {
+        "services.ZonaRequest": {
             "type": "object",
             "properties": {
-                "barrio": {
-                    "type": "string"
-                },
-                "direccion": {
-                    "type": "string"
-                },
-                "id_centro": {
-                    "type": "integer"
-                },
-                "id_neo": {
-                    "type": "string"
+                "barrios": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CHACARITA"
+                    ]
                 },
-                "latitud": {
-                    "type": "number"
+                "camiones": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1
+                    ]
                 },
-                "longitud": {
-                    "type": "number"
+                "limite": {
+                    "type": "object"
                 },
                 "nombre": {
-                    "description": "Información adicional de Neo4j",
-                    "type": "string"
-                },
-                "nombre_tipo": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Chacarita"
                 }
             }
         },
-        "services.CentroResponse": {
+        "services.ZonaResponse": {
             "type": "object",
             "properties": {
-                "centro": {
-                    "$ref": "#/definitions/services.Centro"
+                "zona": {
+                    "$ref": "#/definitions/services.Zona"
                 }
             }
         },
-        "services.CentrosResponse": {
+        "services.ZonaResumen": {
             "type": "object",
             "properties": {
-                "centros": {
+                "barrios": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/services.Centro"
+                        "type": "string"
                     }
                 },
-                "total": {
-                    "type": "integer"
-                }
-            }
-        },
-        "services.CreateTachoRequest": {
-            "type": "object",
-            "properties": {
-                "barrio": {
-                    "description": "Datos para Neo4j",
-                    "type": "string"
-                },
-                "capacidad": {
-                    "type": "number"
-                },
-                "direccion": {
-                    "type": "string"
+                "camiones": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "id_estado": {
+                "cantidad_personas": {
                     "type": "integer"
                 },
-                "id_tipo": {
-                    "description": "Datos para MySQL",
+                "cantidad_tachos": {
                     "type": "integer"
                 },
-                "latitude": {
-                    "type": "number"
-                },
-                "longitude": {
+                "capacidad_promedio": {
                     "type": "number"
                 },
-                "prioridad": {
+                "id_zona": {
                     "type": "integer"
-                }
-            }
-        },
-        "services.CreateTachoResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
                 },
-                "neo_node_id": {
+                "limite": {
+                    "type": "object"
+                },
+                "nombre": {
                     "type": "string"
                 },
-                "tacho_id": {
-                    "type": "integer"
+                "personas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "services.TachoCompleto": {
+        "services.ZonasResponse": {
             "type": "object",
             "properties": {
-                "barrio": {
-                    "type": "string"
-                },
-                "capacidad": {
-                    "type": "number"
-                },
-                "direccion": {
-                    "type": "string"
-                },
-                "estado": {
-                    "type": "string"
-                },
-                "id_tacho": {
+                "total": {
                     "type": "integer"
                 },
-                "latitud": {
-                    "type": "number"
-                },
-                "longitud": {
-                    "type": "number"
+                "zonas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.Zona"
+                    }
                 }
             }
         }
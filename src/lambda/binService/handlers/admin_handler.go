@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetOutboxHandler lista los eventos del outbox MySQL -> Neo4j
+// @Summary Listar eventos del outbox
+// @Description Devuelve los últimos eventos de sincronización con Neo4j y el resumen (pendientes, fallidos y lag). Los pendientes se reintentan solos con espera creciente; los fallidos (dead-letter) agotaron los reintentos y retienen los eventos siguientes de su recurso hasta que se reintenten o se descarten.
+// @Tags Admin
+// @Produce json
+// @Param estado query string false "Filtrar por estado (pendiente, aplicado, fallido, descartado)"
+// @Success 200 {array} services.EventoOutbox "Eventos del outbox"
+// @Failure 400 {object} map[string]string "Estado inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /admin/outbox [get]
+func GetOutboxHandler(c *gin.Context) {
+	estado := c.Query("estado")
+	switch estado {
+	case "", services.OutboxPendiente, services.OutboxAplicado, services.OutboxFallido, services.OutboxDescartado:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Estado inválido: " + estado})
+		return
+	}
+
+	eventos, resumen, err := services.GetEventosOutbox(estado)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener el outbox: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"eventos": eventos,
+		"total":   len(eventos),
+		"resumen": resumen,
+	})
+}
+
+// ReintentarEventoOutboxHandler vuelve a aplicar un evento del outbox
+// @Summary Reintentar evento del outbox
+// @Description Vuelve a poner en cola un evento pendiente o fallido y lo aplica ahora en Neo4j, después de los eventos anteriores del mismo recurso
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID del evento"
+// @Success 200 {object} services.EventoOutbox "Evento reintentado"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 409 {object} map[string]string "El evento ya está aplicado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /admin/outbox/{id}/reintentar [post]
+func ReintentarEventoOutboxHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido: debe ser un número entero mayor a 0"})
+		return
+	}
+
+	evento, err := services.ReintentarEventoOutbox(id)
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case strings.HasSuffix(msg, " not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": msg})
+		case strings.HasPrefix(msg, "transición inválida"):
+			c.JSON(http.StatusConflict, gin.H{"error": msg})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al reintentar evento: " + msg})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, evento)
+}
+
+// DescartarEventoOutboxHandler descarta un evento fallido del outbox
+// @Summary Descartar evento fallido del outbox
+// @Description Marca como descartado un evento fallido (dead-letter) para que se apliquen los eventos siguientes del mismo recurso. Lo que el evento no aplicó se puede corregir con /admin/reconciliacion.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "ID del evento"
+// @Success 200 {object} services.EventoOutbox "Evento descartado"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Evento no encontrado"
+// @Failure 409 {object} map[string]string "El evento no está fallido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /admin/outbox/{id}/descartar [post]
+func DescartarEventoOutboxHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido: debe ser un número entero mayor a 0"})
+		return
+	}
+
+	evento, err := services.DescartarEventoOutbox(id)
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case strings.HasSuffix(msg, " not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": msg})
+		case strings.HasPrefix(msg, "transición inválida"):
+			c.JSON(http.StatusConflict, gin.H{"error": msg})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al descartar evento: " + msg})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, evento)
+}
+
+// GetReconciliacionHandler compara tachos y centros entre MySQL y Neo4j
+// @Summary Reporte de consistencia MySQL/Neo4j
+// @Description Informa filas de MySQL sin nodo en Neo4j, nodos sin fila, nodos sin ubicación o con una ubicación distinta de la registrada en MySQL y custom ids duplicados. No modifica nada.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} services.ReporteReconciliacion "Inconsistencias encontradas"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /admin/reconciliacion [get]
+func GetReconciliacionHandler(c *gin.Context) {
+	reporte, err := services.Reconciliar(nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al comparar las bases: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reporte)
+}
+
+// ReconciliarHandler corrige las inconsistencias de tachos y centros entre MySQL y Neo4j
+// @Summary Corregir inconsistencias MySQL/Neo4j
+// @Description Corrige las inconsistencias de tachos y centros tomando como verdadera la base indicada en fuente (mysql o neo4j): borra o recrea el lado que sobra o falta y vuelve a escribir la ubicación. Los cambios en Neo4j pasan por el outbox. Los duplicados solo se corrigen en la otra base: se conserva la fila más antigua o el nodo de menor elementId. Para crear en MySQL lo que solo está en Neo4j hacen falta id_tipo e id_estado (tachos) o id_tipo_centro (centros). El reporte indica el resultado de cada inconsistencia.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body services.ReconciliarRequest true "Fuente de verdad y tipos a corregir"
+// @Success 200 {object} services.ReporteReconciliacion "Inconsistencias y resultado de la corrección"
+// @Failure 400 {object} map[string]string "Fuente o tipo inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /admin/reconciliacion [post]
+func ReconciliarHandler(c *gin.Context) {
+	var request services.ReconciliarRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	reporte, err := services.Reconciliar(&request)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "reconciliación inválida") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al reconciliar: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reporte)
+}
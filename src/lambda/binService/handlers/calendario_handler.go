@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalendarioZonaHandler lista las próximas corridas de recolección de una zona
+// @Summary Calendario de recolección de una zona
+// @Description Devuelve las reglas recurrentes de la zona y sus corridas de los próximos días. Cada corrida está pendiente, generada (con su id_ruta) o en feriado.
+// @Tags Zonas
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Param dias query int false "Días hacia adelante (máximo 90)" default(14)
+// @Success 200 {object} services.CalendarioZona "Calendario de la zona"
+// @Failure 400 {object} map[string]string "ID de zona o días inválidos"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id}/calendario [get]
+func GetCalendarioZonaHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+	dias, ok := parseDiasCalendario(c, services.DiasCalendarioPorDefecto)
+	if !ok {
+		return
+	}
+
+	calendario, err := services.GetCalendarioZona(zonaID, dias)
+	if err != nil {
+		responderErrorCalendario(c, err, zonaID, "Error al obtener calendario: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, calendario)
+}
+
+// UpdateCalendarioZonaHandler reemplaza las reglas de recolección de una zona
+// @Summary Actualizar calendario de recolección de una zona
+// @Description Reemplaza las reglas recurrentes de la zona (días de la semana y hora de salida, con camión, persona y algoritmo opcionales). Las rutas ya generadas por las reglas anteriores que todavía no salieron se cancelan.
+// @Tags Zonas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Param calendario body services.CalendarioRequest true "Reglas de recolección"
+// @Success 200 {object} services.CalendarioZona "Calendario actualizado"
+// @Failure 400 {object} map[string]string "Reglas inválidas"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id}/calendario [put]
+func UpdateCalendarioZonaHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+
+	var request services.CalendarioRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	calendario, err := services.SetCalendarioZona(zonaID, request)
+	if err != nil {
+		responderErrorCalendario(c, err, zonaID, "Error al actualizar calendario: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, calendario)
+}
+
+// GenerarCalendarioZonaHandler genera las rutas de las próximas corridas de una zona
+// @Summary Generar rutas programadas de una zona
+// @Description Calcula y guarda como planificadas las rutas de las corridas pendientes de los próximos días, con el llenado actual de los tachos. Cada ruta sale a la hora de su corrida y omite los tachos que no entran en sus ventanas horarias o en el turno de la persona. Las corridas ya generadas o en feriado no se tocan. El servicio también las genera solo cada hora para los próximos 2 días.
+// @Tags Zonas
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Param dias query int false "Días hacia adelante (máximo 90)" default(2)
+// @Success 200 {array} services.CorridaCalendario "Corridas procesadas"
+// @Failure 400 {object} map[string]string "ID de zona o días inválidos"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id}/calendario/generar [post]
+func GenerarCalendarioZonaHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+	dias, ok := parseDiasCalendario(c, 2)
+	if !ok {
+		return
+	}
+
+	corridas, err := services.GenerarPlanesCalendario(zonaID, dias)
+	if err != nil {
+		responderErrorCalendario(c, err, zonaID, "Error al generar rutas programadas: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id_zona":  zonaID,
+		"corridas": corridas,
+		"total":    len(corridas),
+	})
+}
+
+// GetFeriadosHandler lista los próximos feriados
+// @Summary Listar feriados
+// @Description Devuelve los feriados desde hoy. Con id_zona, solo los de esa zona y los generales (id_zona 0).
+// @Tags Zonas
+// @Produce json
+// @Param id_zona query int false "ID de la zona"
+// @Success 200 {array} services.FeriadoRequest "Feriados"
+// @Failure 400 {object} map[string]string "ID de zona inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /feriados [get]
+func GetFeriadosHandler(c *gin.Context) {
+	zonaID, ok := parseZonaFeriado(c)
+	if !ok {
+		return
+	}
+
+	feriados, err := services.GetFeriados(zonaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener feriados: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"feriados": feriados,
+		"total":    len(feriados),
+	})
+}
+
+// CreateFeriadoHandler carga un día sin recolección
+// @Summary Cargar feriado
+// @Description Marca un día sin recolección para una zona o, con id_zona 0, para todas. Las rutas ya generadas para ese día que no salieron se cancelan.
+// @Tags Zonas
+// @Accept json
+// @Produce json
+// @Param feriado body services.FeriadoRequest true "Fecha (YYYY-MM-DD), zona y descripción"
+// @Success 201 {object} services.FeriadoRequest "Feriado guardado"
+// @Failure 400 {object} map[string]string "Fecha inválida"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /feriados [post]
+func CreateFeriadoHandler(c *gin.Context) {
+	var request services.FeriadoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+	if request.IDZona < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id_zona inválido"})
+		return
+	}
+
+	feriado, err := services.SetFeriado(request)
+	if err != nil {
+		responderErrorCalendario(c, err, request.IDZona, "Error al guardar feriado: ")
+		return
+	}
+
+	c.JSON(http.StatusCreated, feriado)
+}
+
+// DeleteFeriadoHandler elimina un feriado
+// @Summary Eliminar feriado
+// @Description Elimina el feriado de la fecha para la zona indicada (0 o sin id_zona para el general). Las corridas de ese día vuelven a generarse.
+// @Tags Zonas
+// @Produce json
+// @Param fecha path string true "Fecha (YYYY-MM-DD)"
+// @Param id_zona query int false "ID de la zona" default(0)
+// @Success 200 {object} map[string]string "Feriado eliminado"
+// @Failure 400 {object} map[string]string "Fecha o zona inválida"
+// @Failure 404 {object} map[string]string "Feriado no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /feriados/{fecha} [delete]
+func DeleteFeriadoHandler(c *gin.Context) {
+	zonaID, ok := parseZonaFeriado(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteFeriado(c.Param("fecha"), zonaID); err != nil {
+		responderErrorCalendario(c, err, 0, "Error al eliminar feriado: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Feriado eliminado exitosamente",
+		"fecha":   c.Param("fecha"),
+		"id_zona": zonaID,
+	})
+}
+
+// parseDiasCalendario lee el parámetro dias; responde 400 si es inválido
+func parseDiasCalendario(c *gin.Context, porDefecto int) (int, bool) {
+	diasStr := c.Query("dias")
+	if diasStr == "" {
+		return porDefecto, true
+	}
+	dias, err := strconv.Atoi(diasStr)
+	if err != nil || dias <= 0 || dias > services.DiasCalendarioMax {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "dias inválido: debe ser un número entero entre 1 y " + strconv.Itoa(services.DiasCalendarioMax),
+		})
+		return 0, false
+	}
+	return dias, true
+}
+
+// parseZonaFeriado lee el id_zona opcional de los feriados; 0 es el general
+func parseZonaFeriado(c *gin.Context) (int, bool) {
+	zonaStr := c.Query("id_zona")
+	if zonaStr == "" {
+		return 0, true
+	}
+	zonaID, err := strconv.Atoi(zonaStr)
+	if err != nil || zonaID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id_zona inválido: debe ser un número entero"})
+		return 0, false
+	}
+	return zonaID, true
+}
+
+// responderErrorCalendario traduce los errores del calendario a respuestas HTTP
+func responderErrorCalendario(c *gin.Context, err error, zonaID int, prefijo string) {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "regla inválida"), strings.HasPrefix(msg, "hora inválida"),
+		strings.HasPrefix(msg, "fecha inválida"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	case zonaID > 0 && zonaNoEncontrada(err, zonaID):
+		responderErrorZona(c, err, zonaID, prefijo)
+	case strings.HasSuffix(msg, " not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefijo + msg})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -59,6 +60,9 @@ func UpdateCapacidadTachoHandler(c *gin.Context) {
 		return
 	}
 
+	// La ruta cacheada de la zona ya no refleja el llenado del tacho
+	services.InvalidarRutasTachoPorID(id, services.MotivoCapacidad)
+
 	// Actualizar métricas de Prometheus
 	// Nota: Necesitarías obtener la zona del tacho para las etiquetas completas
 	middleware.UpdateTachoCapacidad(idStr, "zona_desconocida", body.Capacidad)
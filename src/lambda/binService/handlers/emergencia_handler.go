@@ -2,31 +2,45 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
 	"github.com/gin-gonic/gin"
 )
 
+// RequestBody es una emergencia. Con id_zona se reoptimiza la ruta en curso
+// de la zona; id_tacho es el tacho afectado y lat/lng la posición del camión.
 type RequestBody struct {
-	Tipo        string `json:"tipo" example:"incendio"`
-	Descripcion string `json:"descripcion" example:"Incendio en edificio de oficinas"`
+	Tipo        string   `json:"tipo" example:"incendio"`
+	Descripcion string   `json:"descripcion" example:"Incendio en edificio de oficinas"`
+	IDZona      int      `json:"id_zona,omitempty" example:"1"`
+	IDTacho     int      `json:"id_tacho,omitempty" example:"12"`
+	Lat         *float64 `json:"lat,omitempty" example:"-34.6037"`
+	Lng         *float64 `json:"lng,omitempty" example:"-58.3816"`
 }
 
 type ResponseBody struct {
 	Message     string `json:"message" example:"Emergencia enviada correctamente"`
 	Tipo        string `json:"tipo" example:"incendio"`
 	Descripcion string `json:"descripcion" example:"Incendio en edificio de oficinas"`
+	// Ruta es la ruta en curso de la zona ya reoptimizada, si la hay
+	Ruta *services.PlanRuta `json:"ruta,omitempty"`
 }
 
 // SendEmergencyHandler envía una emergencia
 // @Summary Enviar emergencia
-// @Description Registra una nueva emergencia en el sistema
+// @Description Registra una nueva emergencia en el sistema. Con id_zona se reoptimiza la ruta en curso de la zona como en /rutas/{id}/reoptimizar: el tacho indicado (id_tacho) pasa a urgente y, si no se envía la posición del camión (lat, lng), se usa la de la última parada atendida. Si la zona no tiene ruta en curso solo se registra la emergencia.
 // @Tags Emergencias
 // @Accept json
 // @Produce json
 // @Param emergencia body RequestBody true "Datos de la emergencia"
 // @Success 200 {object} ResponseBody "Emergencia enviada correctamente"
 // @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Tacho no encontrado"
+// @Failure 409 {object} map[string]string "La ruta ya no admite cambios"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /enviar-emergencia [post]
 func SendEmergencyHandler(c *gin.Context) {
 	var body RequestBody
@@ -36,15 +50,35 @@ func SendEmergencyHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
 		return
 	}
+	if body.IDTacho > 0 && body.IDZona <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id_zona es requerido para reoptimizar la ruta"})
+		return
+	}
 
+	response := ResponseBody{
+		Message:     "Emergencia enviada correctamente",
+		Tipo:        body.Tipo,
+		Descripcion: body.Descripcion,
+	}
 	// Actualizar métricas de Prometheus
-	// Nota: Asumo zona genérica, podrías obtener la zona del contexto o header
-	middleware.IncrementEmergencias(body.Tipo, "zona_general")
+	zona := "zona_general"
+	if body.IDZona > 0 {
+		zona = strconv.Itoa(body.IDZona)
+	}
+	middleware.IncrementEmergencias(body.Tipo, zona)
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":     "Emergencia enviada correctamente",
-		"tipo":        body.Tipo,
-		"descripcion": body.Descripcion,
-	})
+	if body.IDZona > 0 {
+		motivo := strings.TrimSpace("Emergencia: " + body.Tipo + " " + body.Descripcion)
+		plan, err := services.ReoptimizarPorEmergencia(body.IDZona, body.IDTacho, body.Lat, body.Lng, motivo)
+		if err != nil {
+			responderErrorPlan(c, err, "Error al reoptimizar ruta: ")
+			return
+		}
+		if plan != nil {
+			response.Message = "Emergencia enviada; la ruta en curso de la zona fue reoptimizada"
+			response.Ruta = plan
+		}
+	}
 
+	c.JSON(http.StatusOK, response)
 }
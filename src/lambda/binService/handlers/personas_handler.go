@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,9 +16,11 @@ var ctx = context.Background()
 
 // PersonaResponse represents the response for persona data
 type PersonaResponse struct {
-	ID       string `json:"id"`
-	ZonaID   string `json:"zona_id"`
-	CamionID string `json:"camion_id"`
+	ID          string `json:"id"`
+	ZonaID      string `json:"zona_id"`
+	CamionID    string `json:"camion_id"`
+	TurnoInicio string `json:"turno_inicio,omitempty"`
+	TurnoFin    string `json:"turno_fin,omitempty"`
 }
 
 // GetAllPersonas obtiene todas las personas de Redis
@@ -46,9 +50,11 @@ func GetAllPersonas(c *gin.Context) {
 		}
 
 		persona := PersonaResponse{
-			ID:       personData["id"],
-			ZonaID:   personData["zona_id"],
-			CamionID: personData["camion_id"],
+			ID:          personData["id"],
+			ZonaID:      personData["zona_id"],
+			CamionID:    personData["camion_id"],
+			TurnoInicio: personData["turno_inicio"],
+			TurnoFin:    personData["turno_fin"],
 		}
 
 		result = append(result, persona)
@@ -114,9 +120,11 @@ func GetPersonaByID(c *gin.Context) {
 	}
 
 	persona := PersonaResponse{
-		ID:       personData["id"],
-		ZonaID:   personData["zona_id"],
-		CamionID: personData["camion_id"],
+		ID:          personData["id"],
+		ZonaID:      personData["zona_id"],
+		CamionID:    personData["camion_id"],
+		TurnoInicio: personData["turno_inicio"],
+		TurnoFin:    personData["turno_fin"],
 	}
 
 	c.JSON(http.StatusOK, persona)
@@ -160,9 +168,11 @@ func GetPersonasByZona(c *gin.Context) {
 		personZona, _ := strconv.Atoi(personData["zona_id"])
 		if personZona == zona {
 			persona := PersonaResponse{
-				ID:       personData["id"],
-				ZonaID:   personData["zona_id"],
-				CamionID: personData["camion_id"],
+				ID:          personData["id"],
+				ZonaID:      personData["zona_id"],
+				CamionID:    personData["camion_id"],
+				TurnoInicio: personData["turno_inicio"],
+				TurnoFin:    personData["turno_fin"],
 			}
 			result = append(result, persona)
 		}
@@ -177,3 +187,49 @@ func GetPersonasByZona(c *gin.Context) {
 		"personas": result,
 	})
 }
+
+// UpdateTurnoPersonaHandler fija el turno de trabajo de una persona
+// @Summary Actualizar turno de una persona
+// @Description Guarda el horario de trabajo (HH:MM) de la persona. La agenda de recorrido solo planifica paradas dentro del turno.
+// @Tags Personas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la persona"
+// @Param turno body services.TurnoRequest true "Inicio y fin del turno"
+// @Success 200 {object} map[string]string "Turno actualizado"
+// @Failure 400 {object} map[string]string "ID u horario inválido"
+// @Failure 404 {object} map[string]string "Persona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /personas/{id}/turno [put]
+func UpdateTurnoPersonaHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var request services.TurnoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := services.SetTurnoPersona(strconv.Itoa(id), request); err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "hora inválida"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.HasSuffix(err.Error(), " not found"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Persona no encontrada"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar turno: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Turno actualizado correctamente",
+		"id":           strconv.Itoa(id),
+		"turno_inicio": request.Inicio,
+		"turno_fin":    request.Fin,
+	})
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreatePlanRutaHandler calcula y guarda una ruta
+// @Summary Planificar una ruta
+// @Description Calcula la ruta de una zona y la guarda como planificada, asignada a un camión y una persona. Si se indica persona, su zona y camión se usan cuando no vienen en el pedido. La ruta sale ahora: los tachos que no entran en sus ventanas horarias o en el turno de la persona quedan omitidos.
+// @Tags Rutas
+// @Accept json
+// @Produce json
+// @Param ruta body services.CrearPlanRequest true "Zona, asignación y opciones de cálculo"
+// @Success 201 {object} services.PlanRuta "Ruta planificada"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Zona, camión, centro o persona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas [post]
+func CreatePlanRutaHandler(c *gin.Context) {
+	var request services.CrearPlanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+	if _, err := services.ValidarAlgoritmo(request.Algoritmo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.IDZona < 0 || request.IDCamion < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id_zona e id_camion deben ser mayores a 0"})
+		return
+	}
+
+	plan, err := services.CrearPlanRuta(request)
+	if err != nil {
+		responderErrorPlan(c, err, "Error al planificar ruta: ")
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// GetPlanesRutaHandler lista las rutas guardadas
+// @Summary Listar rutas planificadas
+// @Description Lista las rutas guardadas (sin paradas), de la más nueva a la más vieja, con filtros opcionales
+// @Tags Rutas
+// @Produce json
+// @Param fecha query string false "Día de creación (YYYY-MM-DD)"
+// @Param zona query int false "ID de la zona"
+// @Param camion query int false "ID del camión"
+// @Param persona query string false "ID de la persona"
+// @Param estado query string false "Estado (planificada, en_curso, completada, cancelada)"
+// @Success 200 {object} services.PlanesResponse "Rutas guardadas"
+// @Failure 400 {object} map[string]string "Filtro inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas [get]
+func GetPlanesRutaHandler(c *gin.Context) {
+	var filtro services.FiltroPlanes
+
+	if fechaStr := c.Query("fecha"); fechaStr != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", fechaStr, config.ZonaHoraria())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fecha inválida: debe tener formato YYYY-MM-DD"})
+			return
+		}
+		filtro.Fecha = &fecha
+	}
+	for param, destino := range map[string]*int{"zona": &filtro.IDZona, "camion": &filtro.IDCamion} {
+		if valor := c.Query(param); valor != "" {
+			id, err := strconv.Atoi(valor)
+			if err != nil || id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " debe ser un número entero mayor a 0"})
+				return
+			}
+			*destino = id
+		}
+	}
+	filtro.IDPersona = c.Query("persona")
+	if estado := c.Query("estado"); estado != "" {
+		if err := services.ValidarEstadoPlan(estado); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filtro.Estado = estado
+	}
+
+	response, err := services.GetPlanesRuta(filtro)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener rutas: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPlanRutaHandler obtiene una ruta guardada
+// @Summary Obtener una ruta planificada
+// @Description Devuelve una ruta guardada con su estado, paradas en orden y polilínea
+// @Tags Rutas
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Success 200 {object} services.PlanRuta "Ruta guardada"
+// @Failure 400 {object} map[string]string "ID de ruta inválido"
+// @Failure 404 {object} map[string]string "Ruta no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id} [get]
+func GetPlanRutaHandler(c *gin.Context) {
+	rutaID, ok := parseRutaID(c)
+	if !ok {
+		return
+	}
+
+	plan, err := services.GetPlanRuta(rutaID)
+	if err != nil {
+		responderErrorPlan(c, err, "Error al obtener ruta: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// CancelarPlanRutaHandler cancela una ruta planificada o en curso
+// @Summary Cancelar una ruta
+// @Description Cancela una ruta planificada o en curso. Las rutas completadas o ya canceladas no se pueden cancelar.
+// @Tags Rutas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Param cancelacion body services.CancelarPlanRequest false "Motivo de la cancelación"
+// @Success 200 {object} services.PlanRuta "Ruta cancelada"
+// @Failure 400 {object} map[string]string "ID de ruta inválido"
+// @Failure 404 {object} map[string]string "Ruta no encontrada"
+// @Failure 409 {object} map[string]string "La ruta no se puede cancelar en su estado actual"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id}/cancelar [post]
+func CancelarPlanRutaHandler(c *gin.Context) {
+	rutaID, ok := parseRutaID(c)
+	if !ok {
+		return
+	}
+
+	var request services.CancelarPlanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+			return
+		}
+	}
+
+	plan, err := services.CancelarPlanRuta(rutaID, strings.TrimSpace(request.Motivo))
+	if err != nil {
+		responderErrorPlan(c, err, "Error al cancelar ruta: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// RecolectarParadaHandler marca una parada como recolectada
+// @Summary Marcar parada recolectada
+// @Description Marca la parada de un tacho como recolectada y deja la capacidad del tacho en 0. La primera parada marcada inicia la ruta y la última la completa; se actualizan el progreso y la hora estimada de llegada.
+// @Tags Rutas
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Param secuencia path int true "Número de parada en la ruta"
+// @Success 200 {object} services.PlanRuta "Ruta actualizada"
+// @Failure 400 {object} map[string]string "Parámetros inválidos o la parada no es un tacho"
+// @Failure 404 {object} map[string]string "Ruta o parada no encontrada"
+// @Failure 409 {object} map[string]string "La ruta o la parada ya no admite cambios"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id}/paradas/{secuencia}/recolectar [post]
+func RecolectarParadaHandler(c *gin.Context) {
+	marcarParada(c, services.EstadoParadaRecolectada)
+}
+
+// OmitirParadaHandler marca una parada como omitida
+// @Summary Omitir parada
+// @Description Marca la parada de un tacho como omitida, con el motivo. Se actualizan el progreso y la hora estimada de llegada.
+// @Tags Rutas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Param secuencia path int true "Número de parada en la ruta"
+// @Param parada body services.MarcarParadaRequest true "Motivo"
+// @Success 200 {object} services.PlanRuta "Ruta actualizada"
+// @Failure 400 {object} map[string]string "Parámetros inválidos, falta el motivo o la parada no es un tacho"
+// @Failure 404 {object} map[string]string "Ruta o parada no encontrada"
+// @Failure 409 {object} map[string]string "La ruta o la parada ya no admite cambios"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id}/paradas/{secuencia}/omitir [post]
+func OmitirParadaHandler(c *gin.Context) {
+	marcarParada(c, services.EstadoParadaOmitida)
+}
+
+// BloquearParadaHandler marca una parada como bloqueada
+// @Summary Bloquear parada
+// @Description Marca la parada de un tacho como bloqueada (no se pudo acceder), con el motivo. Una parada bloqueada se puede recolectar u omitir después; la ruta no se completa mientras tenga paradas bloqueadas.
+// @Tags Rutas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Param secuencia path int true "Número de parada en la ruta"
+// @Param parada body services.MarcarParadaRequest true "Motivo"
+// @Success 200 {object} services.PlanRuta "Ruta actualizada"
+// @Failure 400 {object} map[string]string "Parámetros inválidos, falta el motivo o la parada no es un tacho"
+// @Failure 404 {object} map[string]string "Ruta o parada no encontrada"
+// @Failure 409 {object} map[string]string "La ruta o la parada ya no admite cambios"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id}/paradas/{secuencia}/bloquear [post]
+func BloquearParadaHandler(c *gin.Context) {
+	marcarParada(c, services.EstadoParadaBloqueada)
+}
+
+// marcarParada valida la URL y el motivo y registra el nuevo estado de la parada
+func marcarParada(c *gin.Context, estado string) {
+	rutaID, ok := parseRutaID(c)
+	if !ok {
+		return
+	}
+	secuencia, err := strconv.Atoi(c.Param("secuencia"))
+	if err != nil || secuencia <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Secuencia inválida: debe ser un número entero mayor a 0",
+		})
+		return
+	}
+
+	var request services.MarcarParadaRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+			return
+		}
+	}
+
+	plan, err := services.MarcarParada(rutaID, secuencia, estado, strings.TrimSpace(request.Motivo))
+	if err != nil {
+		responderErrorPlan(c, err, "Error al marcar parada: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// ReoptimizarPlanRutaHandler recalcula el resto de una ruta desde la posición del camión
+// @Summary Reoptimizar ruta en curso
+// @Description Ante una emergencia o una calle bloqueada, recalcula el resto de la ruta desde la posición actual del camión. Los tachos urgentes (id_tacho) se visitan primero y se agregan si no estaban en la ruta; los quitados y las paradas bloqueadas salen del recorrido. Las paradas ya atendidas se conservan; si la ruta tiene control de capacidad los viajes de descarga se recalculan partiendo de la carga actual del camión. Cada reoptimización queda guardada como una nueva revisión.
+// @Tags Rutas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Param cambios body services.ReoptimizarRequest true "Posición actual y tachos urgentes o quitados"
+// @Success 200 {object} services.PlanRuta "Ruta reoptimizada"
+// @Failure 400 {object} map[string]string "Posición o tachos inválidos"
+// @Failure 404 {object} map[string]string "Ruta o tacho no encontrado"
+// @Failure 409 {object} map[string]string "La ruta ya no admite cambios"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id}/reoptimizar [post]
+func ReoptimizarPlanRutaHandler(c *gin.Context) {
+	rutaID, ok := parseRutaID(c)
+	if !ok {
+		return
+	}
+
+	var request services.ReoptimizarRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+	request.Motivo = strings.TrimSpace(request.Motivo)
+
+	plan, err := services.ReoptimizarPlanRuta(rutaID, request)
+	if err != nil {
+		responderErrorPlan(c, err, "Error al reoptimizar ruta: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// GetRevisionesPlanRutaHandler devuelve el historial de revisiones de una ruta
+// @Summary Historial de revisiones de una ruta
+// @Description Devuelve todas las versiones del recorrido de la ruta: el plan original y cada reoptimización, con su motivo, la posición del camión y las paradas completas.
+// @Tags Rutas
+// @Produce json
+// @Param id path int true "ID de la ruta"
+// @Success 200 {object} services.RevisionesResponse "Revisiones de la ruta"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Ruta no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /rutas/{id}/revisiones [get]
+func GetRevisionesPlanRutaHandler(c *gin.Context) {
+	rutaID, ok := parseRutaID(c)
+	if !ok {
+		return
+	}
+
+	revisiones, err := services.GetRevisionesPlan(rutaID)
+	if err != nil {
+		responderErrorPlan(c, err, "Error al obtener revisiones: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, revisiones)
+}
+
+// parseRutaID lee y valida el ID de ruta de la URL; responde 400 si es inválido
+func parseRutaID(c *gin.Context) (int, bool) {
+	rutaID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || rutaID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de ruta inválido: debe ser un número entero mayor a 0",
+		})
+		return 0, false
+	}
+	return rutaID, true
+}
+
+// responderErrorPlan traduce los errores del servicio de rutas guardadas a respuestas HTTP
+func responderErrorPlan(c *gin.Context, err error, prefijo string) {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, " not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "transición inválida"):
+		c.JSON(http.StatusConflict, gin.H{"error": msg})
+	case strings.HasPrefix(msg, "id_zona es requerido"), strings.HasPrefix(msg, "min_capacidad"),
+		strings.HasPrefix(msg, "peso_prioridad"), strings.HasPrefix(msg, "motivo es requerido"),
+		strings.HasPrefix(msg, "la parada"), strings.HasPrefix(msg, "reoptimización inválida"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefijo + msg})
+	}
+}
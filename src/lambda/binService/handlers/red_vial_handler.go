@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRedVialHandler informa el estado de la red de calles
+// @Summary Estado de la red vial
+// @Description Indica si las rutas se calculan por calles (vial) o en línea recta (lineal) y el tamaño de la red cargada
+// @Tags Rutas
+// @Produce json
+// @Success 200 {object} services.EstadoRedVial "Estado de la red vial"
+// @Router /red-vial [get]
+func GetRedVialHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, services.GetEstadoRedVial())
+}
+
+// ImportarRedVialHandler importa la red de calles desde un extracto OSM
+// @Summary Importar red vial
+// @Description Reemplaza la red de calles en Neo4j con un extracto OSM en XML o PBF (intersecciones como nodos, cuadras como relaciones con largo y mano). Acepta el archivo en el campo multipart "archivo" o como cuerpo del request. La red nueva se carga aparte y reemplaza a la anterior solo si la importación termina bien.
+// @Tags Rutas
+// @Accept xml
+// @Accept mpfd
+// @Accept octet-stream
+// @Produce json
+// @Param archivo formData file false "Extracto OSM (XML o PBF)"
+// @Success 200 {object} services.ImportacionRedVial "Red vial importada"
+// @Failure 400 {object} map[string]string "Extracto inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /red-vial/importar [post]
+func ImportarRedVialHandler(c *gin.Context) {
+	var extracto io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		archivo, err := c.FormFile("archivo")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Falta el archivo: " + err.Error()})
+			return
+		}
+		f, err := archivo.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No se pudo leer el archivo: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		extracto = f
+	}
+
+	resultado, err := services.ImportarRedVial(extracto)
+	if err != nil {
+		if errors.Is(err, services.ErrExtractoOSM) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al importar red vial: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resultado)
+}
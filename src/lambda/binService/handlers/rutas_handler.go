@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
 	"github.com/gin-gonic/gin"
@@ -13,18 +14,31 @@ import (
 
 // GetRutaHandler obtiene la ruta óptima para una zona específica
 // @Summary Obtener ruta óptima
-// @Description Devuelve la ruta óptima y distancias para una zona específica
+// @Description Devuelve la ruta óptima y la distancia total para una zona específica. La ruta se cachea en Redis hasta que cambian los tachos de la zona o vence el TTL.
 // @Tags Rutas
 // @Accept json
 // @Produce json
 // @Param zonaID path int true "ID de la zona"
-// @Success 200 {array} map[string]interface{} "Lista de puntos con distancias"
-// @Failure 400 {object} map[string]string "zonaID inválido"
+// @Param algoritmo query string false "Algoritmo de ordenamiento (simple, vecino, 2opt, completo)" default(completo)
+// @Param origen_centro query int false "ID del centro desde donde parte el camión"
+// @Param origen_lat query number false "Latitud de partida (requiere origen_lng)"
+// @Param origen_lng query number false "Longitud de partida (requiere origen_lat)"
+// @Param destino_centro query int false "ID del centro de descarga final"
+// @Param camion query int false "ID del camión; activa el control de capacidad con descargas intermedias"
+// @Param min_capacidad query number false "Omite tachos con menor llenado (%), salvo los de prioridad urgente"
+// @Param peso_prioridad query number false "Visita primero los tachos urgentes; peso (0-1) de la prioridad frente al llenado"
+// @Param format query string false "Formato de salida (json, geojson, gpx, kml); sin él se usa el header Accept" default(json)
+// @Produce application/geo+json
+// @Produce application/gpx+xml
+// @Produce application/vnd.google-earth.kml+xml
+// @Success 200 {object} services.Ruta "Ruta ordenada con distancia total"
+// @Header 200 {string} X-Cache "HIT si la ruta salió de la caché, MISS si se calculó"
+// @Header 200 {string} X-Computed-At "Momento en que se calculó la ruta (RFC 3339)"
+// @Failure 400 {object} map[string]string "zonaID, algoritmo, formato, camión u origen/destino inválido"
+// @Failure 404 {object} map[string]string "Zona, centro o camión no encontrado"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /ruta-optima/{zonaID} [get]
 func GetRutaHandler(c *gin.Context) {
-	start := time.Now() // Para medir tiempo de cálculo cuando haya que calcular
-
 	zonaIDStr := c.Param("zonaID")
 	zonaID, err := strconv.Atoi(zonaIDStr)
 	if err != nil {
@@ -32,45 +46,183 @@ func GetRutaHandler(c *gin.Context) {
 		return
 	}
 
-	// Intentar obtener la ruta desde caché Redis
-	if cached, err := services.GetCachedRoute(zonaID); err == nil && len(cached) > 0 {
-		// Cache hit: devolver inmediatamente
-		c.JSON(http.StatusOK, cached)
+	opciones, status, err := parseRutaOpciones(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	formato, err := services.NegociarFormato(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	nombre := fmt.Sprintf("ruta-zona-%d", zonaID)
 
-	// Cache miss: calcular ruta
-	points, err := services.GetDistances(zonaID)
+	resultado, err := services.GetRutaZona(zonaID, opciones)
 	if err != nil {
+		if zonaNoEncontrada(err, zonaID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Zona no encontrada con ID: %d", zonaID)})
+			return
+		}
+		if err.Error() == fmt.Sprintf("camion with ID %d not found", opciones.IDCamion) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Camión no encontrado con ID: %d", opciones.IDCamion)})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Guardar en caché (no bloquear la respuesta en caso de error de cache)
-	if err := services.SetCachedRoute(zonaID, points); err != nil {
-		// Loguear pero no interrumpir
-		// fmt.Printf se evita en producción; usar logger si existe. Por ahora simple print.
-		fmt.Printf("Warning: failed to set cached route for zona %d: %v\n", zonaID, err)
+	headersCache(c, resultado)
+	responderRuta(c, formato, resultado.Ruta, nombre, resultado.Ruta)
+}
+
+// GetRutasFlotaHandler reparte los tachos de una zona entre los camiones operativos
+// @Summary Obtener rutas por camión
+// @Description Divide los tachos de la zona entre los camiones operativos asignados a la zona (o todos los operativos si la zona no tiene camiones asignados) y devuelve una ruta balanceada por camión
+// @Tags Rutas
+// @Accept json
+// @Produce json
+// @Param zonaID path int true "ID de la zona"
+// @Param camiones query int false "Cantidad máxima de camiones a usar (por defecto todos los disponibles para la zona)"
+// @Param algoritmo query string false "Algoritmo de ordenamiento (simple, vecino, 2opt, completo)" default(completo)
+// @Param origen_centro query int false "ID del centro desde donde parten los camiones"
+// @Param origen_lat query number false "Latitud de partida (requiere origen_lng)"
+// @Param origen_lng query number false "Longitud de partida (requiere origen_lat)"
+// @Param destino_centro query int false "ID del centro de descarga final"
+// @Param min_capacidad query number false "Omite tachos con menor llenado (%), salvo los de prioridad urgente"
+// @Param peso_prioridad query number false "Visita primero los tachos urgentes; peso (0-1) de la prioridad frente al llenado"
+// @Success 200 {object} services.RutasFlota "Rutas por camión"
+// @Failure 400 {object} map[string]string "Parámetros inválidos"
+// @Failure 404 {object} map[string]string "Zona o centro de origen/destino no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /ruta-optima/{zonaID}/camiones [get]
+func GetRutasFlotaHandler(c *gin.Context) {
+	start := time.Now()
+
+	zonaIDStr := c.Param("zonaID")
+	zonaID, err := strconv.Atoi(zonaIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "zonaID inválido"})
+		return
+	}
+
+	maxCamiones := 0
+	if camionesStr := c.Query("camiones"); camionesStr != "" {
+		maxCamiones, err = strconv.Atoi(camionesStr)
+		if err != nil || maxCamiones <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "camiones debe ser un número entero mayor a 0"})
+			return
+		}
+	}
+
+	opciones, status, err := parseRutaOpciones(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	flota, err := services.GetRutasFlota(zonaID, opciones, maxCamiones)
+	if err != nil {
+		if zonaNoEncontrada(err, zonaID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Zona no encontrada con ID: %d", zonaID)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Registrar métricas de negocio solo en cálculos reales
-	duration := time.Since(start).Seconds()
 	middleware.IncrementRutasOptimas(zonaIDStr)
-	middleware.ObserveRutaCalculoTime(zonaIDStr, duration)
+	middleware.ObserveRutaCalculoTime(zonaIDStr, time.Since(start).Seconds())
 
-	c.JSON(http.StatusOK, points)
+	c.JSON(http.StatusOK, flota)
+}
+
+// GetAgendaZonaHandler arma el recorrido de una zona con horario por parada
+// @Summary Obtener agenda de recorrido
+// @Description Arma el recorrido de la zona con llegada, espera e inicio estimados por parada, respetando las ventanas horarias de los tachos y el turno de la persona. Informa los tachos que no entran y el motivo (fuera_de_ventana, fuera_de_turno). Si la fecha es hoy el recorrido arranca ahora. El orden lo deciden los horarios, así que no acepta algoritmo, camion ni peso_prioridad.
+// @Tags Rutas
+// @Produce json
+// @Param zonaID path int true "ID de la zona"
+// @Param persona query string false "ID de la persona cuyo turno se respeta (sin ella se usa el día completo)"
+// @Param fecha query string false "Día del recorrido (YYYY-MM-DD), por defecto hoy"
+// @Param origen_centro query int false "ID del centro desde donde parte el camión"
+// @Param origen_lat query number false "Latitud de partida (requiere origen_lng)"
+// @Param origen_lng query number false "Longitud de partida (requiere origen_lat)"
+// @Param destino_centro query int false "ID del centro de descarga final"
+// @Param min_capacidad query number false "Omite tachos con menor llenado (%), salvo los de prioridad urgente"
+// @Success 200 {object} services.Agenda "Recorrido con horarios"
+// @Failure 400 {object} map[string]string "Parámetros inválidos o que no se aplican a la agenda"
+// @Failure 404 {object} map[string]string "Zona, centro o persona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /ruta-optima/{zonaID}/agenda [get]
+func GetAgendaZonaHandler(c *gin.Context) {
+	zonaID, err := strconv.Atoi(c.Param("zonaID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "zonaID inválido"})
+		return
+	}
+
+	// La agenda ordena por horario y no controla capacidad
+	for _, param := range []string{"algoritmo", "camion", "peso_prioridad"} {
+		if c.Query(param) != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s no se aplica a la agenda", param)})
+			return
+		}
+	}
+
+	opciones, status, err := parseRutaOpciones(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	fecha := time.Now().In(config.ZonaHoraria())
+	if fechaStr := c.Query("fecha"); fechaStr != "" {
+		fecha, err = time.ParseInLocation("2006-01-02", fechaStr, config.ZonaHoraria())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "fecha inválida: debe tener formato YYYY-MM-DD"})
+			return
+		}
+	}
+
+	personaID := c.Query("persona")
+	agenda, err := services.GetAgendaZona(zonaID, opciones, personaID, fecha)
+	if err != nil {
+		if zonaNoEncontrada(err, zonaID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Zona no encontrada con ID: %d", zonaID)})
+			return
+		}
+		if err.Error() == fmt.Sprintf("persona with ID %s not found", personaID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Persona no encontrada con ID: " + personaID})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, agenda)
 }
 
 // GetRutaHandlerByHeader obtiene la ruta óptima basada en el email del header
 // @Summary Obtener ruta óptima por email
-// @Description Devuelve la ruta óptima y distancias para la zona de la persona asociada al email
+// @Description Devuelve la ruta óptima y distancias para la zona de la persona asociada al email. Comparte la caché de Redis con /ruta-optima/{zonaID}.
 // @Tags Rutas
 // @Accept json
 // @Produce json
 // @Param email header string true "email del usuario"
+// @Param algoritmo query string false "Algoritmo de ordenamiento (simple, vecino, 2opt, completo)" default(completo)
+// @Param min_capacidad query number false "Omite tachos con menor llenado (%), salvo los de prioridad urgente"
+// @Param peso_prioridad query number false "Visita primero los tachos urgentes; peso (0-1) de la prioridad frente al llenado"
+// @Param format query string false "Formato de salida (json, geojson, gpx, kml); sin él se usa el header Accept" default(json)
+// @Produce application/geo+json
+// @Produce application/gpx+xml
+// @Produce application/vnd.google-earth.kml+xml
 // @Success 200 {array} map[string]interface{} "Lista de puntos con distancias"
+// @Header 200 {string} X-Cache "HIT si la ruta salió de la caché, MISS si se calculó"
+// @Header 200 {string} X-Computed-At "Momento en que se calculó la ruta (RFC 3339)"
 // @Failure 400 {object} map[string]string "Email faltante o inválido"
-// @Failure 404 {object} map[string]string "Usuario o persona no encontrada"
+// @Failure 404 {object} map[string]string "Usuario, persona o zona no encontrada"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /ruta-optima [get]
 func GetRutaHandlerByHeader(c *gin.Context) {
@@ -109,18 +261,160 @@ func GetRutaHandlerByHeader(c *gin.Context) {
 		return
 	}
 
-	// Obtener las distancias/rutas para la zona
-	points, err := services.GetDistances(zonaID)
+	opciones, status, err := parseRutaOpciones(c)
 	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	formato, err := services.NegociarFormato(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Obtener la ruta de la zona (cacheada si ya se calculó)
+	resultado, err := services.GetRutaZona(zonaID, opciones)
+	if err != nil {
+		if zonaNoEncontrada(err, zonaID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Zona no encontrada con ID: %d", zonaID)})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"email":     email,
-		"persona":   personaNumStr,
-		"zona_id":   zonaID,
-		"zona_name": persona["zona_nombre"],
-		"routes":    points,
+	ruta := resultado.Ruta
+	headersCache(c, resultado)
+	responderRuta(c, formato, ruta, fmt.Sprintf("ruta-zona-%d", zonaID), gin.H{
+		"email":              email,
+		"persona":            personaNumStr,
+		"zona_id":            zonaID,
+		"zona_name":          persona["zona_nombre"],
+		"routes":             ruta.Puntos,
+		"distancia_total_km": ruta.DistanciaTotal,
+		"calculada_en":       ruta.CalculadaEn,
 	})
 }
+
+// headersCache indica si la ruta salió de la caché (X-Cache: HIT o MISS) y
+// cuándo se calculó (X-Computed-At, RFC 3339)
+func headersCache(c *gin.Context, resultado *services.RutaZona) {
+	if resultado.DesdeCache {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
+	if resultado.Ruta.CalculadaEn != nil {
+		c.Header("X-Computed-At", resultado.Ruta.CalculadaEn.Format(time.RFC3339))
+	}
+}
+
+// responderRuta envía la ruta en el formato negociado. Para JSON se envía
+// cuerpoJSON tal cual; el resto de los formatos se arma desde la ruta.
+func responderRuta(c *gin.Context, formato string, ruta *services.Ruta, nombre string, cuerpoJSON interface{}) {
+	if formato == services.FormatoJSON {
+		c.JSON(http.StatusOK, cuerpoJSON)
+		return
+	}
+
+	data, err := services.ExportarRuta(ruta, formato, nombre)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al exportar la ruta: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", nombre+"."+formato))
+	c.Data(http.StatusOK, services.ContentTypeFormato(formato), data)
+}
+
+// parseRutaOpciones lee algoritmo, origen y destino de los query params.
+// Devuelve el status HTTP a usar si hay error.
+func parseRutaOpciones(c *gin.Context) (services.RutaOpciones, int, error) {
+	var opciones services.RutaOpciones
+
+	algoritmo, err := services.ValidarAlgoritmo(c.Query("algoritmo"))
+	if err != nil {
+		return opciones, http.StatusBadRequest, err
+	}
+	opciones.Algoritmo = algoritmo
+
+	// Origen: un centro o coordenadas sueltas
+	if centroStr := c.Query("origen_centro"); centroStr != "" {
+		origen, status, err := resolverCentro(centroStr, services.TipoPuntoOrigen)
+		if err != nil {
+			return opciones, status, err
+		}
+		opciones.Origen = origen
+	} else if latStr, lngStr := c.Query("origen_lat"), c.Query("origen_lng"); latStr != "" || lngStr != "" {
+		lat, errLat := strconv.ParseFloat(latStr, 64)
+		lng, errLng := strconv.ParseFloat(lngStr, 64)
+		if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			return opciones, http.StatusBadRequest, fmt.Errorf("origen_lat y origen_lng deben ser coordenadas válidas")
+		}
+		opciones.Origen = &services.Point{Tipo: services.TipoPuntoOrigen, Lat: lat, Lng: lng}
+	}
+
+	// Destino: centro de descarga final
+	if centroStr := c.Query("destino_centro"); centroStr != "" {
+		destino, status, err := resolverCentro(centroStr, services.TipoPuntoDestino)
+		if err != nil {
+			return opciones, status, err
+		}
+		opciones.Destino = destino
+	}
+
+	// Camión para el control de capacidad
+	if camionStr := c.Query("camion"); camionStr != "" {
+		camionID, err := strconv.Atoi(camionStr)
+		if err != nil || camionID <= 0 {
+			return opciones, http.StatusBadRequest, fmt.Errorf("ID de camión inválido: %s", camionStr)
+		}
+		opciones.IDCamion = camionID
+	}
+
+	// Selección de paradas por llenado y prioridad
+	if minStr := c.Query("min_capacidad"); minStr != "" {
+		minCapacidad, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			return opciones, http.StatusBadRequest, fmt.Errorf("min_capacidad inválido: %s", minStr)
+		}
+		opciones.MinCapacidad = minCapacidad
+	}
+	if pesoStr := c.Query("peso_prioridad"); pesoStr != "" {
+		peso, err := strconv.ParseFloat(pesoStr, 64)
+		if err != nil {
+			return opciones, http.StatusBadRequest, fmt.Errorf("peso_prioridad inválido: %s", pesoStr)
+		}
+		opciones.Priorizar = true
+		opciones.PesoPrioridad = peso
+	}
+	if err := services.ValidarSeleccion(opciones.MinCapacidad, opciones.PesoPrioridad); err != nil {
+		return opciones, http.StatusBadRequest, err
+	}
+
+	return opciones, http.StatusOK, nil
+}
+
+// resolverCentro convierte un ID de centro en el punto de la ruta correspondiente
+func resolverCentro(centroStr string, tipo string) (*services.Point, int, error) {
+	centroID, err := strconv.Atoi(centroStr)
+	if err != nil || centroID <= 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("ID de centro inválido: %s", centroStr)
+	}
+
+	point, err := services.GetCentroPoint(centroID, tipo)
+	if err != nil {
+		if err.Error() == "centro with ID "+centroStr+" not found" {
+			return nil, http.StatusNotFound, fmt.Errorf("Centro no encontrado con ID: %s", centroStr)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return point, http.StatusOK, nil
+}
+
+// zonaNoEncontrada indica si el error corresponde a una zona inexistente
+func zonaNoEncontrada(err error, zonaID int) bool {
+	return err.Error() == fmt.Sprintf("zona with ID %d not found", zonaID)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadersCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calculada := time.Date(2024, 5, 10, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		desdeCache bool
+		wantCache  string
+	}{
+		{"Ruta cacheada", true, "HIT"},
+		{"Ruta calculada", false, "MISS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/ruta", func(c *gin.Context) {
+				headersCache(c, &services.RutaZona{
+					Ruta:       &services.Ruta{CalculadaEn: &calculada},
+					DesdeCache: tt.desdeCache,
+				})
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/ruta", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCache, w.Header().Get("X-Cache"))
+			assert.Equal(t, "2024-05-10T08:30:00Z", w.Header().Get("X-Computed-At"))
+		})
+	}
+}
+
+func TestGetAgendaZonaHandlerRechazaParametros(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ruta-optima/:zonaID/agenda", GetAgendaZonaHandler)
+
+	for _, query := range []string{"algoritmo=2opt", "camion=3", "peso_prioridad=0.5"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/ruta-optima/1/agenda?"+query, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), "no se aplica a la agenda", query)
+	}
+}
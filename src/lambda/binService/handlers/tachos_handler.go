@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
@@ -11,13 +13,14 @@ import (
 
 // CreateTachoHandler crea un nuevo tacho en MySQL y Neo4j
 // @Summary Crear un nuevo tacho
-// @Description Crea un tacho guardándolo tanto en MySQL como en Neo4j
+// @Description Crea el tacho en MySQL y deja su alta en Neo4j en el outbox, en la misma transacción. El nodo se crea enseguida si Neo4j responde (neo_node_id); si no, lo crea el worker del outbox con reintentos (ver /admin/outbox).
 // @Tags Tachos
 // @Accept json
 // @Produce json
 // @Param tacho body services.CreateTachoRequest true "Datos del tacho a crear"
 // @Success 201 {object} services.CreateTachoResponse "Tacho creado exitosamente"
 // @Failure 400 {object} map[string]string "Datos de entrada inválidos"
+// @Failure 409 {object} map[string]string "Ya existe un tacho en esa dirección y barrio"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /tachos [post]
 func CreateTachoHandler(c *gin.Context) {
@@ -32,6 +35,10 @@ func CreateTachoHandler(c *gin.Context) {
 	// Crear el tacho usando el servicio
 	response, err := services.CreateTacho(request)
 	if err != nil {
+		if errors.Is(err, services.ErrCustomIDDuplicado) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -46,59 +53,95 @@ func CreateTachoHandler(c *gin.Context) {
 
 // DeleteTachoHandler elimina un tacho de MySQL y Neo4j
 // @Summary Eliminar un tacho
-// @Description Elimina un tacho tanto de MySQL como de Neo4j usando query parameters (custom_id O direccion+barrio)
+// @Description Elimina un tacho por id_tacho o por custom id exacto (custom_id O direccion+barrio). La baja en Neo4j pasa por el outbox: si Neo4j no responde la aplica después el worker (ver /admin/outbox). Al borrar por id_tacho, el nodo se conserva si otra fila usa el mismo custom id. Con dry_run=true solo informa qué se borraría. La respuesta informa el resultado en cada base.
 // @Tags Tachos
 // @Accept json
 // @Produce json
+// @Param id_tacho query int false "ID del tacho en MySQL"
 // @Param custom_id query string false "ID personalizado del tacho (direccion|barrio)"
 // @Param direccion query string false "Dirección del tacho (requiere también barrio)"
 // @Param barrio query string false "Barrio del tacho (requerido si se pasa direccion)"
-// @Success 200 {object} map[string]string "Tacho eliminado exitosamente"
+// @Param dry_run query bool false "Solo mostrar qué se borraría"
+// @Success 200 {object} services.DeleteTachoResponse "Tacho eliminado (o vista previa)"
+// @Success 202 {object} services.DeleteTachoResponse "Eliminado de MySQL; la baja en Neo4j quedó pendiente"
 // @Failure 400 {object} map[string]string "Parámetros inválidos"
 // @Failure 404 {object} map[string]string "Tacho no encontrado en ninguna base"
 // @Failure 500 {object} map[string]string "Error interno del servidor"
 // @Router /tachos [delete]
 func DeleteTachoHandler(c *gin.Context) {
 	// Obtener parámetros de query
+	idTacho := c.Query("id_tacho")
 	customID := c.Query("custom_id")
 	direccion := c.Query("direccion")
 	barrio := c.Query("barrio")
 
-	var finalCustomID string
+	var request services.DeleteTachoRequest
 
-	// Validar que se proporcione al menos una opción válida
+	// Validar que se proporcione exactamente una opción válida
+	opciones := 0
+	if idTacho != "" {
+		// Opción 1: id_tacho de MySQL
+		id, err := strconv.Atoi(idTacho)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id_tacho inválido: debe ser un número entero mayor a 0"})
+			return
+		}
+		request.IDTacho = id
+		opciones++
+	}
 	if customID != "" {
-		// Opción 1: usar custom_id directamente
-		finalCustomID = customID
-	} else if direccion != "" && barrio != "" {
-		// Opción 2: construir custom_id desde direccion + barrio
-		finalCustomID = direccion + "|" + barrio
-	} else {
+		// Opción 2: usar custom_id directamente
+		request.CustomID = customID
+		opciones++
+	}
+	if direccion != "" || barrio != "" {
+		if direccion == "" || barrio == "" {
+			opciones = -1
+		} else {
+			// Opción 3: construir custom_id desde direccion + barrio
+			request.CustomID = direccion + "|" + barrio
+			opciones++
+		}
+	}
+	if opciones != 1 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Debe proporcionar 'custom_id' O ('direccion' + 'barrio')",
+			"error": "Debe proporcionar solo uno de 'id_tacho', 'custom_id' o ('direccion' + 'barrio')",
 			"examples": map[string]string{
-				"opcion1": "?custom_id=Av Corrientes 1234|CHACARITA",
-				"opcion2": "?direccion=Av Corrientes 1234&barrio=CHACARITA",
+				"opcion1": "?id_tacho=42",
+				"opcion2": "?custom_id=Av Corrientes 1234|CHACARITA",
+				"opcion3": "?direccion=Av Corrientes 1234&barrio=CHACARITA",
 			},
 		})
 		return
 	}
 
-	// Eliminar el tacho usando el servicio simplificado
-	err := services.DeleteTacho(finalCustomID)
+	if dryRun := c.Query("dry_run"); dryRun != "" {
+		valor, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run inválido: use true o false"})
+			return
+		}
+		request.DryRun = valor
+	}
+
+	response, err := services.DeleteTacho(request)
 	if err != nil {
-		if strings.Contains(err.Error(), "no se pudo eliminar de ninguna base de datos") {
+		switch {
+		case strings.HasSuffix(err.Error(), " not found"):
 			c.JSON(http.StatusNotFound, gin.H{"error": "Tacho no encontrado en ninguna base de datos"})
-			return
+		case strings.HasPrefix(err.Error(), "eliminación inválida"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Tacho eliminado exitosamente",
-		"custom_id": finalCustomID,
-	})
+	if response.Pendiente {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetAllTachosHandler obtiene todos los tachos con información completa
@@ -123,3 +166,129 @@ func GetAllTachosHandler(c *gin.Context) {
 		"total":  len(tachos),
 	})
 }
+
+// tachoIDParam lee el id_tacho de la URL; responde 400 si no es válido
+func tachoIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id_tacho"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id_tacho inválido: debe ser un número entero mayor a 0"})
+		return 0, false
+	}
+	return id, true
+}
+
+// responderErrorTacho traduce los errores del servicio de tachos a códigos HTTP
+func responderErrorTacho(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCustomIDDuplicado):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasSuffix(err.Error(), " not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tacho no encontrado"})
+	case strings.HasPrefix(err.Error(), "actualización inválida"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "transición inválida"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetTachoHandler obtiene un tacho por su ID
+// @Summary Obtener un tacho
+// @Description Devuelve el tacho con los datos de MySQL (tipo, estado, capacidad) y de Neo4j (ubicación, prioridad). en_neo4j indica si tiene nodo y sincronizacion_pendiente si hay cambios en el outbox que Neo4j todavía no tiene.
+// @Tags Tachos
+// @Produce json
+// @Param id_tacho path int true "ID del tacho"
+// @Success 200 {object} services.TachoDetalle "Tacho"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Tacho no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /tachos/{id_tacho} [get]
+func GetTachoHandler(c *gin.Context) {
+	id, ok := tachoIDParam(c)
+	if !ok {
+		return
+	}
+
+	tacho, err := services.GetTacho(id)
+	if err != nil {
+		responderErrorTacho(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tacho)
+}
+
+// UpdateTachoHandler actualiza los campos indicados de un tacho
+// @Summary Actualizar un tacho
+// @Description Cambia tipo, estado, capacidad (0-100), prioridad (1-5) o dirección. Los campos que no se envían quedan igual. Cambiar la dirección cambia el custom id (direccion|barrio) en MySQL, Neo4j, la zona asignada, las ventanas horarias y las paradas de los planes. Los cambios de Neo4j pasan por el outbox (ver /admin/outbox).
+// @Tags Tachos
+// @Accept json
+// @Produce json
+// @Param id_tacho path int true "ID del tacho"
+// @Param cambios body services.UpdateTachoRequest true "Campos a cambiar"
+// @Success 200 {object} services.TachoDetalle "Tacho actualizado"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Tacho no encontrado"
+// @Failure 409 {object} map[string]string "El nuevo custom id ya existe o el tacho no tiene nodo en Neo4j"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /tachos/{id_tacho} [patch]
+func UpdateTachoHandler(c *gin.Context) {
+	id, ok := tachoIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request services.UpdateTachoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	tacho, err := services.UpdateTacho(id, request)
+	if err != nil {
+		responderErrorTacho(c, err)
+		return
+	}
+
+	if request.Capacidad != nil {
+		middleware.UpdateTachoCapacidad(strconv.Itoa(id), tacho.Barrio, *request.Capacidad)
+	}
+	if request.Prioridad != nil {
+		middleware.UpdateTachoPrioridad(strconv.Itoa(id), tacho.Barrio, float64(*request.Prioridad))
+	}
+	c.JSON(http.StatusOK, tacho)
+}
+
+// ReubicarTachoHandler mueve un tacho a otra ubicación
+// @Summary Reubicar un tacho
+// @Description Mueve el tacho a la nueva ubicación y recalcula su zona. Si se indican dirección o barrio el custom id cambia con ellos en MySQL, Neo4j, la zona asignada, las ventanas horarias y las paradas de los planes. Se invalidan las rutas de la zona anterior y de la nueva.
+// @Tags Tachos
+// @Accept json
+// @Produce json
+// @Param id_tacho path int true "ID del tacho"
+// @Param ubicacion body services.ReubicarTachoRequest true "Nueva ubicación"
+// @Success 200 {object} services.TachoDetalle "Tacho reubicado"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Tacho no encontrado"
+// @Failure 409 {object} map[string]string "El nuevo custom id ya existe o el tacho no tiene nodo en Neo4j"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /tachos/{id_tacho}/reubicar [post]
+func ReubicarTachoHandler(c *gin.Context) {
+	id, ok := tachoIDParam(c)
+	if !ok {
+		return
+	}
+
+	var request services.ReubicarTachoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	tacho, err := services.ReubicarTacho(id, request)
+	if err != nil {
+		responderErrorTacho(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tacho)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteTachoHandlerParametrosInvalidos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"Sin parámetros", ""},
+		{"id_tacho no numérico", "?id_tacho=abc"},
+		{"id_tacho y custom_id", "?id_tacho=3&custom_id=Calle%201|BOEDO"},
+		{"Dirección sin barrio", "?direccion=Calle%201"},
+		{"dry_run inválido", "?custom_id=Calle%201|BOEDO&dry_run=quizas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.DELETE("/tachos", DeleteTachoHandler)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/tachos"+tt.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestUpdateTachoHandlerDatosInvalidos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+	}{
+		{"id_tacho no numérico", "/tachos/abc", `{"capacidad":10}`},
+		{"Sin campos", "/tachos/3", `{}`},
+		{"Capacidad fuera de rango", "/tachos/3", `{"capacidad":120}`},
+		{"Prioridad fuera de rango", "/tachos/3", `{"prioridad":9}`},
+		{"Dirección con separador", "/tachos/3", `{"direccion":"Calle 1|BOEDO"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.PATCH("/tachos/:id_tacho", UpdateTachoHandler)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestResponderErrorTacho(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("tacho with ID 7 not found"), http.StatusNotFound},
+		{errors.New("actualización inválida: prioridad fuera de rango (1-5)"), http.StatusBadRequest},
+		{fmt.Errorf("%w: Calle 2|BOEDO", services.ErrCustomIDDuplicado), http.StatusConflict},
+		{errors.New("transición inválida: el tacho 7 no tiene nodo en Neo4j (ver /admin/reconciliacion)"), http.StatusConflict},
+		{errors.New("error updating Tacho: timeout"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			responderErrorTacho(c, tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
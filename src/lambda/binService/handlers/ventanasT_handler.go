@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetVentanasTachoHandler devuelve las ventanas horarias de un tacho
+// @Summary Obtener ventanas horarias del tacho
+// @Description Devuelve los horarios (HH:MM) en los que se puede vaciar el tacho. Sin ventanas se puede vaciar a cualquier hora.
+// @Tags Tachos
+// @Produce json
+// @Param id_tacho path int true "ID del tacho"
+// @Success 200 {object} services.VentanasRequest "Ventanas del tacho"
+// @Failure 400 {object} map[string]string "ID inválido"
+// @Failure 404 {object} map[string]string "Tacho no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /tachos/{id_tacho}/ventanas [get]
+func GetVentanasTachoHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id_tacho"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	ventanas, err := services.GetVentanasTacho(id)
+	if err != nil {
+		responderErrorVentanas(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.VentanasRequest{Ventanas: ventanas})
+}
+
+// UpdateVentanasTachoHandler reemplaza las ventanas horarias de un tacho
+// @Summary Actualizar ventanas horarias del tacho
+// @Description Reemplaza los horarios (HH:MM) en los que se puede vaciar el tacho. Una lista vacía lo deja sin restricción. Las ventanas que cruzan la medianoche se cargan como dos.
+// @Tags Tachos
+// @Accept json
+// @Produce json
+// @Param id_tacho path int true "ID del tacho"
+// @Param ventanas body services.VentanasRequest true "Nuevas ventanas del tacho"
+// @Success 200 {object} services.VentanasRequest "Ventanas actualizadas"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Tacho no encontrado"
+// @Failure 500 {object} map[string]string "Error interno"
+// @Router /tachos/{id_tacho}/ventanas [put]
+func UpdateVentanasTachoHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id_tacho"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var body services.VentanasRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		return
+	}
+
+	ventanas, err := services.SetVentanasTacho(id, body.Ventanas)
+	if err != nil {
+		responderErrorVentanas(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.VentanasRequest{Ventanas: ventanas})
+}
+
+// responderErrorVentanas traduce los errores de ventanas a respuestas HTTP
+func responderErrorVentanas(c *gin.Context, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "hora inválida"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.HasSuffix(err.Error(), " not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tacho no encontrado"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al procesar ventanas: " + err.Error()})
+	}
+}
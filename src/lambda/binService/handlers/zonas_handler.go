@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAllZonasHandler obtiene todas las zonas
+// @Summary Obtener todas las zonas
+// @Description Obtiene todas las zonas con sus barrios, límite GeoJSON y camiones asignados
+// @Tags Zonas
+// @Produce json
+// @Success 200 {object} services.ZonasResponse "Lista de zonas obtenida exitosamente"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas [get]
+func GetAllZonasHandler(c *gin.Context) {
+	response, err := services.GetAllZonas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error al obtener zonas: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetZonaByIDHandler obtiene una zona específica por ID
+// @Summary Obtener una zona por ID
+// @Description Obtiene una zona con sus barrios, límite GeoJSON y camiones asignados
+// @Tags Zonas
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Success 200 {object} services.ZonaResponse "Zona obtenida exitosamente"
+// @Failure 400 {object} map[string]string "ID de zona inválido"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id} [get]
+func GetZonaByIDHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+
+	response, err := services.GetZonaByID(zonaID)
+	if err != nil {
+		responderErrorZona(c, err, zonaID, "Error al obtener zona: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateZonaHandler crea una nueva zona
+// @Summary Crear una zona
+// @Description Crea una zona con nombre, barrios, límite GeoJSON opcional (Polygon o MultiPolygon) y camiones asignados
+// @Tags Zonas
+// @Accept json
+// @Produce json
+// @Param zona body services.ZonaRequest true "Datos de la zona"
+// @Success 201 {object} services.ZonaResponse "Zona creada exitosamente"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Camión no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas [post]
+func CreateZonaHandler(c *gin.Context) {
+	var request services.ZonaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+	if err := services.ValidarZonaRequest(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := services.CreateZona(request)
+	if err != nil {
+		responderErrorZona(c, err, 0, "Error al crear zona: ")
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateZonaHandler reemplaza los datos de una zona
+// @Summary Actualizar una zona
+// @Description Reemplaza nombre, barrios, límite GeoJSON y camiones asignados de una zona
+// @Tags Zonas
+// @Accept json
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Param zona body services.ZonaRequest true "Datos de la zona"
+// @Success 200 {object} services.ZonaResponse "Zona actualizada exitosamente"
+// @Failure 400 {object} map[string]string "Datos inválidos"
+// @Failure 404 {object} map[string]string "Zona o camión no encontrado"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id} [put]
+func UpdateZonaHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+
+	var request services.ZonaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+	if err := services.ValidarZonaRequest(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := services.UpdateZona(zonaID, request)
+	if err != nil {
+		responderErrorZona(c, err, zonaID, "Error al actualizar zona: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteZonaHandler elimina una zona
+// @Summary Eliminar una zona
+// @Description Elimina una zona junto con sus barrios, límite y camiones asignados
+// @Tags Zonas
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Success 200 {object} map[string]interface{} "Zona eliminada exitosamente"
+// @Failure 400 {object} map[string]string "ID de zona inválido"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id} [delete]
+func DeleteZonaHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteZona(zonaID); err != nil {
+		responderErrorZona(c, err, zonaID, "Error al eliminar zona: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Zona eliminada exitosamente",
+		"id_zona": zonaID,
+	})
+}
+
+// GetZonaResumenHandler obtiene el resumen operativo de una zona
+// @Summary Obtener resumen de una zona
+// @Description Devuelve la zona con cantidad de tachos, llenado promedio y personas asignadas
+// @Tags Zonas
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Success 200 {object} services.ZonaResumen "Resumen de la zona"
+// @Failure 400 {object} map[string]string "ID de zona inválido"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id}/resumen [get]
+func GetZonaResumenHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+
+	resumen, err := services.GetZonaResumen(zonaID)
+	if err != nil {
+		responderErrorZona(c, err, zonaID, "Error al obtener resumen de zona: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, resumen)
+}
+
+// GetMatrizDistanciasZonaHandler devuelve la matriz de distancias de una zona
+// @Summary Obtener matriz de distancias de una zona
+// @Description Devuelve la matriz de distancias (km) y duraciones estimadas (min) entre los tachos de la zona, guardada en Redis. Se actualiza al crear o eliminar tachos; con refrescar=true se recalcula completa.
+// @Tags Zonas
+// @Produce json
+// @Param id path int true "ID de la zona"
+// @Param refrescar query bool false "Recalcular la matriz completa"
+// @Success 200 {object} services.MatrizZona "Matriz de la zona"
+// @Failure 400 {object} map[string]string "ID de zona inválido"
+// @Failure 404 {object} map[string]string "Zona no encontrada"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/{id}/matriz-distancias [get]
+func GetMatrizDistanciasZonaHandler(c *gin.Context) {
+	zonaID, ok := parseZonaID(c)
+	if !ok {
+		return
+	}
+
+	matriz, err := services.GetMatrizZona(zonaID, c.Query("refrescar") == "true")
+	if err != nil {
+		responderErrorZona(c, err, zonaID, "Error al obtener matriz de distancias: ")
+		return
+	}
+
+	c.JSON(http.StatusOK, matriz)
+}
+
+// ImportarLimitesZonasHandler importa los límites de las zonas desde GeoJSON
+// @Summary Importar límites de zonas
+// @Description Recibe un GeoJSON FeatureCollection con un Feature (Polygon o MultiPolygon) por zona, identificada por properties.id_zona o properties.nombre. Guarda los límites y reasigna los tachos por ubicación.
+// @Tags Zonas
+// @Accept json
+// @Produce json
+// @Param limites body object true "FeatureCollection con los límites"
+// @Success 200 {object} services.ImportacionLimites "Límites importados y tachos reasignados"
+// @Failure 400 {object} map[string]string "GeoJSON inválido"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/limites [post]
+func ImportarLimitesZonasHandler(c *gin.Context) {
+	raw, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+	if err := services.ValidarLimitesZonas(raw); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resultado, err := services.ImportarLimitesZonas(raw)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error al importar límites: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resultado)
+}
+
+// GetAsignacionTachosHandler reporta la asignación de tachos a zonas por ubicación
+// @Summary Reporte de asignación de tachos a zonas
+// @Description Calcula sin guardar la zona de cada tacho según los límites y lista los tachos fuera de toda zona o dentro de más de una
+// @Tags Zonas
+// @Produce json
+// @Success 200 {object} services.AsignacionReporte "Reporte de asignación"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/asignacion [get]
+func GetAsignacionTachosHandler(c *gin.Context) {
+	reporte, err := services.GetReporteAsignacion()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error al calcular la asignación: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, reporte)
+}
+
+// AsignarTachosHandler recalcula y guarda la zona de cada tacho
+// @Summary Reasignar tachos a zonas
+// @Description Recalcula la zona de cada tacho por punto en polígono sobre su ubicación en Neo4j y guarda la asignación
+// @Tags Zonas
+// @Produce json
+// @Success 200 {object} services.AsignacionReporte "Tachos reasignados"
+// @Failure 500 {object} map[string]string "Error interno del servidor"
+// @Router /zonas/asignacion [post]
+func AsignarTachosHandler(c *gin.Context) {
+	reporte, err := services.AsignarTachosAZonas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Error al asignar tachos: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, reporte)
+}
+
+// parseZonaID lee y valida el ID de zona de la URL; responde 400 si es inválido
+func parseZonaID(c *gin.Context) (int, bool) {
+	zonaID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || zonaID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "ID de zona inválido: debe ser un número entero mayor a 0",
+		})
+		return 0, false
+	}
+	return zonaID, true
+}
+
+// responderErrorZona traduce los errores del servicio de zonas a respuestas HTTP
+func responderErrorZona(c *gin.Context, err error, zonaID int, prefijo string) {
+	msg := err.Error()
+	switch {
+	case zonaID > 0 && zonaNoEncontrada(err, zonaID):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zona no encontrada con ID: " + strconv.Itoa(zonaID)})
+	case strings.HasPrefix(msg, "camion with ID "):
+		c.JSON(http.StatusNotFound, gin.H{"error": msg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefijo + msg})
+	}
+}
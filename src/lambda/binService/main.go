@@ -25,6 +25,7 @@ import (
 	_ "github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/docs" // Import generated docs
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/routes"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/services"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -34,6 +35,9 @@ func main() {
 	// Connect to MySQL
 	config.ConnectDatabase()
 
+	// Create auxiliary tables
+	config.MigrateDatabase()
+
 	// Connect to Redis and initialize data
 	config.ConnectRedis()
 
@@ -41,6 +45,20 @@ func main() {
 	_, err := config.GetNeo4jDriver()
 	if err != nil {
 		log.Printf("Warning: Neo4j connection failed: %v", err)
+	} else {
+		// Load the street graph used for road distances (straight lines if missing)
+		go func() {
+			if err := services.CargarRedVial(); err != nil {
+				log.Printf("Warning: street graph not loaded: %v", err)
+			}
+		}()
+	}
+
+	// Generate the scheduled collection routes ahead of time
+	if config.DB != nil {
+		services.IniciarGeneradorCalendario()
+		// Apply MySQL changes to Neo4j from the outbox
+		services.IniciarWorkerOutbox()
 	}
 
 	// Close Neo4j driver on app shutdown
@@ -55,7 +73,7 @@ func main() {
 	// Configuración de CORS
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // tu frontend
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
@@ -48,6 +48,22 @@ var (
 		[]string{"zona_id"},
 	)
 
+	rutasCache = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rutas_cache_requests_total",
+			Help: "Total number of route cache lookups by result",
+		},
+		[]string{"resultado"}, // hit, miss
+	)
+
+	rutasCacheInvalidadas = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rutas_cache_invalidaciones_total",
+			Help: "Total number of cached routes invalidated",
+		},
+		[]string{"motivo"},
+	)
+
 	// Business metrics - Personas
 	personasTotal = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -73,6 +89,30 @@ var (
 		[]string{"tipo", "zona"},
 	)
 
+	// Outbox MySQL -> Neo4j
+	outboxLag = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_lag_seconds",
+			Help: "Age of the oldest outbox event not yet applied to Neo4j",
+		},
+	)
+
+	outboxEventos = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_eventos",
+			Help: "Number of outbox events by state",
+		},
+		[]string{"estado"}, // pendiente, fallido
+	)
+
+	outboxAplicados = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_eventos_procesados_total",
+			Help: "Total number of outbox events processed by result",
+		},
+		[]string{"resultado"}, // aplicado, error, fallido
+	)
+
 	// Database connection metrics
 	databaseConnections = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -118,6 +158,16 @@ func ObserveRutaCalculoTime(zonaID string, duration float64) {
 	rutasTiempoCalculo.WithLabelValues(zonaID).Observe(duration)
 }
 
+// IncrementRutaCache counts a route cache lookup as hit or miss
+func IncrementRutaCache(resultado string) {
+	rutasCache.WithLabelValues(resultado).Inc()
+}
+
+// AddRutasCacheInvalidadas counts cached routes removed after a data change
+func AddRutasCacheInvalidadas(motivo string, cantidad int) {
+	rutasCacheInvalidadas.WithLabelValues(motivo).Add(float64(cantidad))
+}
+
 // UpdatePersonasMetrics updates persona-related metrics
 func UpdatePersonasMetrics(total int) {
 	personasTotal.Set(float64(total))
@@ -133,6 +183,18 @@ func IncrementEmergencias(tipo, zona string) {
 	emergenciasEnviadas.WithLabelValues(tipo, zona).Inc()
 }
 
+// UpdateOutboxMetrics updates the outbox lag and the number of events per state
+func UpdateOutboxMetrics(lagSeconds float64, pendientes, fallidos int) {
+	outboxLag.Set(lagSeconds)
+	outboxEventos.WithLabelValues("pendiente").Set(float64(pendientes))
+	outboxEventos.WithLabelValues("fallido").Set(float64(fallidos))
+}
+
+// IncrementOutboxProcesados counts an outbox event processed by the worker
+func IncrementOutboxProcesados(resultado string) {
+	outboxAplicados.WithLabelValues(resultado).Inc()
+}
+
 // UpdateDatabaseConnections updates database connection metrics
 func UpdateDatabaseConnections(dbType string, count int) {
 	databaseConnections.WithLabelValues(dbType).Set(float64(count))
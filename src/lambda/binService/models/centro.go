@@ -0,0 +1,13 @@
+package models
+
+// Centro es un centro de descarga. La tabla ya existe en la base: no se migra.
+type Centro struct {
+	IDCentro int64  `gorm:"column:id_centro;primaryKey"`
+	IDTipo   int64  `gorm:"column:id_tipo"`
+	IDNeo    string `gorm:"column:id_neo"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (Centro) TableName() string {
+	return "Centro"
+}
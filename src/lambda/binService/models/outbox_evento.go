@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// OutboxEvento es un cambio hecho en MySQL que falta aplicar en Neo4j. Se
+// inserta en la misma transacción que la escritura, así no se pierde aunque
+// Neo4j no esté disponible. Clave identifica el recurso (id_tacho para los
+// tachos) y ordena sus eventos; Payload lleva los datos en JSON.
+type OutboxEvento struct {
+	IDEvento       int64      `gorm:"column:id_evento;primaryKey;autoIncrement"`
+	Entidad        string     `gorm:"column:entidad;size:20;index:idx_outbox_clave"`
+	Clave          string     `gorm:"column:clave;size:255;index:idx_outbox_clave"`
+	Operacion      string     `gorm:"column:operacion;size:20"`
+	Payload        string     `gorm:"column:payload;type:text"`
+	Estado         string     `gorm:"column:estado;size:20;index"`
+	Intentos       int        `gorm:"column:intentos"`
+	UltimoError    string     `gorm:"column:ultimo_error;type:text"`
+	ProximoIntento time.Time  `gorm:"column:proximo_intento"`
+	CreadoEn       time.Time  `gorm:"column:creado_en;autoCreateTime;index"`
+	AplicadoEn     *time.Time `gorm:"column:aplicado_en"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (OutboxEvento) TableName() string {
+	return "Outbox_evento"
+}
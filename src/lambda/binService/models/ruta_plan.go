@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// RutaPlan es una ruta calculada y asignada, con su estado de ejecución
+type RutaPlan struct {
+	IDRuta            int64      `gorm:"column:id_ruta;primaryKey;autoIncrement"`
+	IDZona            int64      `gorm:"column:id_zona;index"`
+	IDCamion          int64      `gorm:"column:id_camion;index"`
+	IDPersona         string     `gorm:"column:id_persona;size:50;index"`
+	Algoritmo         string     `gorm:"column:algoritmo;size:20"`
+	Estado            string     `gorm:"column:estado;size:20;index"`
+	DistanciaTotal    float64    `gorm:"column:distancia_total_km"`
+	TipoDistancia     string     `gorm:"column:tipo_distancia;size:10"`
+	CapacidadLitros   float64    `gorm:"column:capacidad_camion_litros"`
+	Descargas         int        `gorm:"column:descargas"`
+	Omitidos          int        `gorm:"column:tachos_omitidos"`
+	Polilinea         string     `gorm:"column:polilinea;type:longtext"`
+	MotivoCancelacion string     `gorm:"column:motivo_cancelacion;size:255"`
+	CreadaEn          time.Time  `gorm:"column:creada_en;autoCreateTime;index"`
+	ActualizadaEn     time.Time  `gorm:"column:actualizada_en;autoUpdateTime"`
+	IniciadaEn        *time.Time `gorm:"column:iniciada_en"`
+	FinalizadaEn      *time.Time `gorm:"column:finalizada_en"`
+	ETA               *time.Time `gorm:"column:eta"`
+	Revision          int        `gorm:"column:revision"`
+	IDCalendario      *int64     `gorm:"column:id_calendario;uniqueIndex:idx_ruta_corrida"`
+	ProgramadaPara    *time.Time `gorm:"column:programada_para;uniqueIndex:idx_ruta_corrida"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (RutaPlan) TableName() string {
+	return "Ruta_plan"
+}
+
+// RutaParada es una parada de una ruta planificada, en orden de visita
+type RutaParada struct {
+	IDRuta             int64   `gorm:"column:id_ruta;primaryKey;autoIncrement:false"`
+	Secuencia          int     `gorm:"column:secuencia;primaryKey;autoIncrement:false"`
+	Tipo               string  `gorm:"column:tipo;size:20"`
+	IDPunto            int     `gorm:"column:id_punto"`
+	IDTacho            int     `gorm:"column:id_tacho;index"`
+	IDNeo              string  `gorm:"column:id_neo;size:255"`
+	Nombre             string  `gorm:"column:nombre;size:255"`
+	Direccion          string  `gorm:"column:direccion;size:255"`
+	Lat                float64 `gorm:"column:lat"`
+	Lng                float64 `gorm:"column:lng"`
+	Capacidad          float64 `gorm:"column:capacidad"`
+	Prioridad          int     `gorm:"column:prioridad"`
+	Urgencia           float64 `gorm:"column:urgencia"`
+	Volumen            float64 `gorm:"column:volumen_litros"`
+	Carga              float64 `gorm:"column:carga_litros"`
+	DistanciaTramo     float64 `gorm:"column:distancia_tramo_km"`
+	DistanciaAcumulada float64 `gorm:"column:distancia_acumulada_km"`
+	// Ejecución: solo las paradas de tachos se marcan; el resto queda sin estado
+	Estado     string     `gorm:"column:estado;size:20"`
+	Motivo     string     `gorm:"column:motivo;size:255"`
+	AtendidaEn *time.Time `gorm:"column:atendida_en"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (RutaParada) TableName() string {
+	return "Ruta_parada"
+}
+
+// RutaRevision guarda cada versión del recorrido de una ruta: la 1 es el plan
+// original y cada reoptimización agrega una nueva con sus paradas completas
+type RutaRevision struct {
+	IDRuta         int64     `gorm:"column:id_ruta;primaryKey;autoIncrement:false"`
+	Revision       int       `gorm:"column:revision;primaryKey;autoIncrement:false"`
+	Motivo         string    `gorm:"column:motivo;size:255"`
+	Lat            *float64  `gorm:"column:lat"`
+	Lng            *float64  `gorm:"column:lng"`
+	Urgentes       string    `gorm:"column:urgentes;type:text"`
+	Quitados       string    `gorm:"column:quitados;type:text"`
+	DistanciaTotal float64   `gorm:"column:distancia_total_km"`
+	Paradas        string    `gorm:"column:paradas;type:longtext"`
+	Polilinea      string    `gorm:"column:polilinea;type:longtext"`
+	CreadaEn       time.Time `gorm:"column:creada_en;autoCreateTime"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (RutaRevision) TableName() string {
+	return "Ruta_revision"
+}
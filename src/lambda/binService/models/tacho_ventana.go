@@ -0,0 +1,15 @@
+package models
+
+// TachoVentana es un horario en el que se puede vaciar un tacho (HH:MM a HH:MM).
+// Un tacho sin ventanas se puede vaciar a cualquier hora.
+type TachoVentana struct {
+	IDVentana int64  `gorm:"column:id_ventana;primaryKey;autoIncrement"`
+	IdNeo     string `gorm:"column:id_neo;size:255;index"`
+	Desde     string `gorm:"column:desde;size:5"`
+	Hasta     string `gorm:"column:hasta;size:5"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (TachoVentana) TableName() string {
+	return "Tacho_ventana"
+}
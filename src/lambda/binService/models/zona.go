@@ -0,0 +1,45 @@
+package models
+
+// ZonaBarrio asocia una zona con uno de los barrios que cubre
+type ZonaBarrio struct {
+	IDZona int64  `gorm:"column:id_zona;primaryKey;autoIncrement:false"`
+	Barrio string `gorm:"column:barrio;primaryKey;size:100"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (ZonaBarrio) TableName() string {
+	return "Zona_barrio"
+}
+
+// ZonaLimite guarda el polígono límite de una zona como GeoJSON
+type ZonaLimite struct {
+	IDZona  int64  `gorm:"column:id_zona;primaryKey;autoIncrement:false"`
+	GeoJSON string `gorm:"column:geojson;type:longtext"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (ZonaLimite) TableName() string {
+	return "Zona_limite"
+}
+
+// ZonaCamion asigna un camión a una zona
+type ZonaCamion struct {
+	IDZona   int64 `gorm:"column:id_zona;primaryKey;autoIncrement:false"`
+	IDCamion int64 `gorm:"column:id_camion;primaryKey;autoIncrement:false"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (ZonaCamion) TableName() string {
+	return "Zona_camion"
+}
+
+// TachoZona guarda la zona asignada a un tacho por su ubicación
+type TachoZona struct {
+	IdNeo  string `gorm:"column:id_neo;primaryKey;size:255"`
+	IDZona int64  `gorm:"column:id_zona;index"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (TachoZona) TableName() string {
+	return "Tacho_zona"
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ZonaCalendario es una regla de recolección recurrente de una zona: los días
+// de la semana (lun,mie,vie) y la hora (HH:MM) en que sale el camión
+type ZonaCalendario struct {
+	IDCalendario int64     `gorm:"column:id_calendario;primaryKey;autoIncrement"`
+	IDZona       int64     `gorm:"column:id_zona;index"`
+	Dias         string    `gorm:"column:dias;size:30"`
+	Hora         string    `gorm:"column:hora;size:5"`
+	IDCamion     int64     `gorm:"column:id_camion"`
+	IDPersona    string    `gorm:"column:id_persona;size:50"`
+	Algoritmo    string    `gorm:"column:algoritmo;size:20"`
+	Activo       bool      `gorm:"column:activo"`
+	CreadoEn     time.Time `gorm:"column:creado_en;autoCreateTime"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (ZonaCalendario) TableName() string {
+	return "Zona_calendario"
+}
+
+// Feriado es un día sin recolección. Con id_zona 0 aplica a todas las zonas.
+type Feriado struct {
+	Fecha       time.Time `gorm:"column:fecha;type:date;primaryKey"`
+	IDZona      int64     `gorm:"column:id_zona;primaryKey;autoIncrement:false"`
+	Descripcion string    `gorm:"column:descripcion;size:255"`
+}
+
+// TableName - nombre exacto de la tabla en MySQL
+func (Feriado) TableName() string {
+	return "Feriado"
+}
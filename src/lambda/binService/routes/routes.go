@@ -13,19 +13,42 @@ func SetupRoutes(r *gin.Engine) {
 	// API endpoints existentes
 	r.GET("/ruta-optima", handlers.GetRutaHandlerByHeader)
 	r.GET("/ruta-optima/:zonaID", handlers.GetRutaHandler)
+	r.GET("/ruta-optima/:zonaID/camiones", handlers.GetRutasFlotaHandler) // Una ruta por camión operativo
+	r.GET("/ruta-optima/:zonaID/agenda", handlers.GetAgendaZonaHandler)   // Horario por parada con ventanas y turno
 	r.POST("/enviar-emergencia", handlers.SendEmergencyHandler)
 
+	// Rutas planificadas y su historial
+	r.GET("/rutas", handlers.GetPlanesRutaHandler)
+	r.POST("/rutas", handlers.CreatePlanRutaHandler)
+	r.GET("/rutas/:id", handlers.GetPlanRutaHandler)
+	r.POST("/rutas/:id/cancelar", handlers.CancelarPlanRutaHandler)
+	r.POST("/rutas/:id/paradas/:secuencia/recolectar", handlers.RecolectarParadaHandler)
+	r.POST("/rutas/:id/paradas/:secuencia/omitir", handlers.OmitirParadaHandler)
+	r.POST("/rutas/:id/paradas/:secuencia/bloquear", handlers.BloquearParadaHandler)
+	r.POST("/rutas/:id/reoptimizar", handlers.ReoptimizarPlanRutaHandler)
+	r.GET("/rutas/:id/revisiones", handlers.GetRevisionesPlanRutaHandler)
+
+	// Red de calles para distancias reales
+	r.GET("/red-vial", handlers.GetRedVialHandler)
+	r.POST("/red-vial/importar", handlers.ImportarRedVialHandler)
+
 	// Nuevos endpoints para personas (Redis)
 	r.GET("/personas", handlers.GetAllPersonas)
 	r.GET("/personas/:id", handlers.GetPersonaByID)
 	r.GET("/personas/zona/:zona", handlers.GetPersonasByZona)
+	r.PUT("/personas/:id/turno", handlers.UpdateTurnoPersonaHandler)
 
 	// Endpoints para tachos
 	r.GET("/tachos", handlers.GetAllTachosHandler) // Obtener todos los tachos
 	r.POST("/tachos", handlers.CreateTachoHandler)
 	r.DELETE("/tachos", handlers.DeleteTachoHandler) // Cambiado para usar query parameters
+	r.GET("/tachos/:id_tacho", handlers.GetTachoHandler)
+	r.PATCH("/tachos/:id_tacho", handlers.UpdateTachoHandler)
+	r.POST("/tachos/:id_tacho/reubicar", handlers.ReubicarTachoHandler)
 	r.PUT("/tachos/:id_tacho/capacidad", handlers.UpdateCapacidadTachoHandler)
 	r.PUT("/tachos/:id_tacho/prioridad", handlers.UpdatePrioridadTachoHandler)
+	r.GET("/tachos/:id_tacho/ventanas", handlers.GetVentanasTachoHandler)
+	r.PUT("/tachos/:id_tacho/ventanas", handlers.UpdateVentanasTachoHandler)
 
 	// Endpoints para camiones
 	r.GET("/camiones", handlers.GetAllCamionesHandler)    // Obtener todos los camiones con JOIN
@@ -34,4 +57,31 @@ func SetupRoutes(r *gin.Engine) {
 	// Endpoints para centros
 	r.GET("/centros", handlers.GetAllCentrosHandler)     // Obtener todos los centros con JOIN MySQL + Neo4j
 	r.GET("/centros/:id", handlers.GetCentroByIDHandler) // Obtener centro por ID con JOIN MySQL + Neo4j
+
+	// Endpoints para zonas
+	r.GET("/zonas", handlers.GetAllZonasHandler)
+	r.POST("/zonas", handlers.CreateZonaHandler)
+	r.POST("/zonas/limites", handlers.ImportarLimitesZonasHandler)  // Importar límites GeoJSON
+	r.GET("/zonas/asignacion", handlers.GetAsignacionTachosHandler) // Tachos sin zona o en más de una
+	r.POST("/zonas/asignacion", handlers.AsignarTachosHandler)      // Reasignar tachos por ubicación
+	r.GET("/zonas/:id", handlers.GetZonaByIDHandler)
+	r.PUT("/zonas/:id", handlers.UpdateZonaHandler)
+	r.DELETE("/zonas/:id", handlers.DeleteZonaHandler)
+	r.GET("/zonas/:id/resumen", handlers.GetZonaResumenHandler)                    // Tachos, llenado promedio y personas
+	r.GET("/zonas/:id/matriz-distancias", handlers.GetMatrizDistanciasZonaHandler) // Distancias entre tachos (depuración)
+	r.GET("/zonas/:id/calendario", handlers.GetCalendarioZonaHandler)              // Próximas corridas de recolección
+	r.PUT("/zonas/:id/calendario", handlers.UpdateCalendarioZonaHandler)
+	r.POST("/zonas/:id/calendario/generar", handlers.GenerarCalendarioZonaHandler) // Generar rutas programadas
+
+	// Días sin recolección
+	r.GET("/feriados", handlers.GetFeriadosHandler)
+	r.POST("/feriados", handlers.CreateFeriadoHandler)
+	r.DELETE("/feriados/:fecha", handlers.DeleteFeriadoHandler)
+
+	// Administración
+	r.GET("/admin/outbox", handlers.GetOutboxHandler) // Sincronización MySQL -> Neo4j pendiente y fallida
+	r.POST("/admin/outbox/:id/reintentar", handlers.ReintentarEventoOutboxHandler)
+	r.POST("/admin/outbox/:id/descartar", handlers.DescartarEventoOutboxHandler)
+	r.GET("/admin/reconciliacion", handlers.GetReconciliacionHandler) // Diferencias entre MySQL y Neo4j
+	r.POST("/admin/reconciliacion", handlers.ReconciliarHandler)
 }
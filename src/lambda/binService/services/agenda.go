@@ -0,0 +1,556 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+)
+
+// Motivos por los que una parada no entra en la agenda
+const (
+	MotivoFueraDeVentana = "fuera_de_ventana"
+	MotivoFueraDeTurno   = "fuera_de_turno"
+)
+
+// Ventana es un horario (HH:MM) del día
+type Ventana struct {
+	Desde string `json:"desde" example:"08:00"`
+	Hasta string `json:"hasta" example:"12:00"`
+}
+
+// VentanasRequest reemplaza las ventanas horarias de un tacho
+type VentanasRequest struct {
+	Ventanas []Ventana `json:"ventanas"`
+}
+
+// TurnoRequest fija el turno de trabajo de una persona
+type TurnoRequest struct {
+	Inicio string `json:"inicio" example:"06:00"`
+	Fin    string `json:"fin" example:"14:00"`
+}
+
+// ParadaAgenda es una parada con su horario estimado
+type ParadaAgenda struct {
+	Point
+	Llegada   time.Time `json:"llegada"`
+	EsperaMin float64   `json:"espera_min,omitempty"`
+	Inicio    time.Time `json:"inicio"`
+	Fin       time.Time `json:"fin"`
+	Ventanas  []Ventana `json:"ventanas,omitempty"`
+}
+
+// ParadaNoEntra es un tacho que no se puede visitar respetando ventanas y turno
+type ParadaNoEntra struct {
+	Point
+	Motivo   string    `json:"motivo"`
+	Ventanas []Ventana `json:"ventanas,omitempty"`
+}
+
+// Agenda es el recorrido de una zona con horarios por parada
+type Agenda struct {
+	IDZona         int             `json:"id_zona"`
+	IDPersona      string          `json:"id_persona,omitempty"`
+	TurnoInicio    time.Time       `json:"turno_inicio"`
+	TurnoFin       time.Time       `json:"turno_fin"`
+	Paradas        []ParadaAgenda  `json:"paradas"`
+	NoEntran       []ParadaNoEntra `json:"no_entran"`
+	DistanciaTotal float64         `json:"distancia_total_km"`
+	TipoDistancia  string          `json:"tipo_distancia"`
+	Fin            time.Time       `json:"fin"`
+	Polilinea      [][2]float64    `json:"polilinea,omitempty"`
+}
+
+// intervalo es una ventana en minutos desde la medianoche
+type intervalo struct {
+	desde, hasta float64
+}
+
+// parseHora convierte HH:MM en minutos desde la medianoche; acepta 24:00
+func parseHora(hora string) (float64, error) {
+	partes := strings.Split(strings.TrimSpace(hora), ":")
+	if len(partes) != 2 || len(partes[0]) != 2 || len(partes[1]) != 2 {
+		return 0, fmt.Errorf("hora inválida: %q (formato HH:MM)", hora)
+	}
+	h, errH := strconv.Atoi(partes[0])
+	m, errM := strconv.Atoi(partes[1])
+	if errH != nil || errM != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("hora inválida: %q (formato HH:MM)", hora)
+	}
+	return float64(h*60 + m), nil
+}
+
+// ValidarVentanas verifica formato y orden de las ventanas. No se admiten
+// ventanas que crucen la medianoche: se cargan como dos ventanas.
+func ValidarVentanas(ventanas []Ventana) error {
+	for _, v := range ventanas {
+		desde, err := parseHora(v.Desde)
+		if err != nil {
+			return err
+		}
+		hasta, err := parseHora(v.Hasta)
+		if err != nil {
+			return err
+		}
+		if desde >= hasta {
+			return fmt.Errorf("hora inválida: la ventana %s-%s debe terminar después de empezar", v.Desde, v.Hasta)
+		}
+	}
+	return nil
+}
+
+// intervalos convierte ventanas ya validadas en minutos, ordenadas
+func intervalos(ventanas []Ventana) []intervalo {
+	resultado := make([]intervalo, 0, len(ventanas))
+	for _, v := range ventanas {
+		desde, _ := parseHora(v.Desde)
+		hasta, _ := parseHora(v.Hasta)
+		resultado = append(resultado, intervalo{desde, hasta})
+	}
+	sort.Slice(resultado, func(i, j int) bool { return resultado[i].desde < resultado[j].desde })
+	return resultado
+}
+
+// inicioEnVentana devuelve el primer minuto desde llegada en el que se puede
+// empezar a vaciar el tacho, o false si ya no queda ventana ese día
+func inicioEnVentana(ventanas []intervalo, llegada float64) (float64, bool) {
+	if len(ventanas) == 0 {
+		return llegada, true
+	}
+	for _, v := range ventanas {
+		if llegada <= v.hasta {
+			return math.Max(llegada, v.desde), true
+		}
+	}
+	return 0, false
+}
+
+// minutosViaje estima el tiempo de manejo para una distancia
+func minutosViaje(km float64) float64 {
+	return km / velocidadPromedioKmh * 60
+}
+
+// planificarAgenda elige el orden de visita respetando ventanas y turno. Los
+// nodos son [origen] + tachos + [destino] según conOrigen y conDestino, y los
+// tiempos se expresan en minutos desde la medianoche. En cada paso se visita
+// el tacho que se puede empezar antes (a igual hora, el más cercano); un tacho
+// cuya ventana ya cerró o que no permite terminar el turno queda afuera.
+// Devuelve el orden de los nodos visitados con la llegada y el inicio de cada
+// uno, y el motivo de cada tacho que no entra.
+func planificarAgenda(dist [][]float64, ventanas [][]intervalo, conOrigen, conDestino bool, turnoInicio, turnoFin float64) (orden []int, llegadas, inicios []float64, noEntran map[int]string) {
+	n := len(dist)
+	primero, ultimo := 0, n
+	if conOrigen {
+		primero = 1
+	}
+	if conDestino {
+		ultimo = n - 1
+	}
+
+	noEntran = make(map[int]string)
+	pendientes := make(map[int]bool)
+	for i := primero; i < ultimo; i++ {
+		pendientes[i] = true
+	}
+
+	actual := -1
+	reloj := turnoInicio
+	if conOrigen {
+		actual = 0
+		orden = append(orden, 0)
+		llegadas = append(llegadas, reloj)
+		inicios = append(inicios, reloj)
+	}
+
+	viaje := func(desde, hasta int) float64 {
+		if desde < 0 {
+			return 0
+		}
+		return minutosViaje(dist[desde][hasta])
+	}
+
+	for len(pendientes) > 0 {
+		mejor, mejorInicio, mejorLlegada, mejorViaje := -1, math.Inf(1), 0.0, 0.0
+		for j := primero; j < ultimo; j++ {
+			if !pendientes[j] {
+				continue
+			}
+			minutos := viaje(actual, j)
+			llegada := reloj + minutos
+			inicio, ok := inicioEnVentana(ventanas[j], llegada)
+			if !ok {
+				// El reloj solo avanza: la ventana no vuelve a abrir
+				noEntran[j] = MotivoFueraDeVentana
+				delete(pendientes, j)
+				continue
+			}
+			fin := inicio + minutosPorParada
+			if conDestino {
+				fin += viaje(j, n-1)
+			}
+			if fin > turnoFin {
+				noEntran[j] = MotivoFueraDeTurno
+				delete(pendientes, j)
+				continue
+			}
+			if inicio < mejorInicio-epsilon || (math.Abs(inicio-mejorInicio) <= epsilon && minutos < mejorViaje) {
+				mejor, mejorInicio, mejorLlegada, mejorViaje = j, inicio, llegada, minutos
+			}
+		}
+		if mejor < 0 {
+			break
+		}
+
+		orden = append(orden, mejor)
+		llegadas = append(llegadas, mejorLlegada)
+		inicios = append(inicios, mejorInicio)
+		delete(pendientes, mejor)
+		actual = mejor
+		reloj = mejorInicio + minutosPorParada
+	}
+
+	if conDestino {
+		llegada := reloj + viaje(actual, n-1)
+		orden = append(orden, n-1)
+		llegadas = append(llegadas, llegada)
+		inicios = append(inicios, llegada)
+	}
+	return orden, llegadas, inicios, noEntran
+}
+
+// horarioRuta es el horario de un plan guardado en minutos desde la medianoche
+// del día del recorrido: desde cuándo se puede empezar y cuándo termina el turno
+type horarioRuta struct {
+	inicio, fin float64
+}
+
+// nuevoHorarioRuta arma el horario de una ruta que sale en salida. Con persona
+// el recorrido no empieza antes de su turno ni termina después; sin persona
+// tiene hasta el final del día.
+func nuevoHorarioRuta(salida time.Time, persona map[string]interface{}) *horarioRuta {
+	salida = salida.In(config.ZonaHoraria())
+	dia := time.Date(salida.Year(), salida.Month(), salida.Day(), 0, 0, 0, 0, salida.Location())
+	inicio, fin := 0.0, 24*60.0
+	if persona != nil {
+		inicio, fin = turnoPersona(persona)
+	}
+	return &horarioRuta{inicio: math.Max(inicio, salida.Sub(dia).Minutes()), fin: fin}
+}
+
+// descartarFueraDeHorario recorre la ruta en su orden y deja afuera los tachos
+// que no se pueden vaciar dentro de sus ventanas o que no permiten terminar el
+// turno (llegando al destino si hay uno). A diferencia de planificarAgenda no
+// cambia el orden. Devuelve los índices de los puntos que quedan y el motivo
+// de cada tacho descartado.
+func descartarFueraDeHorario(puntos []Point, dist [][]float64, ventanas [][]intervalo, horario horarioRuta) (quedan []int, noEntran map[int]string) {
+	destino := -1
+	if n := len(puntos); n > 0 && puntos[n-1].Tipo == TipoPuntoDestino {
+		destino = n - 1
+	}
+	viaje := func(desde, hasta int) float64 {
+		if desde < 0 {
+			return 0
+		}
+		return minutosViaje(dist[desde][hasta])
+	}
+
+	noEntran = make(map[int]string)
+	anterior := -1
+	reloj := horario.inicio
+	for i, p := range puntos {
+		if p.Tipo != TipoPuntoTacho {
+			reloj += viaje(anterior, i)
+			quedan = append(quedan, i)
+			anterior = i
+			continue
+		}
+
+		inicio, ok := inicioEnVentana(ventanas[i], reloj+viaje(anterior, i))
+		if !ok {
+			noEntran[i] = MotivoFueraDeVentana
+			continue
+		}
+		fin := inicio + minutosPorParada
+		if destino >= 0 {
+			fin += viaje(i, destino)
+		}
+		if fin > horario.fin {
+			noEntran[i] = MotivoFueraDeTurno
+			continue
+		}
+
+		quedan = append(quedan, i)
+		anterior = i
+		reloj = inicio + minutosPorParada
+	}
+	return quedan, noEntran
+}
+
+// ajustarAHorario saca de la ruta ordenada los tachos que no entran en sus
+// ventanas o en el turno y los suma a los omitidos. Se aplica antes del
+// control de capacidad, así que no cuenta los viajes de descarga.
+func ajustarAHorario(ruta *Ruta, horario horarioRuta, base *MatrizZona) error {
+	customIDs := make([]string, 0, len(ruta.Puntos))
+	for _, p := range ruta.Puntos {
+		if p.Tipo == TipoPuntoTacho {
+			customIDs = append(customIDs, p.CustomID)
+		}
+	}
+	ventanasTacho, err := getVentanasPorTacho(customIDs)
+	if err != nil {
+		return err
+	}
+
+	ventanas := make([][]intervalo, len(ruta.Puntos))
+	for i, p := range ruta.Puntos {
+		if p.Tipo == TipoPuntoTacho {
+			ventanas[i] = intervalos(ventanasTacho[p.CustomID])
+		}
+	}
+
+	quedan, noEntran := descartarFueraDeHorario(ruta.Puntos, matrizDistanciasCon(ruta.Puntos, base), ventanas, horario)
+	if len(noEntran) == 0 {
+		return nil
+	}
+	puntos := make([]Point, len(quedan))
+	for k, i := range quedan {
+		puntos[k] = ruta.Puntos[i]
+	}
+	ruta.Puntos = puntos
+	ruta.Omitidos += len(noEntran)
+	return nil
+}
+
+// getVentanasPorTacho obtiene las ventanas horarias de los tachos indicados
+func getVentanasPorTacho(customIDs []string) (map[string][]Ventana, error) {
+	ventanas := make(map[string][]Ventana)
+	if len(customIDs) == 0 {
+		return ventanas, nil
+	}
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var filas []models.TachoVentana
+	if err := config.DB.Where("id_neo IN ?", customIDs).Order("desde").Find(&filas).Error; err != nil {
+		return nil, fmt.Errorf("error querying ventanas: %v", err)
+	}
+	for _, fila := range filas {
+		ventanas[fila.IdNeo] = append(ventanas[fila.IdNeo], Ventana{Desde: fila.Desde, Hasta: fila.Hasta})
+	}
+	return ventanas, nil
+}
+
+// getTachoIDNeo obtiene el id_neo de un tacho por su ID de MySQL
+func getTachoIDNeo(tachoID int) (string, error) {
+	if config.DB == nil {
+		return "", fmt.Errorf("database connection not available")
+	}
+
+	var tacho models.Tacho
+	result := config.DB.Where("id_tacho = ?", tachoID).Limit(1).Find(&tacho)
+	if result.Error != nil {
+		return "", fmt.Errorf("error querying tacho: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", fmt.Errorf("tacho with ID %d not found", tachoID)
+	}
+	return tacho.IDNeo, nil
+}
+
+// GetVentanasTacho devuelve las ventanas horarias de un tacho
+func GetVentanasTacho(tachoID int) ([]Ventana, error) {
+	customID, err := getTachoIDNeo(tachoID)
+	if err != nil {
+		return nil, err
+	}
+
+	ventanas, err := getVentanasPorTacho([]string{customID})
+	if err != nil {
+		return nil, err
+	}
+	if ventanas[customID] == nil {
+		return []Ventana{}, nil
+	}
+	return ventanas[customID], nil
+}
+
+// SetVentanasTacho reemplaza las ventanas horarias de un tacho; una lista
+// vacía deja el tacho sin restricción horaria
+func SetVentanasTacho(tachoID int, ventanas []Ventana) ([]Ventana, error) {
+	if err := ValidarVentanas(ventanas); err != nil {
+		return nil, err
+	}
+	customID, err := getTachoIDNeo(tachoID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id_neo = ?", customID).Delete(&models.TachoVentana{}).Error; err != nil {
+			return fmt.Errorf("error deleting ventanas: %v", err)
+		}
+		for _, v := range ventanas {
+			fila := models.TachoVentana{IdNeo: customID, Desde: strings.TrimSpace(v.Desde), Hasta: strings.TrimSpace(v.Hasta)}
+			if err := tx.Create(&fila).Error; err != nil {
+				return fmt.Errorf("error inserting ventana: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetVentanasTacho(tachoID)
+}
+
+// borrarVentanasTacho quita las ventanas de un tacho eliminado
+func borrarVentanasTacho(customID string) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	return config.DB.Where("id_neo = ?", customID).Delete(&models.TachoVentana{}).Error
+}
+
+// SetTurnoPersona guarda el turno de una persona en su hash de Redis
+func SetTurnoPersona(personaID string, turno TurnoRequest) error {
+	inicio, err := parseHora(turno.Inicio)
+	if err != nil {
+		return err
+	}
+	fin, err := parseHora(turno.Fin)
+	if err != nil {
+		return err
+	}
+	if inicio >= fin {
+		return fmt.Errorf("hora inválida: el turno %s-%s debe terminar después de empezar", turno.Inicio, turno.Fin)
+	}
+
+	if _, err := GetPersonaByKey("persona:" + personaID); err != nil {
+		return fmt.Errorf("persona with ID %s not found", personaID)
+	}
+
+	return config.RedisClient.HSet(context.Background(), "persona:"+personaID, map[string]interface{}{
+		"turno_inicio": strings.TrimSpace(turno.Inicio),
+		"turno_fin":    strings.TrimSpace(turno.Fin),
+	}).Err()
+}
+
+// turnoPersona devuelve el turno de la persona en minutos; sin turno cargado
+// se usa el día completo
+func turnoPersona(persona map[string]interface{}) (float64, float64) {
+	inicio, errInicio := parseHora(fmt.Sprint(persona["turno_inicio"]))
+	fin, errFin := parseHora(fmt.Sprint(persona["turno_fin"]))
+	if errInicio != nil || errFin != nil || inicio >= fin {
+		return 0, 24 * 60
+	}
+	return inicio, fin
+}
+
+// GetAgendaZona arma el recorrido de una zona con horario por parada para el
+// día indicado. Respeta las ventanas horarias de los tachos y el turno de la
+// persona (o el día completo si no se indica persona). Si la fecha es hoy el
+// recorrido arranca ahora cuando el turno ya empezó.
+func GetAgendaZona(zonaID int, opciones RutaOpciones, personaID string, fecha time.Time) (*Agenda, error) {
+	turnoInicio, turnoFin := 0.0, 24*60.0
+	if personaID != "" {
+		persona, err := GetPersonaByKey("persona:" + personaID)
+		if err != nil {
+			return nil, fmt.Errorf("persona with ID %s not found", personaID)
+		}
+		turnoInicio, turnoFin = turnoPersona(persona)
+	}
+
+	fecha = fecha.In(config.ZonaHoraria())
+	dia := time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, fecha.Location())
+	enMinutos := func(t time.Time) float64 { return t.Sub(dia).Minutes() }
+	aHora := func(minutos float64) time.Time {
+		return dia.Add(time.Duration(minutos * float64(time.Minute))).Truncate(time.Second)
+	}
+	if ahora := enMinutos(time.Now()); ahora > turnoInicio && ahora < 24*60 {
+		turnoInicio = math.Min(ahora, turnoFin)
+	}
+
+	points, err := getTachoPointsByZona(zonaID)
+	if err != nil {
+		return nil, err
+	}
+	base := matrizParaRuta(zonaID, points)
+	points, _ = SeleccionarParadas(points, opciones.MinCapacidad)
+
+	customIDs := make([]string, 0, len(points))
+	for _, p := range points {
+		customIDs = append(customIDs, p.CustomID)
+	}
+	ventanasTacho, err := getVentanasPorTacho(customIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	nodos := make([]Point, 0, len(points)+2)
+	if opciones.Origen != nil {
+		nodos = append(nodos, *opciones.Origen)
+	}
+	nodos = append(nodos, points...)
+	if opciones.Destino != nil {
+		nodos = append(nodos, *opciones.Destino)
+	}
+	ventanas := make([][]intervalo, len(nodos))
+	for i, p := range nodos {
+		if p.Tipo == TipoPuntoTacho {
+			ventanas[i] = intervalos(ventanasTacho[p.CustomID])
+		}
+	}
+
+	dist := matrizDistanciasCon(nodos, base)
+	orden, llegadas, inicios, noEntran := planificarAgenda(dist, ventanas, opciones.Origen != nil, opciones.Destino != nil, turnoInicio, turnoFin)
+
+	ruta := &Ruta{Puntos: make([]Point, len(orden))}
+	for k, i := range orden {
+		ruta.Puntos[k] = nodos[i]
+	}
+	completarRecorrido(ruta)
+
+	agenda := &Agenda{
+		IDZona:         zonaID,
+		IDPersona:      personaID,
+		TurnoInicio:    aHora(turnoInicio),
+		TurnoFin:       aHora(turnoFin),
+		Paradas:        make([]ParadaAgenda, len(orden)),
+		NoEntran:       []ParadaNoEntra{},
+		DistanciaTotal: ruta.DistanciaTotal,
+		TipoDistancia:  ruta.TipoDistancia,
+		Fin:            aHora(turnoInicio),
+		Polilinea:      ruta.Polilinea,
+	}
+	for k, p := range ruta.Puntos {
+		fin := inicios[k]
+		if p.Tipo == TipoPuntoTacho {
+			fin += minutosPorParada
+		}
+		agenda.Paradas[k] = ParadaAgenda{
+			Point:     p,
+			Llegada:   aHora(llegadas[k]),
+			EsperaMin: math.Round(inicios[k] - llegadas[k]),
+			Inicio:    aHora(inicios[k]),
+			Fin:       aHora(fin),
+			Ventanas:  ventanasTacho[p.CustomID],
+		}
+		agenda.Fin = aHora(fin)
+	}
+	for i, p := range nodos {
+		if motivo, ok := noEntran[i]; ok {
+			agenda.NoEntran = append(agenda.NoEntran, ParadaNoEntra{Point: p, Motivo: motivo, Ventanas: ventanasTacho[p.CustomID]})
+		}
+	}
+
+	return agenda, nil
+}
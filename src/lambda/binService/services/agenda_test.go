@@ -0,0 +1,131 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHora(t *testing.T) {
+	minutos, err := parseHora("08:30")
+	assert.NoError(t, err)
+	assert.Equal(t, 510.0, minutos)
+
+	minutos, err = parseHora("24:00")
+	assert.NoError(t, err)
+	assert.Equal(t, 1440.0, minutos)
+
+	for _, hora := range []string{"8:30", "25:00", "24:01", "08:60", "ocho"} {
+		_, err := parseHora(hora)
+		assert.Error(t, err, hora)
+	}
+}
+
+func TestValidarVentanas(t *testing.T) {
+	assert.NoError(t, ValidarVentanas([]Ventana{{Desde: "06:00", Hasta: "09:00"}, {Desde: "20:00", Hasta: "24:00"}}))
+	assert.Error(t, ValidarVentanas([]Ventana{{Desde: "22:00", Hasta: "02:00"}}), "no cruza la medianoche")
+	assert.Error(t, ValidarVentanas([]Ventana{{Desde: "09:00", Hasta: "09:00"}}))
+}
+
+func TestInicioEnVentana(t *testing.T) {
+	ventanas := []intervalo{{desde: 480, hasta: 540}, {desde: 900, hasta: 960}}
+
+	inicio, ok := inicioEnVentana(ventanas, 500)
+	assert.True(t, ok)
+	assert.Equal(t, 500.0, inicio, "dentro de la ventana no espera")
+
+	inicio, ok = inicioEnVentana(ventanas, 600)
+	assert.True(t, ok)
+	assert.Equal(t, 900.0, inicio, "espera a la siguiente ventana")
+
+	_, ok = inicioEnVentana(ventanas, 961)
+	assert.False(t, ok)
+
+	inicio, ok = inicioEnVentana(nil, 1000)
+	assert.True(t, ok)
+	assert.Equal(t, 1000.0, inicio)
+}
+
+// distanciasIguales arma una matriz con 1 km (3 minutos) entre todos los nodos
+func distanciasIguales(n int) [][]float64 {
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+		for j := range dist[i] {
+			if i != j {
+				dist[i][j] = 1
+			}
+		}
+	}
+	return dist
+}
+
+func TestPlanificarAgenda_Ventanas(t *testing.T) {
+	// Nodo 0 es el origen; el 1 abre a las 09:00, el 2 no tiene ventana y el 3 cerró a las 07:30
+	ventanas := [][]intervalo{nil, {{desde: 540, hasta: 600}}, nil, {{desde: 420, hasta: 450}}}
+
+	orden, llegadas, inicios, noEntran := planificarAgenda(distanciasIguales(4), ventanas, true, false, 480, 720)
+
+	assert.Equal(t, []int{0, 2, 1}, orden)
+	assert.InDeltaSlice(t, []float64{480, 483, 488}, llegadas, 1e-9)
+	assert.InDeltaSlice(t, []float64{480, 483, 540}, inicios, 1e-9, "espera a que abra la ventana")
+	assert.Equal(t, map[int]string{3: MotivoFueraDeVentana}, noEntran)
+}
+
+func TestPlanificarAgenda_Turno(t *testing.T) {
+	ventanas := [][]intervalo{nil, {{desde: 540, hasta: 600}}, nil, nil}
+
+	// Turno hasta las 08:20 con destino: el tacho 1 abre recién a las 09:00
+	orden, llegadas, _, noEntran := planificarAgenda(distanciasIguales(4), ventanas, true, true, 480, 500)
+
+	assert.Equal(t, []int{0, 2, 3}, orden)
+	assert.InDelta(t, 488.0, llegadas[2], 1e-9, "llega al destino después de vaciar el tacho 2")
+	assert.Equal(t, map[int]string{1: MotivoFueraDeTurno}, noEntran)
+}
+
+func TestDescartarFueraDeHorario(t *testing.T) {
+	// Tramos de 5 km (10 minutos a 30 km/h) entre nodos consecutivos
+	puntos := []Point{
+		{Tipo: TipoPuntoOrigen},
+		{Tipo: TipoPuntoTacho, CustomID: "a"},
+		{Tipo: TipoPuntoTacho, CustomID: "b"},
+		{Tipo: TipoPuntoTacho, CustomID: "c"},
+		{Tipo: TipoPuntoDestino},
+	}
+	tramo := 5.0 * velocidadPromedioKmh / 30
+	dist := make([][]float64, len(puntos))
+	for i := range dist {
+		dist[i] = make([]float64, len(puntos))
+		for j := range dist[i] {
+			dist[i][j] = math.Abs(float64(i-j)) * tramo
+		}
+	}
+	// b cerró antes de que el camión llegue
+	ventanas := [][]intervalo{nil, nil, {{desde: 0, hasta: 400}}, nil, nil}
+
+	quedan, noEntran := descartarFueraDeHorario(puntos, dist, ventanas, horarioRuta{inicio: 480, fin: 24 * 60})
+	assert.Equal(t, []int{0, 1, 3, 4}, quedan)
+	assert.Equal(t, map[int]string{2: MotivoFueraDeVentana}, noEntran)
+
+	// El turno alcanza justo para vaciar a y llegar al destino
+	fin := 480 + minutosViaje(tramo) + minutosPorParada + minutosViaje(3*tramo)
+	quedan, noEntran = descartarFueraDeHorario(puntos, dist, make([][]intervalo, len(puntos)), horarioRuta{inicio: 480, fin: fin})
+	assert.Equal(t, []int{0, 1, 4}, quedan)
+	assert.Equal(t, map[int]string{2: MotivoFueraDeTurno, 3: MotivoFueraDeTurno}, noEntran)
+}
+
+func TestNuevoHorarioRuta(t *testing.T) {
+	salida := time.Date(2024, 5, 10, 5, 0, 0, 0, config.ZonaHoraria())
+
+	horario := nuevoHorarioRuta(salida, map[string]interface{}{"turno_inicio": "06:00", "turno_fin": "14:00"})
+	assert.Equal(t, horarioRuta{inicio: 360, fin: 840}, *horario, "no empieza antes del turno")
+
+	horario = nuevoHorarioRuta(salida.Add(3*time.Hour), nil)
+	assert.Equal(t, horarioRuta{inicio: 480, fin: 1440}, *horario, "sin persona tiene todo el día")
+
+	horario = nuevoHorarioRuta(salida.Add(3*time.Hour).UTC(), nil)
+	assert.Equal(t, horarioRuta{inicio: 480, fin: 1440}, *horario, "la salida se lee en la zona horaria configurada")
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+)
+
+// TachoAsignacion describe un tacho que no pudo asignarse a una única zona
+type TachoAsignacion struct {
+	ID        string  `json:"id"`
+	Barrio    string  `json:"barrio"`
+	Direccion string  `json:"direccion"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	Zonas     []int   `json:"zonas,omitempty"`
+}
+
+// AsignacionReporte resume la asignación de tachos a zonas por ubicación
+type AsignacionReporte struct {
+	TotalTachos    int               `json:"total_tachos"`
+	Asignados      int               `json:"asignados"`
+	TachosPorZona  map[int]int       `json:"tachos_por_zona"`
+	SinZona        []TachoAsignacion `json:"sin_zona"`
+	Superpuestos   []TachoAsignacion `json:"superpuestos"`
+	ZonasSinLimite []int             `json:"zonas_sin_limite"`
+}
+
+// ImportacionLimites es el resultado de importar límites de zonas desde GeoJSON
+type ImportacionLimites struct {
+	Importadas    []int              `json:"zonas_importadas"`
+	NoEncontradas []string           `json:"zonas_no_encontradas"`
+	Asignacion    *AsignacionReporte `json:"asignacion"`
+}
+
+// limiteZona es el límite ya interpretado de una zona
+type limiteZona struct {
+	IDZona    int
+	Poligonos []poligono
+}
+
+// getLimitesZonas obtiene los límites de todas las zonas y las zonas que no tienen límite
+func getLimitesZonas() ([]limiteZona, []int, error) {
+	if config.DB == nil {
+		return nil, nil, fmt.Errorf("database connection not available")
+	}
+
+	var zonaIDs []int
+	if err := config.DB.Raw("SELECT id_zona FROM Zona ORDER BY id_zona").Scan(&zonaIDs).Error; err != nil {
+		return nil, nil, fmt.Errorf("error querying zonas: %v", err)
+	}
+
+	var rows []models.ZonaLimite
+	if err := config.DB.Order("id_zona").Find(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("error querying límites de zonas: %v", err)
+	}
+
+	conLimite := make(map[int]bool)
+	limites := []limiteZona{}
+	for _, row := range rows {
+		poligonos, err := parseLimite(json.RawMessage(row.GeoJSON))
+		if err != nil {
+			log.Printf("Límite inválido en zona %d: %v", row.IDZona, err)
+			continue
+		}
+		conLimite[int(row.IDZona)] = true
+		limites = append(limites, limiteZona{IDZona: int(row.IDZona), Poligonos: poligonos})
+	}
+
+	sinLimite := []int{}
+	for _, id := range zonaIDs {
+		if !conLimite[id] {
+			sinLimite = append(sinLimite, id)
+		}
+	}
+	return limites, sinLimite, nil
+}
+
+// zonasQueContienen devuelve las zonas cuyo límite contiene la posición
+func zonasQueContienen(limites []limiteZona, lat, lng float64) []int {
+	zonas := []int{}
+	for _, limite := range limites {
+		if contienePunto(limite.Poligonos, lat, lng) {
+			zonas = append(zonas, limite.IDZona)
+		}
+	}
+	return zonas
+}
+
+// calcularAsignacion asigna cada tacho a la zona que lo contiene. Los tachos
+// fuera de toda zona o dentro de más de una quedan sin asignar y se reportan.
+func calcularAsignacion(tachos map[string]TachoNeo4j, limites []limiteZona) (map[string]int, *AsignacionReporte) {
+	ids := make([]string, 0, len(tachos))
+	for id := range tachos {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	asignacion := make(map[string]int)
+	reporte := &AsignacionReporte{
+		TotalTachos:   len(tachos),
+		TachosPorZona: make(map[int]int),
+		SinZona:       []TachoAsignacion{},
+		Superpuestos:  []TachoAsignacion{},
+	}
+	for _, limite := range limites {
+		reporte.TachosPorZona[limite.IDZona] = 0
+	}
+
+	for _, id := range ids {
+		tacho := tachos[id]
+		zonas := zonasQueContienen(limites, tacho.Latitude, tacho.Longitude)
+		if len(zonas) == 1 {
+			asignacion[id] = zonas[0]
+			reporte.TachosPorZona[zonas[0]]++
+			continue
+		}
+
+		detalle := TachoAsignacion{
+			ID:        id,
+			Barrio:    tacho.Barrio,
+			Direccion: tacho.Direccion,
+			Lat:       tacho.Latitude,
+			Lng:       tacho.Longitude,
+		}
+		if len(zonas) == 0 {
+			reporte.SinZona = append(reporte.SinZona, detalle)
+		} else {
+			detalle.Zonas = zonas
+			reporte.Superpuestos = append(reporte.Superpuestos, detalle)
+		}
+	}
+	reporte.Asignados = len(asignacion)
+
+	return asignacion, reporte
+}
+
+// GetReporteAsignacion calcula la asignación de tachos a zonas sin guardarla
+func GetReporteAsignacion() (*AsignacionReporte, error) {
+	_, reporte, err := prepararAsignacion()
+	return reporte, err
+}
+
+// AsignarTachosAZonas recalcula la zona de cada tacho por su ubicación en Neo4j
+// y reemplaza la asignación guardada en Tacho_zona
+func AsignarTachosAZonas() (*AsignacionReporte, error) {
+	asignacion, reporte, err := prepararAsignacion()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]models.TachoZona, 0, len(asignacion))
+	for id, zonaID := range asignacion {
+		rows = append(rows, models.TachoZona{IdNeo: id, IDZona: int64(zonaID)})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM Tacho_zona").Error; err != nil {
+			return fmt.Errorf("error deleting asignación anterior: %v", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("error inserting asignación: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	InvalidarRutasZonas(nil, MotivoAsignacion)
+
+	return reporte, nil
+}
+
+// prepararAsignacion obtiene límites y tachos y calcula la asignación
+func prepararAsignacion() (map[string]int, *AsignacionReporte, error) {
+	limites, sinLimite, err := getLimitesZonas()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tachos, err := GetAllTachosCoordinates()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	asignacion, reporte := calcularAsignacion(tachos, limites)
+	reporte.ZonasSinLimite = sinLimite
+	return asignacion, reporte, nil
+}
+
+// reasignarTachos recalcula la asignación después de cambiar límites de zonas.
+// Un error no invalida el cambio ya guardado, solo se registra.
+func reasignarTachos() {
+	if _, err := AsignarTachosAZonas(); err != nil {
+		log.Printf("Error reasignando tachos a zonas: %v", err)
+	}
+}
+
+// asignarTacho asigna un tacho nuevo a la zona que contiene su ubicación
+func asignarTacho(customID string, lat, lng float64) error {
+	limites, _, err := getLimitesZonas()
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Where("id_neo = ?", customID).Delete(&models.TachoZona{}).Error; err != nil {
+		return fmt.Errorf("error deleting asignación del tacho: %v", err)
+	}
+
+	zonas := zonasQueContienen(limites, lat, lng)
+	if len(zonas) != 1 {
+		log.Printf("Tacho %s sin zona única por ubicación (zonas: %v)", customID, zonas)
+		return nil
+	}
+	return config.DB.Create(&models.TachoZona{IdNeo: customID, IDZona: int64(zonas[0])}).Error
+}
+
+// desasignarTacho quita la asignación de un tacho eliminado
+func desasignarTacho(customID string) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	return config.DB.Where("id_neo = ?", customID).Delete(&models.TachoZona{}).Error
+}
+
+// getTachosAsignados devuelve los tachos asignados por ubicación a una zona.
+// conLimite es false si la zona no tiene límite y se debe usar sus barrios.
+func getTachosAsignados(zonaID int) (ids []string, conLimite bool, err error) {
+	if config.DB == nil {
+		return nil, false, fmt.Errorf("database connection not available")
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.ZonaLimite{}).Where("id_zona = ?", zonaID).Count(&count).Error; err != nil {
+		return nil, false, fmt.Errorf("error querying límite de la zona: %v", err)
+	}
+	if count == 0 {
+		return nil, false, nil
+	}
+
+	ids = []string{}
+	if err := config.DB.Raw("SELECT id_neo FROM Tacho_zona WHERE id_zona = ? ORDER BY id_neo", zonaID).Scan(&ids).Error; err != nil {
+		return nil, false, fmt.Errorf("error querying tachos de la zona: %v", err)
+	}
+	return ids, true, nil
+}
+
+// ValidarLimitesZonas verifica que el cuerpo sea un FeatureCollection de límites válido
+func ValidarLimitesZonas(raw json.RawMessage) error {
+	_, err := parseColeccionLimites(raw)
+	return err
+}
+
+// ImportarLimitesZonas guarda el límite de cada zona de un FeatureCollection.
+// Cada Feature identifica su zona con properties.id_zona o properties.nombre.
+// Después de importar se recalcula la asignación de tachos.
+func ImportarLimitesZonas(raw json.RawMessage) (*ImportacionLimites, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	coleccion, err := parseColeccionLimites(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	resultado := &ImportacionLimites{Importadas: []int{}, NoEncontradas: []string{}}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, feature := range coleccion.Features {
+			var zona config.ZonaDisponible
+			var result *gorm.DB
+			if feature.Properties.IDZona > 0 {
+				result = tx.Raw("SELECT id_zona, nombre FROM Zona WHERE id_zona = ?", feature.Properties.IDZona).Scan(&zona)
+			} else {
+				result = tx.Raw("SELECT id_zona, nombre FROM Zona WHERE UPPER(nombre) = ? LIMIT 1",
+					strings.ToUpper(strings.TrimSpace(feature.Properties.Nombre))).Scan(&zona)
+			}
+			if result.Error != nil {
+				return fmt.Errorf("error querying zona: %v", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				nombre := feature.Properties.Nombre
+				if feature.Properties.IDZona > 0 {
+					nombre = fmt.Sprintf("%d", feature.Properties.IDZona)
+				}
+				resultado.NoEncontradas = append(resultado.NoEncontradas, nombre)
+				continue
+			}
+
+			limite := models.ZonaLimite{IDZona: int64(zona.ID), GeoJSON: string(feature.Geometry)}
+			if err := tx.Save(&limite).Error; err != nil {
+				return fmt.Errorf("error saving límite de la zona %d: %v", zona.ID, err)
+			}
+			resultado.Importadas = append(resultado.Importadas, zona.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resultado.Asignacion, err = AsignarTachosAZonas()
+	if err != nil {
+		return nil, fmt.Errorf("límites importados, pero falló la asignación de tachos: %v", err)
+	}
+	return resultado, nil
+}
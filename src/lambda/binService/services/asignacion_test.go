@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cuadrado arma un polígono cuadrado de lado 1 con esquina inferior en (lng, lat)
+func cuadrado(lng, lat float64) poligono {
+	return poligono{{{lng, lat}, {lng + 1, lat}, {lng + 1, lat + 1}, {lng, lat + 1}, {lng, lat}}}
+}
+
+func TestContienePunto_ConHueco(t *testing.T) {
+	conHueco := poligono{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}},
+	}
+
+	assert.True(t, contienePunto([]poligono{conHueco}, 0.5, 0.5))
+	assert.False(t, contienePunto([]poligono{conHueco}, 2, 2), "el punto cae en el hueco")
+	assert.False(t, contienePunto([]poligono{conHueco}, 5, 5))
+}
+
+func TestCalcularAsignacion(t *testing.T) {
+	limites := []limiteZona{
+		{IDZona: 1, Poligonos: []poligono{cuadrado(0, 0)}},
+		{IDZona: 2, Poligonos: []poligono{cuadrado(0.5, 0)}},
+		{IDZona: 3, Poligonos: []poligono{cuadrado(5, 5)}},
+	}
+	tachos := map[string]TachoNeo4j{
+		"a|X": {ID: "a|X", Latitude: 0.5, Longitude: 0.2},
+		"b|X": {ID: "b|X", Latitude: 0.5, Longitude: 0.7},
+		"c|X": {ID: "c|X", Latitude: 5.5, Longitude: 5.5},
+		"d|X": {ID: "d|X", Latitude: 9, Longitude: 9},
+	}
+
+	asignacion, reporte := calcularAsignacion(tachos, limites)
+
+	assert.Equal(t, map[string]int{"a|X": 1, "c|X": 3}, asignacion)
+	assert.Equal(t, 4, reporte.TotalTachos)
+	assert.Equal(t, 2, reporte.Asignados)
+	assert.Equal(t, map[int]int{1: 1, 2: 0, 3: 1}, reporte.TachosPorZona)
+	if assert.Len(t, reporte.Superpuestos, 1) {
+		assert.Equal(t, "b|X", reporte.Superpuestos[0].ID)
+		assert.Equal(t, []int{1, 2}, reporte.Superpuestos[0].Zonas)
+	}
+	if assert.Len(t, reporte.SinZona, 1) {
+		assert.Equal(t, "d|X", reporte.SinZona[0].ID)
+	}
+}
+
+func TestParseColeccionLimites(t *testing.T) {
+	valida := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"nombre":"Boedo"},
+		"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`
+	coleccion, err := parseColeccionLimites([]byte(valida))
+	assert.NoError(t, err)
+	assert.Len(t, coleccion.Features, 1)
+
+	sinZona := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{},
+		"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}]}`
+	_, err = parseColeccionLimites([]byte(sinZona))
+	assert.Error(t, err)
+}
@@ -0,0 +1,566 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Estados de una corrida del calendario
+const (
+	CorridaPendiente = "pendiente"
+	CorridaGenerada  = "generada"
+	CorridaFeriado   = "feriado"
+)
+
+const (
+	// DiasCalendarioPorDefecto es cuántos días hacia adelante lista el calendario
+	DiasCalendarioPorDefecto = 14
+	// DiasCalendarioMax limita el rango del calendario y de la generación de rutas
+	DiasCalendarioMax = 90
+	// horizonteGeneracionDias es con cuánta anticipación se generan las rutas
+	// programadas: se calculan con el llenado actual de los tachos
+	horizonteGeneracionDias = 2
+	intervaloGeneracion     = time.Hour
+)
+
+// formatoFecha es el formato de las fechas de feriados (YYYY-MM-DD)
+const formatoFecha = "2006-01-02"
+
+// diasSemana son los días de las reglas, por sus tres primeras letras sin tilde
+var diasSemana = map[string]time.Weekday{
+	"dom": time.Sunday,
+	"lun": time.Monday,
+	"mar": time.Tuesday,
+	"mie": time.Wednesday,
+	"jue": time.Thursday,
+	"vie": time.Friday,
+	"sab": time.Saturday,
+}
+
+// ReglaCalendario es una regla de recolección recurrente de una zona
+type ReglaCalendario struct {
+	IDCalendario int      `json:"id_calendario,omitempty"`
+	Dias         []string `json:"dias" example:"lun,mie,vie"`
+	Hora         string   `json:"hora" example:"06:00"`
+	IDCamion     int      `json:"id_camion,omitempty" example:"2"`
+	IDPersona    string   `json:"id_persona,omitempty" example:"3"`
+	Algoritmo    string   `json:"algoritmo,omitempty" example:"completo"`
+	Activo       *bool    `json:"activo,omitempty"`
+}
+
+// CalendarioRequest reemplaza las reglas de recolección de una zona
+type CalendarioRequest struct {
+	Reglas []ReglaCalendario `json:"reglas"`
+}
+
+// CorridaCalendario es una salida programada de una regla
+type CorridaCalendario struct {
+	Fecha        time.Time `json:"fecha"`
+	IDCalendario int       `json:"id_calendario"`
+	Estado       string    `json:"estado"`
+	Feriado      string    `json:"feriado,omitempty"`
+	IDRuta       int       `json:"id_ruta,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// CalendarioZona son las reglas de una zona y sus próximas corridas
+type CalendarioZona struct {
+	IDZona   int                 `json:"id_zona"`
+	Desde    time.Time           `json:"desde"`
+	Hasta    time.Time           `json:"hasta"`
+	Reglas   []ReglaCalendario   `json:"reglas"`
+	Corridas []CorridaCalendario `json:"corridas"`
+	Total    int                 `json:"total"`
+}
+
+// FeriadoRequest representa un día sin recolección, para una zona o para todas
+type FeriadoRequest struct {
+	Fecha       string `json:"fecha" example:"2024-12-25"`
+	IDZona      int    `json:"id_zona,omitempty" example:"0"`
+	Descripcion string `json:"descripcion" example:"Navidad"`
+}
+
+// normalizarDia lleva un día ("Lunes", "mié", "sab") a su clave de tres letras
+func normalizarDia(dia string) (string, error) {
+	d := strings.ToLower(strings.TrimSpace(dia))
+	d = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u").Replace(d)
+	if len(d) >= 3 {
+		if _, ok := diasSemana[d[:3]]; ok {
+			return d[:3], nil
+		}
+	}
+	return "", fmt.Errorf("regla inválida: día desconocido %q (lun, mar, mie, jue, vie, sab, dom)", dia)
+}
+
+// ValidarCalendario normaliza días y algoritmo de las reglas y verifica la hora
+func ValidarCalendario(request *CalendarioRequest) error {
+	for i := range request.Reglas {
+		regla := &request.Reglas[i]
+		if len(regla.Dias) == 0 {
+			return fmt.Errorf("regla inválida: indicar al menos un día")
+		}
+		vistos := make(map[string]bool)
+		dias := make([]string, 0, len(regla.Dias))
+		for _, dia := range regla.Dias {
+			d, err := normalizarDia(dia)
+			if err != nil {
+				return err
+			}
+			if !vistos[d] {
+				vistos[d] = true
+				dias = append(dias, d)
+			}
+		}
+		regla.Dias = dias
+
+		minutos, err := parseHora(regla.Hora)
+		if err != nil {
+			return err
+		}
+		if minutos >= 24*60 {
+			return fmt.Errorf("hora inválida: %q (la salida debe ser antes de 24:00)", regla.Hora)
+		}
+
+		if regla.Algoritmo, err = ValidarAlgoritmo(regla.Algoritmo); err != nil {
+			return fmt.Errorf("regla inválida: %v", err)
+		}
+	}
+	return nil
+}
+
+// reglaDesdeModelo convierte una fila de Zona_calendario en la respuesta de la API
+func reglaDesdeModelo(fila models.ZonaCalendario) ReglaCalendario {
+	activo := fila.Activo
+	return ReglaCalendario{
+		IDCalendario: int(fila.IDCalendario),
+		Dias:         strings.Split(fila.Dias, ","),
+		Hora:         fila.Hora,
+		IDCamion:     int(fila.IDCamion),
+		IDPersona:    fila.IDPersona,
+		Algoritmo:    fila.Algoritmo,
+		Activo:       &activo,
+	}
+}
+
+// proximasCorridas expande las reglas activas en salidas entre desde y hasta.
+// Las que caen en un feriado (clave YYYY-MM-DD) quedan marcadas y no se generan.
+func proximasCorridas(reglas []models.ZonaCalendario, feriados map[string]string, desde, hasta time.Time) []CorridaCalendario {
+	corridas := []CorridaCalendario{}
+	dia := time.Date(desde.Year(), desde.Month(), desde.Day(), 0, 0, 0, 0, desde.Location())
+	for ; dia.Before(hasta); dia = dia.AddDate(0, 0, 1) {
+		for _, regla := range reglas {
+			if !regla.Activo || !reglaIncluyeDia(regla, dia.Weekday()) {
+				continue
+			}
+			minutos, err := parseHora(regla.Hora)
+			if err != nil {
+				continue
+			}
+			fecha := dia.Add(time.Duration(minutos) * time.Minute)
+			if fecha.Before(desde) || !fecha.Before(hasta) {
+				continue
+			}
+
+			corrida := CorridaCalendario{Fecha: fecha, IDCalendario: int(regla.IDCalendario), Estado: CorridaPendiente}
+			if descripcion, ok := feriados[fecha.Format(formatoFecha)]; ok {
+				corrida.Estado = CorridaFeriado
+				corrida.Feriado = descripcion
+			}
+			corridas = append(corridas, corrida)
+		}
+	}
+
+	sort.SliceStable(corridas, func(i, j int) bool {
+		if corridas[i].Fecha.Equal(corridas[j].Fecha) {
+			return corridas[i].IDCalendario < corridas[j].IDCalendario
+		}
+		return corridas[i].Fecha.Before(corridas[j].Fecha)
+	})
+	return corridas
+}
+
+// reglaIncluyeDia indica si la regla sale el día de la semana indicado
+func reglaIncluyeDia(regla models.ZonaCalendario, weekday time.Weekday) bool {
+	for _, dia := range strings.Split(regla.Dias, ",") {
+		if d, ok := diasSemana[dia]; ok && d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// verificarZona devuelve error si la zona no existe
+func verificarZona(db *gorm.DB, zonaID int) error {
+	var count int64
+	if err := db.Raw("SELECT COUNT(*) FROM Zona WHERE id_zona = ?", zonaID).Scan(&count).Error; err != nil {
+		return fmt.Errorf("error querying zona: %v", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("zona with ID %d not found", zonaID)
+	}
+	return nil
+}
+
+// getFeriadosZona devuelve los feriados de la zona (y los generales) entre dos fechas
+func getFeriadosZona(zonaID int, desde, hasta time.Time) (map[string]string, error) {
+	var filas []models.Feriado
+	if err := config.DB.Where("id_zona IN ? AND fecha BETWEEN ? AND ?", []int{0, zonaID},
+		desde.Format(formatoFecha), hasta.Format(formatoFecha)).Find(&filas).Error; err != nil {
+		return nil, fmt.Errorf("error querying feriados: %v", err)
+	}
+
+	feriados := make(map[string]string, len(filas))
+	for _, fila := range filas {
+		descripcion := fila.Descripcion
+		if descripcion == "" {
+			descripcion = "feriado"
+		}
+		feriados[fila.Fecha.Format(formatoFecha)] = descripcion
+	}
+	return feriados, nil
+}
+
+// GetCalendarioZona devuelve las reglas de la zona y sus corridas de los próximos días,
+// con la ruta ya generada para cada una si la hay
+func GetCalendarioZona(zonaID, dias int) (*CalendarioZona, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if err := verificarZona(config.DB, zonaID); err != nil {
+		return nil, err
+	}
+
+	var filas []models.ZonaCalendario
+	if err := config.DB.Where("id_zona = ?", zonaID).Order("id_calendario").Find(&filas).Error; err != nil {
+		return nil, fmt.Errorf("error querying calendario: %v", err)
+	}
+
+	desde := time.Now().In(config.ZonaHoraria())
+	hasta := desde.AddDate(0, 0, dias)
+	feriados, err := getFeriadosZona(zonaID, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	calendario := &CalendarioZona{
+		IDZona:   zonaID,
+		Desde:    desde,
+		Hasta:    hasta,
+		Reglas:   make([]ReglaCalendario, 0, len(filas)),
+		Corridas: proximasCorridas(filas, feriados, desde, hasta),
+	}
+	for _, fila := range filas {
+		calendario.Reglas = append(calendario.Reglas, reglaDesdeModelo(fila))
+	}
+
+	if len(filas) > 0 {
+		ids := make([]int64, 0, len(filas))
+		for _, fila := range filas {
+			ids = append(ids, fila.IDCalendario)
+		}
+		var planes []models.RutaPlan
+		if err := config.DB.Select("id_ruta", "id_calendario", "programada_para").
+			Where("id_calendario IN ? AND programada_para BETWEEN ? AND ?", ids, desde, hasta).
+			Find(&planes).Error; err != nil {
+			return nil, fmt.Errorf("error querying rutas programadas: %v", err)
+		}
+
+		generadas := make(map[string]int, len(planes))
+		for _, plan := range planes {
+			generadas[claveCorrida(*plan.IDCalendario, *plan.ProgramadaPara)] = int(plan.IDRuta)
+		}
+		for i := range calendario.Corridas {
+			corrida := &calendario.Corridas[i]
+			if idRuta, ok := generadas[claveCorrida(int64(corrida.IDCalendario), corrida.Fecha)]; ok {
+				corrida.Estado = CorridaGenerada
+				corrida.IDRuta = idRuta
+			}
+		}
+	}
+
+	calendario.Total = len(calendario.Corridas)
+	return calendario, nil
+}
+
+// claveCorrida identifica una corrida por regla y horario
+func claveCorrida(idCalendario int64, fecha time.Time) string {
+	return fmt.Sprintf("%d|%d", idCalendario, fecha.Unix())
+}
+
+// SetCalendarioZona reemplaza las reglas de recolección de la zona. Las rutas
+// ya generadas por las reglas anteriores que todavía no salieron se cancelan.
+func SetCalendarioZona(zonaID int, request CalendarioRequest) (*CalendarioZona, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if err := ValidarCalendario(&request); err != nil {
+		return nil, err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := verificarZona(tx, zonaID); err != nil {
+			return err
+		}
+
+		var anteriores []int64
+		if err := tx.Model(&models.ZonaCalendario{}).Where("id_zona = ?", zonaID).
+			Pluck("id_calendario", &anteriores).Error; err != nil {
+			return fmt.Errorf("error querying calendario: %v", err)
+		}
+		if len(anteriores) > 0 {
+			if err := cancelarRutasProgramadas(tx.Where("id_calendario IN ?", anteriores), "calendario de la zona modificado"); err != nil {
+				return err
+			}
+			if err := tx.Where("id_zona = ?", zonaID).Delete(&models.ZonaCalendario{}).Error; err != nil {
+				return fmt.Errorf("error deleting calendario: %v", err)
+			}
+		}
+
+		for _, regla := range request.Reglas {
+			fila := models.ZonaCalendario{
+				IDZona:    int64(zonaID),
+				Dias:      strings.Join(regla.Dias, ","),
+				Hora:      regla.Hora,
+				IDCamion:  int64(regla.IDCamion),
+				IDPersona: regla.IDPersona,
+				Algoritmo: regla.Algoritmo,
+				Activo:    regla.Activo == nil || *regla.Activo,
+			}
+			if err := tx.Create(&fila).Error; err != nil {
+				return fmt.Errorf("error inserting regla: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetCalendarioZona(zonaID, DiasCalendarioPorDefecto)
+}
+
+// cancelarRutasProgramadas cancela las rutas generadas por el calendario que
+// cumplen el filtro y todavía no salieron. Se desvinculan de su regla para que
+// la corrida se pueda volver a generar.
+func cancelarRutasProgramadas(filtro *gorm.DB, motivo string) error {
+	if err := filtro.Model(&models.RutaPlan{}).
+		Where("estado = ? AND programada_para > ?", EstadoPlanificada, time.Now()).
+		Updates(map[string]interface{}{
+			"estado":             EstadoCancelada,
+			"motivo_cancelacion": motivo,
+			"id_calendario":      nil,
+		}).Error; err != nil {
+		return fmt.Errorf("error cancelando rutas programadas: %v", err)
+	}
+	return nil
+}
+
+// GenerarPlanesCalendario genera las rutas de las corridas pendientes de los
+// próximos días de la zona y devuelve las corridas procesadas. Un error en una
+// corrida queda en la corrida y no frena a las demás.
+func GenerarPlanesCalendario(zonaID, dias int) ([]CorridaCalendario, error) {
+	calendario, err := GetCalendarioZona(zonaID, dias)
+	if err != nil {
+		return nil, err
+	}
+
+	reglas := make(map[int]ReglaCalendario, len(calendario.Reglas))
+	for _, regla := range calendario.Reglas {
+		reglas[regla.IDCalendario] = regla
+	}
+
+	procesadas := []CorridaCalendario{}
+	for _, corrida := range calendario.Corridas {
+		if corrida.Estado != CorridaPendiente {
+			continue
+		}
+
+		plan, err := generarCorrida(zonaID, reglas[corrida.IDCalendario], corrida.Fecha)
+		switch {
+		case esClaveDuplicada(err):
+			// Otra instancia la generó mientras tanto
+			continue
+		case err != nil:
+			log.Printf("Error generando ruta de zona %d para %s: %v", zonaID, corrida.Fecha.Format(time.RFC3339), err)
+			corrida.Error = err.Error()
+		default:
+			corrida.Estado = CorridaGenerada
+			corrida.IDRuta = plan.IDRuta
+		}
+		procesadas = append(procesadas, corrida)
+	}
+	return procesadas, nil
+}
+
+// esClaveDuplicada indica si MySQL rechazó el insert por un índice único
+// (error 1062), por ejemplo una corrida que ya generó otra instancia
+func esClaveDuplicada(err error) bool {
+	var errMySQL *mysql.MySQLError
+	return errors.As(err, &errMySQL) && errMySQL.Number == 1062
+}
+
+// generarCorrida calcula la ruta de la zona y la guarda programada para la fecha
+func generarCorrida(zonaID int, regla ReglaCalendario, fecha time.Time) (*PlanRuta, error) {
+	request := CrearPlanRequest{
+		IDZona:    zonaID,
+		IDCamion:  regla.IDCamion,
+		IDPersona: regla.IDPersona,
+		Algoritmo: regla.Algoritmo,
+	}
+	ruta, err := calcularRutaPlan(&request, fecha)
+	if err != nil {
+		return nil, err
+	}
+
+	idCalendario := int64(regla.IDCalendario)
+	return guardarPlan(models.RutaPlan{
+		IDZona:         int64(request.IDZona),
+		IDCamion:       int64(request.IDCamion),
+		IDPersona:      request.IDPersona,
+		IDCalendario:   &idCalendario,
+		ProgramadaPara: &fecha,
+	}, ruta)
+}
+
+// GenerarPlanesProgramados genera las rutas de los próximos días de todas las
+// zonas con reglas activas
+func GenerarPlanesProgramados() {
+	if config.DB == nil {
+		return
+	}
+
+	var zonas []int
+	if err := config.DB.Model(&models.ZonaCalendario{}).Where("activo = ?", true).
+		Distinct().Pluck("id_zona", &zonas).Error; err != nil {
+		log.Printf("Error querying zonas con calendario: %v", err)
+		return
+	}
+
+	for _, zonaID := range zonas {
+		corridas, err := GenerarPlanesCalendario(zonaID, horizonteGeneracionDias)
+		if err != nil {
+			log.Printf("Error generando rutas programadas de zona %d: %v", zonaID, err)
+			continue
+		}
+		if len(corridas) > 0 {
+			log.Printf("📅 Zona %d: %d corridas programadas procesadas", zonaID, len(corridas))
+		}
+	}
+}
+
+// IniciarGeneradorCalendario genera periódicamente las rutas programadas
+func IniciarGeneradorCalendario() {
+	go func() {
+		GenerarPlanesProgramados()
+		ticker := time.NewTicker(intervaloGeneracion)
+		defer ticker.Stop()
+		for range ticker.C {
+			GenerarPlanesProgramados()
+		}
+	}()
+}
+
+// parseFecha lee una fecha YYYY-MM-DD en la zona horaria configurada
+func parseFecha(fecha string) (time.Time, error) {
+	t, err := time.ParseInLocation(formatoFecha, strings.TrimSpace(fecha), config.ZonaHoraria())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha inválida: %q (formato YYYY-MM-DD)", fecha)
+	}
+	return t, nil
+}
+
+// feriadoDesdeModelo convierte una fila de Feriado en la respuesta de la API
+func feriadoDesdeModelo(fila models.Feriado) FeriadoRequest {
+	return FeriadoRequest{
+		Fecha:       fila.Fecha.Format(formatoFecha),
+		IDZona:      int(fila.IDZona),
+		Descripcion: fila.Descripcion,
+	}
+}
+
+// GetFeriados lista los feriados desde hoy; con zona, solo los que la afectan
+func GetFeriados(zonaID int) ([]FeriadoRequest, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	query := config.DB.Where("fecha >= ?", time.Now().In(config.ZonaHoraria()).Format(formatoFecha))
+	if zonaID > 0 {
+		query = query.Where("id_zona IN ?", []int{0, zonaID})
+	}
+	var filas []models.Feriado
+	if err := query.Order("fecha").Order("id_zona").Find(&filas).Error; err != nil {
+		return nil, fmt.Errorf("error querying feriados: %v", err)
+	}
+
+	feriados := make([]FeriadoRequest, 0, len(filas))
+	for _, fila := range filas {
+		feriados = append(feriados, feriadoDesdeModelo(fila))
+	}
+	return feriados, nil
+}
+
+// SetFeriado crea o actualiza un feriado y cancela las rutas ya generadas para ese día
+func SetFeriado(request FeriadoRequest) (*FeriadoRequest, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	fecha, err := parseFecha(request.Fecha)
+	if err != nil {
+		return nil, err
+	}
+
+	fila := models.Feriado{Fecha: fecha, IDZona: int64(request.IDZona), Descripcion: strings.TrimSpace(request.Descripcion)}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if fila.IDZona > 0 {
+			if err := verificarZona(tx, request.IDZona); err != nil {
+				return err
+			}
+		}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&fila).Error; err != nil {
+			return fmt.Errorf("error inserting feriado: %v", err)
+		}
+
+		filtro := tx.Where("id_calendario IS NOT NULL AND programada_para >= ? AND programada_para < ?", fecha, fecha.AddDate(0, 0, 1))
+		if fila.IDZona > 0 {
+			filtro = filtro.Where("id_zona = ?", fila.IDZona)
+		}
+		return cancelarRutasProgramadas(filtro, "feriado: "+fila.Descripcion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	feriado := feriadoDesdeModelo(fila)
+	return &feriado, nil
+}
+
+// DeleteFeriado elimina un feriado. Las corridas de ese día vuelven a generarse.
+func DeleteFeriado(fecha string, zonaID int) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	dia, err := parseFecha(fecha)
+	if err != nil {
+		return err
+	}
+
+	result := config.DB.Where("fecha = ? AND id_zona = ?", dia.Format(formatoFecha), zonaID).Delete(&models.Feriado{})
+	if result.Error != nil {
+		return fmt.Errorf("error deleting feriado: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("feriado %s for zona %d not found", dia.Format(formatoFecha), zonaID)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidarCalendario(t *testing.T) {
+	request := CalendarioRequest{Reglas: []ReglaCalendario{
+		{Dias: []string{"Lunes", "miércoles", "VIE", "lun"}, Hora: "06:00"},
+	}}
+	assert.NoError(t, ValidarCalendario(&request))
+	assert.Equal(t, []string{"lun", "mie", "vie"}, request.Reglas[0].Dias)
+	assert.Equal(t, AlgoritmoPorDefecto, request.Reglas[0].Algoritmo)
+
+	tests := []ReglaCalendario{
+		{Hora: "06:00"},
+		{Dias: []string{"feriado"}, Hora: "06:00"},
+		{Dias: []string{"lun"}, Hora: "6"},
+		{Dias: []string{"lun"}, Hora: "24:00"},
+		{Dias: []string{"lun"}, Hora: "06:00", Algoritmo: "magico"},
+	}
+	for _, regla := range tests {
+		assert.Error(t, ValidarCalendario(&CalendarioRequest{Reglas: []ReglaCalendario{regla}}), "%+v", regla)
+	}
+}
+
+func TestProximasCorridas(t *testing.T) {
+	// Lunes 6 de mayo de 2024, 07:00
+	desde := time.Date(2024, 5, 6, 7, 0, 0, 0, time.Local)
+	hasta := desde.AddDate(0, 0, 7)
+	reglas := []models.ZonaCalendario{
+		{IDCalendario: 1, Dias: "lun,mie,vie", Hora: "06:00", Activo: true},
+		{IDCalendario: 2, Dias: "lun", Hora: "20:00", Activo: true},
+		{IDCalendario: 3, Dias: "mar", Hora: "06:00", Activo: false},
+	}
+	feriados := map[string]string{"2024-05-10": "Feriado puente"}
+
+	corridas := proximasCorridas(reglas, feriados, desde, hasta)
+
+	var fechas []string
+	for _, corrida := range corridas {
+		fechas = append(fechas, corrida.Fecha.Format("Mon 02 15:04"))
+	}
+	// La del lunes 06:00 ya pasó y la regla inactiva no genera corridas
+	assert.Equal(t, []string{"Mon 06 20:00", "Wed 08 06:00", "Fri 10 06:00", "Mon 13 06:00"}, fechas)
+	assert.Equal(t, CorridaFeriado, corridas[2].Estado)
+	assert.Equal(t, "Feriado puente", corridas[2].Feriado)
+	assert.Equal(t, CorridaPendiente, corridas[1].Estado)
+}
+
+func TestEsClaveDuplicada(t *testing.T) {
+	assert.True(t, esClaveDuplicada(fmt.Errorf("error inserting ruta: %w", &mysql.MySQLError{Number: 1062})))
+	assert.False(t, esClaveDuplicada(fmt.Errorf("error inserting ruta: %w", &mysql.MySQLError{Number: 1452})))
+	assert.False(t, esClaveDuplicada(errors.New("Duplicate entry '1' for key 'idx_ruta_corrida'")), "no se decide por el texto")
+	assert.False(t, esClaveDuplicada(nil))
+}
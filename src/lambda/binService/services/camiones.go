@@ -9,6 +9,7 @@ import (
 // Estructura para representar un camión con información completa
 type Camion struct {
 	IDCamion   int    `json:"id_camion" gorm:"column:id_camion"`
+	IDTipo     int    `json:"id_tipo" gorm:"column:id_tipo"`
 	NombreTipo string `json:"nombre_tipo" gorm:"column:nombre_tipo"`
 	TipoEstado string `json:"tipo_estado" gorm:"column:tipo_estado"`
 }
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+)
+
+// TipoPuntoDescarga marca una parada intermedia en un centro para vaciar el camión
+const TipoPuntoDescarga = "descarga"
+
+// Volumen de un tacho lleno y capacidad de un camión cuando Tipo_camion no la
+// informa (pueden ser override por env VOLUMEN_TACHO_LITROS y CAPACIDAD_CAMION_LITROS)
+var (
+	volumenTachoLitros    = 1100.0
+	capacidadCamionLitros = 20000.0
+)
+
+func init() {
+	if v, err := strconv.ParseFloat(os.Getenv("VOLUMEN_TACHO_LITROS"), 64); err == nil && v > 0 {
+		volumenTachoLitros = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("CAPACIDAD_CAMION_LITROS"), 64); err == nil && v > 0 {
+		capacidadCamionLitros = v
+	}
+}
+
+// TipoCamion representa un tipo de camión con su capacidad de carga
+type TipoCamion struct {
+	IDTipo          int     `json:"id_tipo" gorm:"column:id_tipo"`
+	NombreTipo      string  `json:"nombre_tipo" gorm:"column:nombre_tipo"`
+	CapacidadLitros float64 `json:"capacidad_litros" gorm:"column:capacidad"`
+}
+
+// getTiposCamion obtiene los tipos de camión desde MySQL. La columna capacidad
+// la agrega la migración; los tipos sin capacidad usan la capacidad por defecto.
+func getTiposCamion() (map[int]TipoCamion, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var tipos []TipoCamion
+	if err := config.DB.Raw("SELECT id_tipo, nombre_tipo, capacidad FROM Tipo_camion").Scan(&tipos).Error; err != nil {
+		return nil, fmt.Errorf("error querying tipos de camion: %v", err)
+	}
+
+	tiposMap := make(map[int]TipoCamion)
+	for _, tipo := range tipos {
+		if tipo.CapacidadLitros <= 0 {
+			tipo.CapacidadLitros = capacidadCamionLitros
+		}
+		tiposMap[tipo.IDTipo] = tipo
+	}
+
+	return tiposMap, nil
+}
+
+// tipoCamionOrDefault devuelve el tipo pedido o uno con la capacidad por defecto
+func tipoCamionOrDefault(tipos map[int]TipoCamion, idTipo int) TipoCamion {
+	if tipo, ok := tipos[idTipo]; ok {
+		return tipo
+	}
+	return TipoCamion{IDTipo: idTipo, CapacidadLitros: capacidadCamionLitros}
+}
+
+// getCentrosDescarga obtiene todos los centros con ubicación como puntos de descarga
+func getCentrosDescarga() ([]Centro, error) {
+	response, err := GetAllCentros()
+	if err != nil {
+		return nil, err
+	}
+
+	centros := []Centro{}
+	for _, centro := range response.Centros {
+		if centro.Latitud == 0 && centro.Longitud == 0 {
+			continue
+		}
+		centros = append(centros, centro)
+	}
+	return centros, nil
+}
+
+// centrosCompatibles filtra los centros cuyo tipo coincide con el tipo de camión.
+// Si ninguno coincide se consideran compatibles todos.
+func centrosCompatibles(centros []Centro, nombreTipoCamion string) []Point {
+	tipoCamion := strings.ToLower(strings.TrimSpace(nombreTipoCamion))
+
+	compatibles := []Point{}
+	todos := []Point{}
+	for _, centro := range centros {
+		point := Point{
+			ID:     centro.IDCentro,
+			Tipo:   TipoPuntoDescarga,
+			Nombre: centro.Nombre,
+			Lat:    centro.Latitud,
+			Lng:    centro.Longitud,
+		}
+		todos = append(todos, point)
+
+		tipoCentro := strings.ToLower(strings.TrimSpace(centro.NombreTipo))
+		if tipoCamion != "" && tipoCentro != "" &&
+			(strings.Contains(tipoCentro, tipoCamion) || strings.Contains(tipoCamion, tipoCentro)) {
+			compatibles = append(compatibles, point)
+		}
+	}
+
+	if len(compatibles) == 0 {
+		return todos
+	}
+	return compatibles
+}
+
+// volumenEstimado estima los litros a recolectar en un tacho según su llenado
+func volumenEstimado(capacidad float64) float64 {
+	if capacidad <= 0 {
+		return 0
+	}
+	if capacidad > 100 {
+		capacidad = 100
+	}
+	return capacidad / 100 * volumenTachoLitros
+}
+
+// centroMasCercano devuelve el centro más cercano a un punto
+func centroMasCercano(desde Point, centros []Point) Point {
+	mejor := centros[0]
+	mejorDist := haversine(desde.Lat, desde.Lng, mejor.Lat, mejor.Lng)
+	for _, centro := range centros[1:] {
+		if d := haversine(desde.Lat, desde.Lng, centro.Lat, centro.Lng); d < mejorDist {
+			mejor = centro
+			mejorDist = d
+		}
+	}
+	return mejor
+}
+
+// AplicarCapacidad recorre la ruta estimando la carga del camión en cada parada.
+// Cuando el próximo tacho excede la capacidad, inserta un viaje al centro
+// compatible más cercano y continúa la ruta con el camión vacío.
+func AplicarCapacidad(ruta *Ruta, capacidadLitros float64, centros []Point) *Ruta {
+	return aplicarCapacidadDesde(ruta, capacidadLitros, centros, 0)
+}
+
+// aplicarCapacidadDesde es AplicarCapacidad con el camión ya cargado con
+// cargaInicial litros, como al reoptimizar una ruta en curso
+func aplicarCapacidadDesde(ruta *Ruta, capacidadLitros float64, centros []Point, cargaInicial float64) *Ruta {
+	resultado := &Ruta{
+		Algoritmo:       ruta.Algoritmo,
+		CapacidadLitros: capacidadLitros,
+		Urgentes:        ruta.Urgentes,
+		Omitidos:        ruta.Omitidos,
+		Puntos:          make([]Point, 0, len(ruta.Puntos)),
+	}
+
+	carga := cargaInicial
+	for _, point := range ruta.Puntos {
+		if point.Tipo == TipoPuntoTacho {
+			point.Volumen = volumenEstimado(point.Capacidad)
+
+			if carga > 0 && carga+point.Volumen > capacidadLitros && len(centros) > 0 {
+				anterior := point
+				if len(resultado.Puntos) > 0 {
+					anterior = resultado.Puntos[len(resultado.Puntos)-1]
+				}
+				resultado.Puntos = append(resultado.Puntos, centroMasCercano(anterior, centros))
+				resultado.Descargas++
+				carga = 0
+			}
+
+			carga += point.Volumen
+			point.Carga = carga
+		}
+
+		resultado.Puntos = append(resultado.Puntos, point)
+	}
+
+	for i := 1; i < len(resultado.Puntos); i++ {
+		a, b := resultado.Puntos[i-1], resultado.Puntos[i]
+		resultado.DistanciaTotal += haversine(a.Lat, a.Lng, b.Lat, b.Lng)
+	}
+
+	return resultado
+}
+
+// getTachosMySQL obtiene las filas de Tacho de MySQL para cada ID personalizado
+func getTachosMySQL(customIDs []string) (map[string]TachoMySQL, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	tachos := make(map[string]TachoMySQL)
+	if len(customIDs) == 0 {
+		return tachos, nil
+	}
+
+	var rows []TachoMySQL
+	if err := config.DB.Raw("SELECT id_tacho, id_neo, capacidad FROM Tacho WHERE id_neo IN ?", customIDs).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error getting tachos capacidad: %v", err)
+	}
+
+	for _, row := range rows {
+		tachos[row.IdNeo] = row
+	}
+	return tachos, nil
+}
+
+// completarDatosMySQL agrega a cada punto su id_tacho y el llenado registrado en MySQL
+func completarDatosMySQL(points []Point) error {
+	customIDs := make([]string, 0, len(points))
+	for _, p := range points {
+		customIDs = append(customIDs, p.CustomID)
+	}
+
+	tachos, err := getTachosMySQL(customIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range points {
+		tacho := tachos[points[i].CustomID]
+		points[i].ID = tacho.ID
+		points[i].IDTacho = tacho.ID
+		points[i].Capacidad = tacho.Capacidad
+	}
+	return nil
+}
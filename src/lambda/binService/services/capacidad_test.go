@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAplicarCapacidad_InsertaDescarga(t *testing.T) {
+	ruta := &Ruta{
+		Algoritmo: AlgoritmoCompleto,
+		Puntos: []Point{
+			{ID: 1, Tipo: TipoPuntoTacho, Capacidad: 100, Lat: -34.60, Lng: -58.40},
+			{ID: 2, Tipo: TipoPuntoTacho, Capacidad: 50, Lat: -34.60, Lng: -58.41},
+			{ID: 3, Tipo: TipoPuntoTacho, Capacidad: 80, Lat: -34.60, Lng: -58.42},
+		},
+	}
+	centros := []Point{
+		{ID: 100, Tipo: TipoPuntoDescarga, Lat: -34.70, Lng: -58.41},
+		{ID: 200, Tipo: TipoPuntoDescarga, Lat: -34.61, Lng: -58.41},
+	}
+
+	// Entran dos tachos (1100 + 550 litros), el tercero obliga a descargar
+	resultado := AplicarCapacidad(ruta, 2000, centros)
+
+	ids := []int{}
+	for _, p := range resultado.Puntos {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []int{1, 2, 200, 3}, ids, "descarga en el centro más cercano")
+	assert.Equal(t, 1, resultado.Descargas)
+	assert.Equal(t, 2000.0, resultado.CapacidadLitros)
+	assert.InDelta(t, volumenTachoLitros*1.5, resultado.Puntos[1].Carga, 1e-9)
+	assert.InDelta(t, volumenTachoLitros*0.8, resultado.Puntos[3].Carga, 1e-9)
+	assert.Greater(t, resultado.DistanciaTotal, 0.0)
+}
+
+func TestAplicarCapacidad_SinExceso(t *testing.T) {
+	ruta := &Ruta{Puntos: []Point{
+		{ID: 1, Tipo: TipoPuntoOrigen},
+		{ID: 2, Tipo: TipoPuntoTacho, Capacidad: 10},
+		{ID: 3, Tipo: TipoPuntoTacho, Capacidad: 10},
+	}}
+
+	resultado := AplicarCapacidad(ruta, 20000, []Point{{ID: 9, Tipo: TipoPuntoDescarga}})
+
+	assert.Len(t, resultado.Puntos, 3)
+	assert.Equal(t, 0, resultado.Descargas)
+}
+
+func TestCentrosCompatibles(t *testing.T) {
+	centros := []Centro{
+		{IDCentro: 1, NombreTipo: "Reciclables", Latitud: -34.6, Longitud: -58.4},
+		{IDCentro: 2, NombreTipo: "Residuos húmedos", Latitud: -34.7, Longitud: -58.5},
+	}
+
+	compatibles := centrosCompatibles(centros, "reciclables")
+	assert.Len(t, compatibles, 1)
+	assert.Equal(t, 1, compatibles[0].ID)
+	assert.Equal(t, TipoPuntoDescarga, compatibles[0].Tipo)
+
+	// Sin coincidencias cualquier centro sirve
+	assert.Len(t, centrosCompatibles(centros, "compactador"), 2)
+}
+
+func TestAplicarCapacidadDesde_CamionCargado(t *testing.T) {
+	ruta := &Ruta{Puntos: []Point{
+		{ID: 1, Tipo: TipoPuntoOrigen, Lat: -34.60, Lng: -58.40},
+		{ID: 2, Tipo: TipoPuntoTacho, Capacidad: 50, Lat: -34.60, Lng: -58.41},
+	}}
+	centros := []Point{{ID: 9, Tipo: TipoPuntoDescarga, Lat: -34.61, Lng: -58.40}}
+
+	resultado := aplicarCapacidadDesde(ruta, volumenTachoLitros, centros, volumenTachoLitros*0.8)
+
+	ids := []int{}
+	for _, p := range resultado.Puntos {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []int{1, 9, 2}, ids, "descarga antes del primer tacho si el camión ya viene lleno")
+	assert.Equal(t, 1, resultado.Descargas)
+}
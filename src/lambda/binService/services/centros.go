@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 // Estructura para representar un centro con información completa (MySQL + Neo4j)
@@ -235,3 +236,201 @@ func getFloatValue(value interface{}) float64 {
 	}
 	return 0.0
 }
+
+// nodoCentro es un centro tal como está en Neo4j
+type nodoCentro struct {
+	NodeID    string
+	ID        string
+	Nombre    string
+	Barrio    string
+	Direccion string
+	Latitud   float64
+	Longitud  float64
+}
+
+// centro devuelve los datos del nodo como los escribe el outbox
+func (n nodoCentro) centro() CentroNeo4j {
+	return CentroNeo4j{ID: n.ID, Nombre: n.Nombre, Barrio: n.Barrio, Direccion: n.Direccion, Latitud: n.Latitud, Longitud: n.Longitud}
+}
+
+// getNodosCentroNeo4j obtiene los nodos de centros. Los centros no tienen una
+// etiqueta fija (getCentroFromNeo4j los busca solo por id), así que se toman
+// los nodos con id que no son tachos ni intersecciones de la red vial.
+func getNodosCentroNeo4j() ([]nodoCentro, error) {
+	session, err := getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(context.Background())
+
+	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		records, err := tx.Run(ctx, `
+			MATCH (c)
+			WHERE c.id IS NOT NULL AND NOT c:Tacho AND NOT c:Interseccion
+			RETURN c.id AS id, c.nombre AS nombre, c.barrio AS barrio, c.direccion AS direccion,
+			       c.location.latitude AS latitud, c.location.longitude AS longitud,
+			       elementId(c) AS nodeId
+			ORDER BY elementId(c)
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		nodos := []nodoCentro{}
+		for records.Next(ctx) {
+			rec := records.Record()
+			id, _ := rec.Get("id")
+			nombre, _ := rec.Get("nombre")
+			barrio, _ := rec.Get("barrio")
+			direccion, _ := rec.Get("direccion")
+			latitud, _ := rec.Get("latitud")
+			longitud, _ := rec.Get("longitud")
+			nodeID, _ := rec.Get("nodeId")
+			nodos = append(nodos, nodoCentro{
+				NodeID:    getStringValue(nodeID),
+				ID:        getStringValue(id),
+				Nombre:    getStringValue(nombre),
+				Barrio:    getStringValue(barrio),
+				Direccion: getStringValue(direccion),
+				Latitud:   getFloatValue(latitud),
+				Longitud:  getFloatValue(longitud),
+			})
+		}
+		return nodos, records.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting centros from Neo4j: %v", err)
+	}
+	return result.([]nodoCentro), nil
+}
+
+// centroFila es un centro tal como está en MySQL
+type centroFila struct {
+	IDCentro int    `gorm:"column:id_centro"`
+	IDNeo    string `gorm:"column:id_neo"`
+}
+
+// getCentrosMySQL obtiene id_centro e id_neo de todos los centros
+func getCentrosMySQL() ([]centroFila, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var centros []centroFila
+	if err := config.DB.Raw("SELECT id_centro, id_neo FROM Centro ORDER BY id_centro").Scan(&centros).Error; err != nil {
+		return nil, fmt.Errorf("error querying centros from MySQL: %v", err)
+	}
+	return centros, nil
+}
+
+// CentroNeo4j son los datos del nodo de un centro
+type CentroNeo4j struct {
+	ID        string  `json:"id"`
+	Nombre    string  `json:"nombre"`
+	Barrio    string  `json:"barrio"`
+	Direccion string  `json:"direccion"`
+	Latitud   float64 `json:"latitud"`
+	Longitud  float64 `json:"longitud"`
+}
+
+// guardarCentroEnNeo4j deja el nodo del centro con los datos indicados: lo
+// busca por idActual (o lo crea si no existe). Es idempotente.
+func guardarCentroEnNeo4j(idActual string, centro CentroNeo4j) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close(context.Background())
+
+	params := map[string]interface{}{
+		"idActual":  idActual,
+		"id":        centro.ID,
+		"nombre":    centro.Nombre,
+		"barrio":    centro.Barrio,
+		"direccion": centro.Direccion,
+		"latitud":   centro.Latitud,
+		"longitud":  centro.Longitud,
+	}
+	_, err = session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		// Los centros cargados antes no tienen etiqueta fija: se buscan por id
+		// como en getNodosCentroNeo4j y solo si no existe se crea con :Centro
+		records, err := tx.Run(ctx, `
+			MATCH (c)
+			WHERE c.id = $idActual AND NOT c:Tacho AND NOT c:Interseccion
+			SET c.id = $id, c.nombre = $nombre, c.barrio = $barrio, c.direccion = $direccion,
+			    c.location = point({latitude: $latitud, longitude: $longitud})
+			RETURN count(c) AS total
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+		rec, err := records.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if total, _ := rec.Get("total"); total.(int64) > 0 {
+			return nil, nil
+		}
+		_, err = tx.Run(ctx, `
+			CREATE (:Centro {id: $id, nombre: $nombre, barrio: $barrio, direccion: $direccion,
+			                 location: point({latitude: $latitud, longitude: $longitud})})
+		`, params)
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("error saving centro in Neo4j: %v", err)
+	}
+	return nil
+}
+
+// borrarCentroNeo4jPorID elimina los nodos de centro con el id indicado.
+// Si ya no existen no es error.
+func borrarCentroNeo4jPorID(customID string) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close(context.Background())
+
+	_, err = session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		_, err := tx.Run(ctx, `
+			MATCH (c)
+			WHERE c.id = $id AND NOT c:Tacho AND NOT c:Interseccion
+			DETACH DELETE c
+		`, map[string]interface{}{"id": customID})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting centro from Neo4j: %v", err)
+	}
+	return nil
+}
+
+// deduplicarCentroNeo4j deja un solo nodo de centro con el id: conserva el de
+// menor elementId (el primero de getNodosCentroNeo4j). Es idempotente.
+func deduplicarCentroNeo4j(customID string) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close(context.Background())
+
+	_, err = session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		_, err := tx.Run(ctx, `
+			MATCH (c)
+			WHERE c.id = $id AND NOT c:Tacho AND NOT c:Interseccion
+			WITH c ORDER BY elementId(c)
+			WITH collect(c) AS nodos
+			FOREACH (c IN nodos[1..] | DETACH DELETE c)
+		`, map[string]interface{}{"id": customID})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting duplicated centros from Neo4j: %v", err)
+	}
+	return nil
+}
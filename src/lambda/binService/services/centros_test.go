@@ -0,0 +1,11 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAplicarOutboxCentroOperacionDesconocida(t *testing.T) {
+	assert.EqualError(t, aplicarEventoOutbox(EntidadOutboxCentro, "mover", nil), "operación de outbox desconocida: mover")
+}
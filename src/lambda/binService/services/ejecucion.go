@@ -0,0 +1,225 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Estados de una parada de tacho durante la ejecución de la ruta
+const (
+	EstadoParadaPendiente   = "pendiente"
+	EstadoParadaRecolectada = "recolectada"
+	EstadoParadaOmitida     = "omitida"
+	EstadoParadaBloqueada   = "bloqueada"
+)
+
+// Supuestos para estimar la llegada mientras no haya ritmo observado
+const (
+	velocidadPromedioKmh = 20.0
+	minutosPorParada     = 2.0
+)
+
+// ParadaPlan es una parada de una ruta guardada con su estado de ejecución
+type ParadaPlan struct {
+	Point
+	Estado     string     `json:"estado,omitempty"`
+	Motivo     string     `json:"motivo,omitempty"`
+	AtendidaEn *time.Time `json:"atendida_en,omitempty"`
+}
+
+// ProgresoPlan resume el avance de una ruta sobre sus paradas de tachos
+type ProgresoPlan struct {
+	TotalParadas       int     `json:"total_paradas"`
+	Recolectadas       int     `json:"recolectadas"`
+	Omitidas           int     `json:"omitidas"`
+	Bloqueadas         int     `json:"bloqueadas"`
+	Pendientes         int     `json:"pendientes"`
+	Porcentaje         float64 `json:"porcentaje"`
+	DistanciaRecorrida float64 `json:"distancia_recorrida_km"`
+	DistanciaRestante  float64 `json:"distancia_restante_km"`
+}
+
+// paradasPlan convierte las filas de Ruta_parada en paradas de la respuesta
+func paradasPlan(filas []models.RutaParada) []ParadaPlan {
+	paradas := make([]ParadaPlan, 0, len(filas))
+	for _, fila := range filas {
+		paradas = append(paradas, ParadaPlan{
+			Point:      puntoDesdeParada(fila),
+			Estado:     fila.Estado,
+			Motivo:     fila.Motivo,
+			AtendidaEn: fila.AtendidaEn,
+		})
+	}
+	return paradas
+}
+
+// MarcarParadaRequest representa el motivo al omitir o bloquear una parada
+type MarcarParadaRequest struct {
+	Motivo string `json:"motivo" example:"Auto estacionado delante del tacho"`
+}
+
+// calcularProgreso cuenta las paradas de tachos por estado. La distancia
+// recorrida llega hasta la última parada atendida (en cualquier estado).
+func calcularProgreso(paradas []models.RutaParada, distanciaTotal float64) ProgresoPlan {
+	var progreso ProgresoPlan
+	for _, parada := range paradas {
+		if parada.Tipo != TipoPuntoTacho {
+			continue
+		}
+		progreso.TotalParadas++
+		switch parada.Estado {
+		case EstadoParadaRecolectada:
+			progreso.Recolectadas++
+		case EstadoParadaOmitida:
+			progreso.Omitidas++
+		case EstadoParadaBloqueada:
+			progreso.Bloqueadas++
+		default:
+			progreso.Pendientes++
+		}
+		if parada.Estado != EstadoParadaPendiente && parada.DistanciaAcumulada > progreso.DistanciaRecorrida {
+			progreso.DistanciaRecorrida = parada.DistanciaAcumulada
+		}
+	}
+
+	if progreso.TotalParadas > 0 {
+		resueltas := progreso.Recolectadas + progreso.Omitidas
+		progreso.Porcentaje = math.Round(float64(resueltas)*1000/float64(progreso.TotalParadas)) / 10
+	}
+	progreso.DistanciaRestante = distanciaTotal - progreso.DistanciaRecorrida
+	if progreso.DistanciaRestante < 0 {
+		progreso.DistanciaRestante = 0
+	}
+	return progreso
+}
+
+// estimarETA calcula la hora de llegada al final de la ruta. Parte de una
+// velocidad promedio y un tiempo fijo por parada, y lo ajusta con el ritmo
+// observado desde el inicio cuando ya hay paradas atendidas.
+func estimarETA(progreso ProgresoPlan, iniciadaEn *time.Time, ahora time.Time) time.Time {
+	estimado := func(km float64, paradas int) float64 {
+		return km/velocidadPromedioKmh*60 + float64(paradas)*minutosPorParada
+	}
+
+	restante := estimado(progreso.DistanciaRestante, progreso.Pendientes+progreso.Bloqueadas)
+
+	atendidas := progreso.TotalParadas - progreso.Pendientes
+	if iniciadaEn != nil && atendidas > 0 {
+		previsto := estimado(progreso.DistanciaRecorrida, atendidas)
+		real := ahora.Sub(*iniciadaEn).Minutes()
+		if previsto > 0 && real > 0 {
+			// Se acota para que una parada muy rápida o muy lenta no distorsione todo
+			ritmo := real / previsto
+			if ritmo < 0.5 {
+				ritmo = 0.5
+			}
+			if ritmo > 3 {
+				ritmo = 3
+			}
+			restante *= ritmo
+		}
+	}
+
+	return ahora.Add(time.Duration(restante * float64(time.Minute)))
+}
+
+// MarcarParada registra lo que pasó en una parada de tacho: recolectada,
+// omitida o bloqueada. La primera parada marcada inicia la ruta y, cuando no
+// quedan paradas pendientes ni bloqueadas, la ruta se completa. Recolectar
+// un tacho deja su capacidad en 0.
+func MarcarParada(rutaID, secuencia int, estado, motivo string) (*PlanRuta, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if estado != EstadoParadaRecolectada && motivo == "" {
+		return nil, fmt.Errorf("motivo es requerido para una parada %s", estado)
+	}
+
+	var tachoRecolectado string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Bloquea la ruta para que dos marcas simultáneas no pisen el avance
+		plan, err := getPlanModelo(tx.Clauses(clause.Locking{Strength: "UPDATE"}), rutaID)
+		if err != nil {
+			return err
+		}
+		if plan.Estado != EstadoPlanificada && plan.Estado != EstadoEnCurso {
+			return fmt.Errorf("transición inválida: la ruta %d está %s", rutaID, plan.Estado)
+		}
+
+		var parada models.RutaParada
+		result := tx.Where("id_ruta = ? AND secuencia = ?", rutaID, secuencia).Limit(1).Find(&parada)
+		if result.Error != nil {
+			return fmt.Errorf("error querying parada: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("parada with secuencia %d not found", secuencia)
+		}
+		if parada.Tipo != TipoPuntoTacho {
+			return fmt.Errorf("la parada %d no es un tacho (%s)", secuencia, parada.Tipo)
+		}
+		// Una parada bloqueada se puede resolver después; las demás ya son definitivas
+		if parada.Estado != EstadoParadaPendiente && parada.Estado != EstadoParadaBloqueada || parada.Estado == estado {
+			return fmt.Errorf("transición inválida: la parada %d ya está %s", secuencia, parada.Estado)
+		}
+
+		ahora := time.Now()
+		if err := tx.Model(&models.RutaParada{}).
+			Where("id_ruta = ? AND secuencia = ?", rutaID, secuencia).
+			Updates(map[string]interface{}{"estado": estado, "motivo": motivo, "atendida_en": ahora}).Error; err != nil {
+			return fmt.Errorf("error updating parada: %v", err)
+		}
+
+		if estado == EstadoParadaRecolectada && parada.IDTacho > 0 {
+			if err := tx.Exec("UPDATE Tacho SET capacidad = 0 WHERE id_tacho = ?", parada.IDTacho).Error; err != nil {
+				return fmt.Errorf("error updating tacho capacidad: %v", err)
+			}
+			tachoRecolectado = parada.IDNeo
+		}
+
+		if plan.Estado == EstadoPlanificada {
+			if err := cambiarEstadoPlan(tx, rutaID, EstadoEnCurso, map[string]interface{}{"iniciada_en": ahora}); err != nil {
+				return err
+			}
+			plan.IniciadaEn = &ahora
+		}
+
+		return actualizarAvancePlan(tx, plan, ahora)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tachoRecolectado != "" {
+		InvalidarRutasTacho(tachoRecolectado, MotivoCapacidad)
+	}
+
+	return GetPlanRuta(rutaID)
+}
+
+// actualizarAvancePlan completa la ruta cuando no le quedan paradas por
+// atender o, si todavía le quedan, recalcula su ETA
+func actualizarAvancePlan(tx *gorm.DB, plan *models.RutaPlan, ahora time.Time) error {
+	var paradas []models.RutaParada
+	if err := tx.Where("id_ruta = ?", plan.IDRuta).Find(&paradas).Error; err != nil {
+		return fmt.Errorf("error querying paradas: %v", err)
+	}
+	progreso := calcularProgreso(paradas, plan.DistanciaTotal)
+
+	if progreso.Pendientes == 0 && progreso.Bloqueadas == 0 {
+		return cambiarEstadoPlan(tx, int(plan.IDRuta), EstadoCompletada, map[string]interface{}{
+			"finalizada_en": ahora,
+			"eta":           ahora,
+		})
+	}
+
+	eta := estimarETA(progreso, plan.IniciadaEn, ahora)
+	if err := tx.Model(&models.RutaPlan{}).Where("id_ruta = ?", plan.IDRuta).Update("eta", eta).Error; err != nil {
+		return fmt.Errorf("error updating eta: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func paradasDePrueba() []models.RutaParada {
+	return []models.RutaParada{
+		{Secuencia: 1, Tipo: TipoPuntoOrigen},
+		{Secuencia: 2, Tipo: TipoPuntoTacho, Estado: EstadoParadaRecolectada, DistanciaAcumulada: 1},
+		{Secuencia: 3, Tipo: TipoPuntoTacho, Estado: EstadoParadaBloqueada, DistanciaAcumulada: 2},
+		{Secuencia: 4, Tipo: TipoPuntoTacho, Estado: EstadoParadaPendiente, DistanciaAcumulada: 3},
+		{Secuencia: 5, Tipo: TipoPuntoTacho, Estado: EstadoParadaPendiente, DistanciaAcumulada: 4},
+	}
+}
+
+func TestCalcularProgreso(t *testing.T) {
+	progreso := calcularProgreso(paradasDePrueba(), 5)
+
+	assert.Equal(t, 4, progreso.TotalParadas, "el origen no cuenta como parada")
+	assert.Equal(t, 1, progreso.Recolectadas)
+	assert.Equal(t, 1, progreso.Bloqueadas)
+	assert.Equal(t, 2, progreso.Pendientes)
+	assert.Equal(t, 25.0, progreso.Porcentaje, "las bloqueadas no cuentan como resueltas")
+	assert.Equal(t, 2.0, progreso.DistanciaRecorrida)
+	assert.Equal(t, 3.0, progreso.DistanciaRestante)
+}
+
+func TestEstimarETA(t *testing.T) {
+	ahora := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+	progreso := ProgresoPlan{TotalParadas: 4, Recolectadas: 1, Bloqueadas: 1, Pendientes: 2,
+		DistanciaRecorrida: 2, DistanciaRestante: 3}
+
+	// Sin inicio: 3 km a 20 km/h (9 min) + 3 paradas por resolver (6 min)
+	assert.Equal(t, ahora.Add(15*time.Minute), estimarETA(progreso, nil, ahora))
+
+	// Previsto hasta ahora: 2 km (6 min) + 2 paradas (4 min) = 10 min; tardó 20, ritmo 2
+	inicio := ahora.Add(-20 * time.Minute)
+	assert.Equal(t, ahora.Add(30*time.Minute), estimarETA(progreso, &inicio, ahora))
+
+	// El ritmo se acota a 3 aunque haya tardado mucho más
+	inicio = ahora.Add(-5 * time.Hour)
+	assert.Equal(t, ahora.Add(45*time.Minute), estimarETA(progreso, &inicio, ahora))
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Formatos de salida de una ruta
+const (
+	FormatoJSON    = "json"
+	FormatoGeoJSON = "geojson"
+	FormatoGPX     = "gpx"
+	FormatoKML     = "kml"
+)
+
+// Content-Type de cada formato de exportación
+var contentTypes = map[string]string{
+	FormatoJSON:    "application/json; charset=utf-8",
+	FormatoGeoJSON: "application/geo+json; charset=utf-8",
+	FormatoGPX:     "application/gpx+xml; charset=utf-8",
+	FormatoKML:     "application/vnd.google-earth.kml+xml; charset=utf-8",
+}
+
+// NegociarFormato elige el formato de salida: el parámetro format tiene
+// prioridad; si no viene se usa el header Accept y por defecto JSON
+func NegociarFormato(formato, accept string) (string, error) {
+	if formato != "" {
+		formato = strings.ToLower(formato)
+		if _, ok := contentTypes[formato]; !ok {
+			return "", fmt.Errorf("formato desconocido: %s (opciones: %s, %s, %s, %s)",
+				formato, FormatoJSON, FormatoGeoJSON, FormatoGPX, FormatoKML)
+		}
+		return formato, nil
+	}
+
+	for _, tipo := range strings.Split(accept, ",") {
+		tipo = strings.ToLower(strings.TrimSpace(strings.Split(tipo, ";")[0]))
+		switch tipo {
+		case "application/geo+json":
+			return FormatoGeoJSON, nil
+		case "application/gpx+xml":
+			return FormatoGPX, nil
+		case "application/vnd.google-earth.kml+xml":
+			return FormatoKML, nil
+		case "application/json":
+			return FormatoJSON, nil
+		}
+	}
+	return FormatoJSON, nil
+}
+
+// ContentTypeFormato devuelve el Content-Type de un formato de exportación
+func ContentTypeFormato(formato string) string {
+	return contentTypes[formato]
+}
+
+// ExportarRuta serializa una ruta ordenada en GeoJSON, GPX o KML
+func ExportarRuta(ruta *Ruta, formato, nombre string) ([]byte, error) {
+	switch formato {
+	case FormatoGeoJSON:
+		return json.Marshal(rutaGeoJSON(ruta, nombre))
+	case FormatoGPX:
+		return marshalXML(rutaGPX(ruta, nombre))
+	case FormatoKML:
+		return marshalXML(rutaKML(ruta, nombre))
+	case FormatoJSON:
+		return json.Marshal(ruta)
+	default:
+		return nil, fmt.Errorf("formato desconocido: %s", formato)
+	}
+}
+
+// marshalXML serializa con la declaración XML al principio
+func marshalXML(v interface{}) ([]byte, error) {
+	cuerpo, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), cuerpo...), nil
+}
+
+// recorridoRuta devuelve las posiciones [lat, lng] del recorrido: la polilínea
+// si la ruta la tiene o las paradas en orden
+func recorridoRuta(ruta *Ruta) [][2]float64 {
+	if len(ruta.Polilinea) > 0 {
+		return ruta.Polilinea
+	}
+	recorrido := make([][2]float64, 0, len(ruta.Puntos))
+	for _, p := range ruta.Puntos {
+		recorrido = append(recorrido, [2]float64{p.Lat, p.Lng})
+	}
+	return recorrido
+}
+
+// propiedadesParada arma los metadatos de una parada para las exportaciones
+func propiedadesParada(p Point, secuencia int) map[string]interface{} {
+	propiedades := map[string]interface{}{
+		"secuencia":              secuencia,
+		"id":                     p.ID,
+		"tipo":                   p.Tipo,
+		"distancia_tramo_km":     p.DistanciaTramo,
+		"distancia_acumulada_km": p.DistanciaAcumulada,
+	}
+	if p.Nombre != "" {
+		propiedades["nombre"] = p.Nombre
+	}
+	if p.Tipo == TipoPuntoTacho {
+		propiedades["id_tacho"] = p.IDTacho
+		propiedades["id_neo"] = p.CustomID
+		propiedades["direccion"] = p.Direccion
+		propiedades["capacidad"] = p.Capacidad
+		propiedades["prioridad"] = p.Prioridad
+		if p.Urgencia > 0 {
+			propiedades["urgencia"] = p.Urgencia
+		}
+		if p.Volumen > 0 {
+			propiedades["volumen_litros"] = p.Volumen
+			propiedades["carga_litros"] = p.Carga
+		}
+	}
+	return propiedades
+}
+
+// nombreParada es el texto que muestran los navegadores para cada parada
+func nombreParada(p Point, secuencia int) string {
+	switch {
+	case p.Direccion != "":
+		return fmt.Sprintf("%d. %s", secuencia, p.Direccion)
+	case p.Nombre != "":
+		return fmt.Sprintf("%d. %s", secuencia, p.Nombre)
+	default:
+		return fmt.Sprintf("%d. %s %d", secuencia, p.Tipo, p.ID)
+	}
+}
+
+// descripcionParada resume los metadatos del tacho en una línea
+func descripcionParada(p Point) string {
+	if p.Tipo != TipoPuntoTacho {
+		return p.Tipo
+	}
+	return fmt.Sprintf("Dirección: %s | Capacidad: %.0f%% | Prioridad: %d", p.Direccion, p.Capacidad, p.Prioridad)
+}
+
+// featureGeoJSON es un Feature de GeoJSON
+type featureGeoJSON struct {
+	Type       string                 `json:"type"`
+	Geometry   geometriaSalida        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geometriaSalida struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type coleccionGeoJSON struct {
+	Type     string           `json:"type"`
+	Features []featureGeoJSON `json:"features"`
+}
+
+// rutaGeoJSON arma un FeatureCollection con un Point por parada y el LineString del recorrido
+func rutaGeoJSON(ruta *Ruta, nombre string) coleccionGeoJSON {
+	coleccion := coleccionGeoJSON{Type: "FeatureCollection", Features: []featureGeoJSON{}}
+
+	for i, p := range ruta.Puntos {
+		coleccion.Features = append(coleccion.Features, featureGeoJSON{
+			Type:       "Feature",
+			Geometry:   geometriaSalida{Type: "Point", Coordinates: [2]float64{p.Lng, p.Lat}},
+			Properties: propiedadesParada(p, i+1),
+		})
+	}
+
+	// GeoJSON usa [lng, lat]
+	linea := [][2]float64{}
+	for _, pos := range recorridoRuta(ruta) {
+		linea = append(linea, [2]float64{pos[1], pos[0]})
+	}
+	coleccion.Features = append(coleccion.Features, featureGeoJSON{
+		Type:     "Feature",
+		Geometry: geometriaSalida{Type: "LineString", Coordinates: linea},
+		Properties: map[string]interface{}{
+			"nombre":             nombre,
+			"algoritmo":          ruta.Algoritmo,
+			"distancia_total_km": ruta.DistanciaTotal,
+			"tipo_distancia":     ruta.TipoDistancia,
+			"paradas":            len(ruta.Puntos),
+		},
+	})
+
+	return coleccion
+}
+
+// Estructuras GPX 1.1
+type gpxDoc struct {
+	XMLName  xml.Name    `xml:"gpx"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Version  string      `xml:"version,attr"`
+	Creator  string      `xml:"creator,attr"`
+	Metadata gpxMetadata `xml:"metadata"`
+	Waypoint []gpxPunto  `xml:"wpt"`
+	Ruta     gpxRuta     `xml:"rte"`
+	Track    gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Desc string `xml:"desc,omitempty"`
+}
+
+type gpxPunto struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Name string  `xml:"name,omitempty"`
+	Cmt  string  `xml:"cmt,omitempty"`
+	Desc string  `xml:"desc,omitempty"`
+	Type string  `xml:"type,omitempty"`
+}
+
+type gpxRuta struct {
+	Name   string     `xml:"name"`
+	Puntos []gpxPunto `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name     string     `xml:"name"`
+	Segmento gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Puntos []gpxPunto `xml:"trkpt"`
+}
+
+// rutaGPX arma un GPX con las paradas como waypoints y ruta, y el recorrido como track
+func rutaGPX(ruta *Ruta, nombre string) gpxDoc {
+	doc := gpxDoc{
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Version: "1.1",
+		Creator: "binService",
+		Metadata: gpxMetadata{
+			Name: nombre,
+			Desc: fmt.Sprintf("Algoritmo: %s | Distancia: %.2f km (%s)", ruta.Algoritmo, ruta.DistanciaTotal, ruta.TipoDistancia),
+		},
+		Ruta:  gpxRuta{Name: nombre},
+		Track: gpxTrack{Name: nombre},
+	}
+
+	for i, p := range ruta.Puntos {
+		parada := gpxPunto{
+			Lat:  p.Lat,
+			Lon:  p.Lng,
+			Name: nombreParada(p, i+1),
+			Desc: descripcionParada(p),
+			Type: p.Tipo,
+		}
+		if p.Tipo == TipoPuntoTacho {
+			parada.Cmt = p.Direccion
+		}
+		doc.Waypoint = append(doc.Waypoint, parada)
+		doc.Ruta.Puntos = append(doc.Ruta.Puntos, parada)
+	}
+
+	for _, pos := range recorridoRuta(ruta) {
+		doc.Track.Segmento.Puntos = append(doc.Track.Segmento.Puntos, gpxPunto{Lat: pos[0], Lon: pos[1]})
+	}
+
+	return doc
+}
+
+// Estructuras KML 2.2
+type kmlDoc struct {
+	XMLName   xml.Name     `xml:"kml"`
+	Xmlns     string       `xml:"xmlns,attr"`
+	Documento kmlDocumento `xml:"Document"`
+}
+
+type kmlDocumento struct {
+	Name       string         `xml:"name"`
+	Desc       string         `xml:"description,omitempty"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name       string        `xml:"name"`
+	Desc       string        `xml:"description,omitempty"`
+	Extended   *kmlExtendido `xml:"ExtendedData,omitempty"`
+	Punto      *kmlGeometria `xml:"Point,omitempty"`
+	LineString *kmlGeometria `xml:"LineString,omitempty"`
+}
+
+type kmlExtendido struct {
+	Datos []kmlDato `xml:"Data"`
+}
+
+type kmlDato struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlGeometria struct {
+	Tessellate int    `xml:"tessellate,omitempty"`
+	Coords     string `xml:"coordinates"`
+}
+
+// rutaKML arma un KML con un Placemark por parada y otro con la línea del recorrido
+func rutaKML(ruta *Ruta, nombre string) kmlDoc {
+	doc := kmlDoc{
+		Xmlns: "http://www.opengis.net/kml/2.2",
+		Documento: kmlDocumento{
+			Name: nombre,
+			Desc: fmt.Sprintf("Algoritmo: %s | Distancia: %.2f km (%s)", ruta.Algoritmo, ruta.DistanciaTotal, ruta.TipoDistancia),
+		},
+	}
+
+	for i, p := range ruta.Puntos {
+		propiedades := propiedadesParada(p, i+1)
+		extendido := &kmlExtendido{}
+		for _, clave := range []string{"secuencia", "tipo", "id_tacho", "id_neo", "direccion", "capacidad", "prioridad", "distancia_acumulada_km"} {
+			if valor, ok := propiedades[clave]; ok {
+				extendido.Datos = append(extendido.Datos, kmlDato{Name: clave, Value: fmt.Sprintf("%v", valor)})
+			}
+		}
+		doc.Documento.Placemarks = append(doc.Documento.Placemarks, kmlPlacemark{
+			Name:     nombreParada(p, i+1),
+			Desc:     descripcionParada(p),
+			Extended: extendido,
+			Punto:    &kmlGeometria{Coords: fmt.Sprintf("%f,%f", p.Lng, p.Lat)},
+		})
+	}
+
+	// KML usa lng,lat separados por espacios
+	coords := []string{}
+	for _, pos := range recorridoRuta(ruta) {
+		coords = append(coords, fmt.Sprintf("%f,%f", pos[1], pos[0]))
+	}
+	doc.Documento.Placemarks = append(doc.Documento.Placemarks, kmlPlacemark{
+		Name:       nombre,
+		LineString: &kmlGeometria{Tessellate: 1, Coords: strings.Join(coords, " ")},
+	})
+
+	return doc
+}
+
+// ExportarFlotaGeoJSON serializa las rutas de todos los camiones en un único
+// FeatureCollection; cada feature lleva el id_camion de su ruta
+func ExportarFlotaGeoJSON(flota *RutasFlota) ([]byte, error) {
+	coleccion := coleccionGeoJSON{Type: "FeatureCollection", Features: []featureGeoJSON{}}
+	for _, ruta := range flota.Rutas {
+		parcial := rutaGeoJSON(&ruta.Ruta, fmt.Sprintf("Camión %d", ruta.IDCamion))
+		for _, feature := range parcial.Features {
+			feature.Properties["id_camion"] = ruta.IDCamion
+			coleccion.Features = append(coleccion.Features, feature)
+		}
+	}
+	return json.Marshal(coleccion)
+}
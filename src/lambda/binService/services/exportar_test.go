@@ -0,0 +1,85 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rutaDeEjemplo() *Ruta {
+	return &Ruta{
+		Algoritmo: AlgoritmoCompleto,
+		Puntos: []Point{
+			{ID: 3, Tipo: TipoPuntoOrigen, Nombre: "Depósito", Lat: -34.60, Lng: -58.40},
+			{ID: 1, Tipo: TipoPuntoTacho, Direccion: "Av. Corrientes 1234", Capacidad: 80, Prioridad: 4, Lat: -34.61, Lng: -58.41},
+		},
+		DistanciaTotal: 1.4,
+		Polilinea:      [][2]float64{{-34.60, -58.40}, {-34.605, -58.40}, {-34.61, -58.41}},
+	}
+}
+
+func TestNegociarFormato(t *testing.T) {
+	formato, err := NegociarFormato("GPX", "application/geo+json")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatoGPX, formato, "el parámetro tiene prioridad sobre Accept")
+
+	formato, _ = NegociarFormato("", "text/html, application/vnd.google-earth.kml+xml;q=0.9")
+	assert.Equal(t, FormatoKML, formato)
+
+	formato, _ = NegociarFormato("", "*/*")
+	assert.Equal(t, FormatoJSON, formato)
+
+	_, err = NegociarFormato("shp", "")
+	assert.Error(t, err)
+}
+
+func TestExportarRuta_GeoJSON(t *testing.T) {
+	data, err := ExportarRuta(rutaDeEjemplo(), FormatoGeoJSON, "ruta-zona-1")
+	assert.NoError(t, err)
+
+	var coleccion struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &coleccion))
+	assert.Equal(t, "FeatureCollection", coleccion.Type)
+	if assert.Len(t, coleccion.Features, 3) {
+		tacho := coleccion.Features[1]
+		assert.Equal(t, "Point", tacho.Geometry.Type)
+		assert.JSONEq(t, `[-58.41,-34.61]`, string(tacho.Geometry.Coordinates))
+		assert.Equal(t, "Av. Corrientes 1234", tacho.Properties["direccion"])
+		assert.Equal(t, float64(80), tacho.Properties["capacidad"])
+		assert.Equal(t, float64(4), tacho.Properties["prioridad"])
+
+		linea := coleccion.Features[2]
+		assert.Equal(t, "LineString", linea.Geometry.Type)
+		assert.JSONEq(t, `[[-58.4,-34.6],[-58.4,-34.605],[-58.41,-34.61]]`, string(linea.Geometry.Coordinates))
+	}
+}
+
+func TestExportarRuta_GPXyKML(t *testing.T) {
+	gpx, err := ExportarRuta(rutaDeEjemplo(), FormatoGPX, "ruta-zona-1")
+	assert.NoError(t, err)
+	texto := string(gpx)
+	assert.True(t, strings.HasPrefix(texto, "<?xml"))
+	assert.Equal(t, 2, strings.Count(texto, "<wpt "))
+	assert.Equal(t, 2, strings.Count(texto, "<rtept "))
+	assert.Equal(t, 3, strings.Count(texto, "<trkpt "))
+	assert.Contains(t, texto, "<name>2. Av. Corrientes 1234</name>")
+	assert.Contains(t, texto, "Capacidad: 80% | Prioridad: 4")
+
+	kml, err := ExportarRuta(rutaDeEjemplo(), FormatoKML, "ruta-zona-1")
+	assert.NoError(t, err)
+	texto = string(kml)
+	assert.Equal(t, 3, strings.Count(texto, "<Placemark>"))
+	assert.Contains(t, texto, `<Data name="direccion">`)
+	assert.Contains(t, texto, "-58.410000,-34.610000")
+}
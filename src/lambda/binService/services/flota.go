@@ -0,0 +1,417 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+)
+
+// maxMovimientosBalanceo limita las iteraciones del balanceo entre camiones
+const maxMovimientosBalanceo = 200
+
+// toleranciaTachos es la diferencia de tachos permitida entre camiones al balancear
+const toleranciaTachos = 1
+
+// RutaCamion representa la ruta asignada a un camión dentro de una zona
+type RutaCamion struct {
+	IDCamion   int `json:"id_camion"`
+	TipoCamion int `json:"tipo_camion"`
+	Tachos     int `json:"cantidad_tachos"`
+	Ruta
+}
+
+// RutasFlota representa el reparto de una zona entre varios camiones
+type RutasFlota struct {
+	ZonaID          int          `json:"zona_id"`
+	Algoritmo       string       `json:"algoritmo"`
+	Camiones        int          `json:"camiones"`
+	Rutas           []RutaCamion `json:"rutas"`
+	DistanciaTotal  float64      `json:"distancia_total_km"`
+	DistanciaMaxima float64      `json:"distancia_maxima_km"`
+	Omitidos        int          `json:"tachos_omitidos,omitempty"`
+}
+
+// GetRutasFlota reparte los tachos de una zona entre los camiones operativos
+// asignados a la zona (Zona_camion); si la zona no tiene camiones asignados
+// usa todos los operativos. maxCamiones limita la cantidad de camiones a usar
+// (0 = todos).
+func GetRutasFlota(zonaID int, opciones RutaOpciones, maxCamiones int) (*RutasFlota, error) {
+	algoritmo, err := ValidarAlgoritmo(opciones.Algoritmo)
+	if err != nil {
+		return nil, err
+	}
+	opciones.Algoritmo = algoritmo
+
+	camiones, err := config.GetCamionesOperativos()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo camiones operativos: %v", err)
+	}
+	asignados, err := getCamionesAsignados(zonaID)
+	if err != nil {
+		return nil, err
+	}
+	camiones = filtrarCamionesZona(camiones, asignados)
+	if len(camiones) == 0 {
+		if len(asignados) > 0 {
+			return nil, fmt.Errorf("no hay camiones operativos asignados a la zona %d", zonaID)
+		}
+		return nil, fmt.Errorf("no hay camiones operativos")
+	}
+	if maxCamiones > 0 && maxCamiones < len(camiones) {
+		camiones = camiones[:maxCamiones]
+	}
+
+	points, err := getTachoPointsByZona(zonaID)
+	if err != nil {
+		return nil, err
+	}
+	opciones.matriz = matrizParaRuta(zonaID, points)
+
+	// Control de capacidad según el tipo de cada camión
+	tipos, err := getTiposCamion()
+	if err != nil {
+		return nil, err
+	}
+	centros, err := getCentrosDescarga()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo centros de descarga: %v", err)
+	}
+
+	flota := armarFlota(points, camiones, tipos, centros, opciones)
+	flota.ZonaID = zonaID
+	return flota, nil
+}
+
+// getCamionesAsignados devuelve los camiones asignados a la zona
+func getCamionesAsignados(zonaID int) ([]int, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var asignados []int
+	if err := config.DB.Raw("SELECT id_camion FROM Zona_camion WHERE id_zona = ? ORDER BY id_camion", zonaID).
+		Scan(&asignados).Error; err != nil {
+		return nil, fmt.Errorf("error querying camiones de la zona: %v", err)
+	}
+	return asignados, nil
+}
+
+// filtrarCamionesZona deja los camiones operativos asignados a la zona. Sin
+// camiones asignados la zona puede usar cualquiera.
+func filtrarCamionesZona(camiones []config.CamionOperativo, asignados []int) []config.CamionOperativo {
+	if len(asignados) == 0 {
+		return camiones
+	}
+
+	deLaZona := make(map[int]bool, len(asignados))
+	for _, id := range asignados {
+		deLaZona[id] = true
+	}
+	filtrados := []config.CamionOperativo{}
+	for _, camion := range camiones {
+		if deLaZona[camion.ID] {
+			filtrados = append(filtrados, camion)
+		}
+	}
+	return filtrados
+}
+
+// armarFlota selecciona los tachos, los reparte entre los camiones y aplica a
+// cada ruta la capacidad de su tipo de camión. No consulta ninguna base.
+func armarFlota(points []Point, camiones []config.CamionOperativo, tipos map[int]TipoCamion, centros []Centro, opciones RutaOpciones) *RutasFlota {
+	points, omitidos := SeleccionarParadas(points, opciones.MinCapacidad)
+	rutas := RepartirTachos(points, camiones, opciones)
+
+	for i := range rutas {
+		tipo := tipoCamionOrDefault(tipos, rutas[i].TipoCamion)
+		rutas[i].Ruta = *AplicarCapacidad(&rutas[i].Ruta, tipo.CapacidadLitros, centrosCompatibles(centros, tipo.NombreTipo))
+		completarRecorrido(&rutas[i].Ruta)
+	}
+
+	flota := &RutasFlota{
+		Algoritmo: opciones.Algoritmo,
+		Camiones:  len(rutas),
+		Rutas:     rutas,
+		Omitidos:  omitidos,
+	}
+	for _, ruta := range rutas {
+		flota.DistanciaTotal += ruta.DistanciaTotal
+		flota.DistanciaMaxima = math.Max(flota.DistanciaMaxima, ruta.DistanciaTotal)
+	}
+
+	return flota
+}
+
+// RepartirTachos divide los tachos entre los camiones con un barrido angular,
+// optimiza cada ruta y luego mueve tachos desde la ruta más larga mientras se
+// reduzca la distancia máxima sin desbalancear la cantidad de tachos. Las
+// distancias salen de una sola matriz calculada al principio.
+func RepartirTachos(points []Point, camiones []config.CamionOperativo, opciones RutaOpciones) []RutaCamion {
+	k := len(camiones)
+	if len(points) < k {
+		k = len(points)
+	}
+	if k == 0 {
+		return []RutaCamion{}
+	}
+
+	b := nuevoBalanceo(points, opciones)
+	grupos := b.indicesDe(barridoAngular(points, k, opciones.Origen))
+
+	// Límite de tachos por camión para mantener el balance
+	maxTachos := (len(points)+k-1)/k + toleranciaTachos
+
+	largos := make([]float64, k)
+	for i := range grupos {
+		grupos[i] = b.ordenar(grupos[i])
+		largos[i] = b.longitud(grupos[i])
+	}
+	for movimientos := 0; movimientos < maxMovimientosBalanceo; movimientos++ {
+		if !b.moverDesdeRutaMasLarga(grupos, largos, maxTachos) {
+			break
+		}
+	}
+
+	rutas := make([]RutaCamion, k)
+	for i, grupo := range grupos {
+		ruta := ordenarParadas(b.puntos(grupo), opciones)
+		rutas[i] = RutaCamion{
+			IDCamion:   camiones[i].ID,
+			TipoCamion: camiones[i].Tipo,
+			Tachos:     len(grupo),
+			Ruta:       *ruta,
+		}
+	}
+
+	return rutas
+}
+
+// barridoAngular ordena los tachos por ángulo alrededor del origen (o del
+// centroide) y los corta en k grupos contiguos de tamaño parejo. Devuelve las
+// posiciones de los tachos en points.
+func barridoAngular(points []Point, k int, origen *Point) [][]int {
+	centro := centroide(points)
+	if origen != nil {
+		centro = *origen
+	}
+
+	ordenados := make([]int, len(points))
+	for i := range ordenados {
+		ordenados[i] = i
+	}
+	angulo := func(i int) float64 {
+		return math.Atan2(points[i].Lat-centro.Lat, points[i].Lng-centro.Lng)
+	}
+	sort.SliceStable(ordenados, func(i, j int) bool {
+		return angulo(ordenados[i]) < angulo(ordenados[j])
+	})
+
+	// Empezar el barrido en el mayor hueco angular para no partir un grupo natural
+	inicio := 0
+	mayorHueco := -1.0
+	for i := range ordenados {
+		anterior := ordenados[(i-1+len(ordenados))%len(ordenados)]
+		hueco := angulo(ordenados[i]) - angulo(anterior)
+		if hueco <= 0 {
+			hueco += 2 * math.Pi
+		}
+		if hueco > mayorHueco {
+			mayorHueco = hueco
+			inicio = i
+		}
+	}
+	ordenados = append(ordenados[inicio:], ordenados[:inicio]...)
+
+	grupos := make([][]int, k)
+	desde := 0
+	for i := 0; i < k; i++ {
+		largo := len(ordenados) / k
+		if i < len(ordenados)%k {
+			largo++
+		}
+		grupos[i] = ordenados[desde : desde+largo]
+		desde += largo
+	}
+
+	return grupos
+}
+
+// centroide calcula el punto medio de un conjunto de puntos
+func centroide(points []Point) Point {
+	var c Point
+	if len(points) == 0 {
+		return c
+	}
+	for _, p := range points {
+		c.Lat += p.Lat
+		c.Lng += p.Lng
+	}
+	c.Lat /= float64(len(points))
+	c.Lng /= float64(len(points))
+	return c
+}
+
+// balanceo guarda la matriz de distancias del reparto. Los nodos son el origen
+// (si hay), los tachos y el destino (si hay); los grupos son índices de nodos
+// de tachos en orden de visita.
+type balanceo struct {
+	nodos     []Point
+	dist      [][]float64
+	algoritmo string
+	origen    int
+	destino   int
+}
+
+// nuevoBalanceo arma la matriz una sola vez, reutilizando la de la zona si está
+func nuevoBalanceo(points []Point, opciones RutaOpciones) *balanceo {
+	b := &balanceo{algoritmo: opciones.Algoritmo, origen: -1, destino: -1}
+	if opciones.Origen != nil {
+		b.origen = len(b.nodos)
+		b.nodos = append(b.nodos, *opciones.Origen)
+	}
+	b.nodos = append(b.nodos, points...)
+	if opciones.Destino != nil {
+		b.destino = len(b.nodos)
+		b.nodos = append(b.nodos, *opciones.Destino)
+	}
+	b.dist = matrizDistanciasCon(b.nodos, opciones.matriz)
+	return b
+}
+
+// indicesDe traduce los grupos del barrido (posiciones en points) a nodos
+func (b *balanceo) indicesDe(grupos [][]int) [][]int {
+	desplazamiento := 0
+	if b.origen >= 0 {
+		desplazamiento = 1
+	}
+	for _, grupo := range grupos {
+		for i := range grupo {
+			grupo[i] += desplazamiento
+		}
+	}
+	return grupos
+}
+
+// puntos devuelve los tachos de un grupo
+func (b *balanceo) puntos(grupo []int) []Point {
+	puntos := make([]Point, len(grupo))
+	for i, nodo := range grupo {
+		puntos[i] = b.nodos[nodo]
+	}
+	return puntos
+}
+
+// recorrido agrega origen y destino a un grupo
+func (b *balanceo) recorrido(grupo []int) []int {
+	recorrido := make([]int, 0, len(grupo)+2)
+	if b.origen >= 0 {
+		recorrido = append(recorrido, b.origen)
+	}
+	recorrido = append(recorrido, grupo...)
+	if b.destino >= 0 {
+		recorrido = append(recorrido, b.destino)
+	}
+	return recorrido
+}
+
+// longitud calcula la distancia de un grupo ya ordenado incluyendo origen y destino
+func (b *balanceo) longitud(grupo []int) float64 {
+	return longitudRecorrido(b.dist, b.recorrido(grupo))
+}
+
+// ordenar optimiza el orden de un grupo sobre la submatriz de sus nodos
+func (b *balanceo) ordenar(grupo []int) []int {
+	nodos := b.recorrido(grupo)
+	sub := make([][]float64, len(nodos))
+	for i, desde := range nodos {
+		sub[i] = make([]float64, len(nodos))
+		for j, hasta := range nodos {
+			sub[i][j] = b.dist[desde][hasta]
+		}
+	}
+
+	orden := ordenarRecorrido(sub, b.algoritmo, b.destino >= 0)
+	ordenado := make([]int, 0, len(grupo))
+	for _, i := range orden {
+		if nodos[i] != b.origen && nodos[i] != b.destino {
+			ordenado = append(ordenado, nodos[i])
+		}
+	}
+	return ordenado
+}
+
+// moverDesdeRutaMasLarga intenta pasar un tacho de la ruta más larga a otra ruta
+// de forma que baje la distancia máxima. Solo reordena los dos grupos que
+// cambian. Devuelve true si hizo un movimiento.
+func (b *balanceo) moverDesdeRutaMasLarga(grupos [][]int, largos []float64, maxTachos int) bool {
+	masLarga := 0
+	for i := range largos {
+		if largos[i] > largos[masLarga] {
+			masLarga = i
+		}
+	}
+	if len(grupos[masLarga]) <= 1 {
+		return false
+	}
+
+	mejorMax := largos[masLarga]
+	mejorTacho, mejorDestino, mejorPos := -1, -1, -1
+
+	for t := range grupos[masLarga] {
+		largoSin := b.longitud(quitarIndice(grupos[masLarga], t))
+
+		for g := range grupos {
+			if g == masLarga || len(grupos[g])+1 > maxTachos {
+				continue
+			}
+			pos, largoCon := b.mejorInsercion(grupos[g], grupos[masLarga][t])
+			nuevoMax := math.Max(largoSin, largoCon)
+			if nuevoMax < mejorMax-epsilon {
+				mejorMax = nuevoMax
+				mejorTacho, mejorDestino, mejorPos = t, g, pos
+			}
+		}
+	}
+
+	if mejorTacho == -1 {
+		return false
+	}
+
+	nodo := grupos[masLarga][mejorTacho]
+	grupos[masLarga] = b.ordenar(quitarIndice(grupos[masLarga], mejorTacho))
+	grupos[mejorDestino] = b.ordenar(insertarIndice(grupos[mejorDestino], mejorPos, nodo))
+	largos[masLarga] = b.longitud(grupos[masLarga])
+	largos[mejorDestino] = b.longitud(grupos[mejorDestino])
+	return true
+}
+
+// mejorInsercion busca la posición que menos alarga el grupo al agregar el nodo
+func (b *balanceo) mejorInsercion(grupo []int, nodo int) (int, float64) {
+	mejorPos := 0
+	mejorLargo := math.Inf(1)
+	for pos := 0; pos <= len(grupo); pos++ {
+		largo := b.longitud(insertarIndice(grupo, pos, nodo))
+		if largo < mejorLargo {
+			mejorLargo = largo
+			mejorPos = pos
+		}
+	}
+	return mejorPos, mejorLargo
+}
+
+// quitarIndice devuelve una copia del grupo sin el nodo en la posición i
+func quitarIndice(grupo []int, i int) []int {
+	nuevo := make([]int, 0, len(grupo)-1)
+	nuevo = append(nuevo, grupo[:i]...)
+	return append(nuevo, grupo[i+1:]...)
+}
+
+// insertarIndice devuelve una copia del grupo con el nodo en la posición pos
+func insertarIndice(grupo []int, pos int, nodo int) []int {
+	nuevo := make([]int, 0, len(grupo)+1)
+	nuevo = append(nuevo, grupo[:pos]...)
+	nuevo = append(nuevo, nodo)
+	return append(nuevo, grupo[pos:]...)
+}
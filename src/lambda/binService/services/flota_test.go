@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// puntosDosGrupos arma dos grupos de tachos separados, uno al norte y otro al sur
+func puntosDosGrupos() []Point {
+	points := []Point{}
+	for i := 0; i < 5; i++ {
+		offset := float64(i) * 0.001
+		points = append(points,
+			Point{ID: i + 1, Lat: -34.58, Lng: -58.45 + offset},
+			Point{ID: i + 11, Lat: -34.62, Lng: -58.45 + offset},
+		)
+	}
+	return points
+}
+
+func TestRepartirTachos_UnaRutaPorCamion(t *testing.T) {
+	points := puntosDosGrupos()
+	camiones := []config.CamionOperativo{{ID: 7, Estado: 1, Tipo: 1}, {ID: 9, Estado: 1, Tipo: 2}}
+
+	rutas := RepartirTachos(points, camiones, RutaOpciones{Algoritmo: AlgoritmoCompleto})
+
+	assert.Len(t, rutas, 2)
+	assert.Equal(t, 7, rutas[0].IDCamion)
+	assert.Equal(t, 9, rutas[1].IDCamion)
+
+	// Todos los tachos quedan asignados exactamente una vez
+	vistos := map[int]int{}
+	for _, ruta := range rutas {
+		assert.Equal(t, len(ruta.Puntos), ruta.Tachos)
+		assert.InDelta(t, 5, ruta.Tachos, float64(toleranciaTachos))
+		for _, p := range ruta.Puntos {
+			vistos[p.ID]++
+		}
+	}
+	assert.Len(t, vistos, len(points))
+	for id, veces := range vistos {
+		assert.Equal(t, 1, veces, "tacho %d asignado más de una vez", id)
+	}
+
+	// Cada camión se queda con un grupo: no cruza de norte a sur
+	for _, ruta := range rutas {
+		for _, p := range ruta.Puntos {
+			assert.Equal(t, ruta.Puntos[0].Lat, p.Lat)
+		}
+	}
+}
+
+func TestRepartirTachos_MasCamionesQueTachos(t *testing.T) {
+	points := []Point{{ID: 1, Lat: -34.6, Lng: -58.4}}
+	camiones := []config.CamionOperativo{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	rutas := RepartirTachos(points, camiones, RutaOpciones{Algoritmo: AlgoritmoCompleto})
+
+	assert.Len(t, rutas, 1)
+	assert.Empty(t, RepartirTachos([]Point{}, camiones, RutaOpciones{Algoritmo: AlgoritmoCompleto}))
+}
+
+func TestRepartirTachos_ConOrigenYDestino(t *testing.T) {
+	origen := &Point{Tipo: TipoPuntoOrigen, Lat: -34.60, Lng: -58.46}
+	destino := &Point{Tipo: TipoPuntoDestino, Lat: -34.60, Lng: -58.44}
+	camiones := []config.CamionOperativo{{ID: 1}, {ID: 2}}
+
+	rutas := RepartirTachos(puntosDosGrupos(), camiones, RutaOpciones{
+		Algoritmo: AlgoritmoCompleto,
+		Origen:    origen,
+		Destino:   destino,
+	})
+
+	for _, ruta := range rutas {
+		assert.Equal(t, TipoPuntoOrigen, ruta.Puntos[0].Tipo)
+		assert.Equal(t, TipoPuntoDestino, ruta.Puntos[len(ruta.Puntos)-1].Tipo)
+		assert.Equal(t, len(ruta.Puntos)-2, ruta.Tachos)
+	}
+}
+
+func TestFiltrarCamionesZona(t *testing.T) {
+	camiones := []config.CamionOperativo{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	assert.Equal(t, camiones, filtrarCamionesZona(camiones, nil), "sin asignados se usan todos")
+	assert.Equal(t, []config.CamionOperativo{{ID: 1}, {ID: 3}}, filtrarCamionesZona(camiones, []int{3, 1}))
+	assert.Empty(t, filtrarCamionesZona(camiones, []int{4}), "asignados no operativos no caen en todos")
+}
+
+func TestBalanceoOrdenaConSuMatriz(t *testing.T) {
+	// Sin origen ni destino: la matriz hace que 0-2-1 sea el único recorrido corto
+	b := &balanceo{
+		nodos:     make([]Point, 3),
+		algoritmo: AlgoritmoCompleto,
+		origen:    -1,
+		destino:   -1,
+		dist: [][]float64{
+			{0, 10, 1},
+			{10, 0, 1},
+			{1, 1, 0},
+		},
+	}
+
+	grupo := b.ordenar([]int{0, 1, 2})
+	assert.Equal(t, 2, grupo[1], "el nodo 2 va en el medio")
+	assert.InDelta(t, 2.0, b.longitud(grupo), 1e-9)
+
+	pos, largo := b.mejorInsercion([]int{0, 1}, 2)
+	assert.Equal(t, 1, pos)
+	assert.InDelta(t, 2.0, largo, 1e-9)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// poligono es una lista de anillos (el primero es el borde exterior, el resto
+// son huecos); cada anillo es una lista de posiciones [lng, lat]
+type poligono [][][2]float64
+
+// geometriaGeoJSON representa una geometría o un Feature de GeoJSON
+type geometriaGeoJSON struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates,omitempty"`
+	Geometry    *geometriaGeoJSON `json:"geometry,omitempty"`
+}
+
+// parseLimite interpreta un GeoJSON Polygon, MultiPolygon o un Feature que los
+// contenga y devuelve sus polígonos
+func parseLimite(raw json.RawMessage) ([]poligono, error) {
+	var geometria geometriaGeoJSON
+	if err := json.Unmarshal(raw, &geometria); err != nil {
+		return nil, fmt.Errorf("GeoJSON inválido: %v", err)
+	}
+
+	if geometria.Type == "Feature" {
+		if geometria.Geometry == nil {
+			return nil, fmt.Errorf("GeoJSON inválido: Feature sin geometry")
+		}
+		geometria = *geometria.Geometry
+	}
+
+	var poligonos []poligono
+	switch geometria.Type {
+	case "Polygon":
+		var p poligono
+		if err := json.Unmarshal(geometria.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("coordenadas de Polygon inválidas: %v", err)
+		}
+		poligonos = []poligono{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometria.Coordinates, &poligonos); err != nil {
+			return nil, fmt.Errorf("coordenadas de MultiPolygon inválidas: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("tipo de geometría no soportado: %q (se espera Polygon o MultiPolygon)", geometria.Type)
+	}
+
+	if len(poligonos) == 0 {
+		return nil, fmt.Errorf("el límite no tiene polígonos")
+	}
+	for _, p := range poligonos {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("polígono sin anillos")
+		}
+		for _, anillo := range p {
+			if len(anillo) < 4 {
+				return nil, fmt.Errorf("cada anillo debe tener al menos 4 posiciones")
+			}
+			if anillo[0] != anillo[len(anillo)-1] {
+				return nil, fmt.Errorf("cada anillo debe estar cerrado (primera posición igual a la última)")
+			}
+		}
+	}
+
+	return poligonos, nil
+}
+
+// contienePunto indica si la posición está dentro de alguno de los polígonos.
+// Un punto dentro de un hueco queda afuera.
+func contienePunto(poligonos []poligono, lat, lng float64) bool {
+	for _, p := range poligonos {
+		if !dentroDeAnillo(p[0], lat, lng) {
+			continue
+		}
+		enHueco := false
+		for _, hueco := range p[1:] {
+			if dentroDeAnillo(hueco, lat, lng) {
+				enHueco = true
+				break
+			}
+		}
+		if !enHueco {
+			return true
+		}
+	}
+	return false
+}
+
+// dentroDeAnillo aplica ray casting sobre un anillo de posiciones [lng, lat]
+func dentroDeAnillo(anillo [][2]float64, lat, lng float64) bool {
+	dentro := false
+	for i, j := 0, len(anillo)-1; i < len(anillo); j, i = i, i+1 {
+		xi, yi := anillo[i][0], anillo[i][1]
+		xj, yj := anillo[j][0], anillo[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			dentro = !dentro
+		}
+	}
+	return dentro
+}
+
+// featureLimite es un Feature de un FeatureCollection de límites de zonas
+type featureLimite struct {
+	Type       string          `json:"type"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties struct {
+		IDZona int    `json:"id_zona"`
+		Nombre string `json:"nombre"`
+	} `json:"properties"`
+}
+
+// coleccionLimites es un FeatureCollection con un Feature por zona
+type coleccionLimites struct {
+	Type     string          `json:"type"`
+	Features []featureLimite `json:"features"`
+}
+
+// parseColeccionLimites interpreta un FeatureCollection de límites de zonas
+func parseColeccionLimites(raw json.RawMessage) (*coleccionLimites, error) {
+	var coleccion coleccionLimites
+	if err := json.Unmarshal(raw, &coleccion); err != nil {
+		return nil, fmt.Errorf("GeoJSON inválido: %v", err)
+	}
+	if coleccion.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("GeoJSON inválido: se espera un FeatureCollection")
+	}
+	if len(coleccion.Features) == 0 {
+		return nil, fmt.Errorf("el FeatureCollection no tiene features")
+	}
+
+	for i, feature := range coleccion.Features {
+		if feature.Properties.IDZona <= 0 && feature.Properties.Nombre == "" {
+			return nil, fmt.Errorf("feature %d: properties debe incluir id_zona o nombre", i)
+		}
+		if _, err := parseLimite(feature.Geometry); err != nil {
+			return nil, fmt.Errorf("feature %d: %v", i, err)
+		}
+	}
+	return &coleccion, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimite(t *testing.T) {
+	poligono := `{"type":"Polygon","coordinates":[[[-58.45,-34.59],[-58.44,-34.59],[-58.44,-34.58],[-58.45,-34.59]]]}`
+	poligonos, err := parseLimite(json.RawMessage(poligono))
+	assert.NoError(t, err)
+	assert.Len(t, poligonos, 1)
+
+	feature := `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[2,2],[3,2],[3,3],[2,2]]]]}}`
+	poligonos, err = parseLimite(json.RawMessage(feature))
+	assert.NoError(t, err)
+	assert.Len(t, poligonos, 2)
+
+	_, err = parseLimite(json.RawMessage(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`))
+	assert.Error(t, err, "anillo sin cerrar")
+
+	_, err = parseLimite(json.RawMessage(`{"type":"Point","coordinates":[0,0]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// MatrizZona guarda las distancias y duraciones entre todos los tachos de una
+// zona. Filas y columnas siguen el orden de Tachos; la distancia puede ser
+// asimétrica si se calculó sobre la red de calles.
+type MatrizZona struct {
+	IDZona        int          `json:"id_zona"`
+	TipoDistancia string       `json:"tipo_distancia"`
+	Tachos        []string     `json:"tachos"`
+	Posiciones    [][2]float64 `json:"posiciones"`
+	DistanciasKm  [][]float64  `json:"distancias_km"`
+	DuracionesMin [][]float64  `json:"duraciones_min"`
+	ActualizadaEn time.Time    `json:"actualizada_en"`
+}
+
+// matrizCacheKey arma la clave de la matriz de una zona en Redis
+func matrizCacheKey(zonaID int) string {
+	return fmt.Sprintf("matriz:zona:%d", zonaID)
+}
+
+// duracionMinutos estima el tiempo de manejo para una distancia
+func duracionMinutos(km float64) float64 {
+	return km / velocidadPromedioKmh * 60
+}
+
+// indice devuelve la fila del tacho en la matriz o -1 si no está o se movió
+func (m *MatrizZona) indice(p Point) int {
+	if p.Tipo != TipoPuntoTacho || p.CustomID == "" {
+		return -1
+	}
+	for i, id := range m.Tachos {
+		if id == p.CustomID {
+			if m.Posiciones[i] != [2]float64{p.Lat, p.Lng} {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// sincronizar pone la matriz al día con los tachos actuales de la zona: quita
+// los que ya no están y agrega los nuevos o movidos. Devuelve si hubo cambios.
+func (m *MatrizZona) sincronizar(points []Point) bool {
+	actuales := make(map[string]bool, len(points))
+	for _, p := range points {
+		actuales[p.CustomID] = true
+	}
+
+	cambio := false
+	for _, id := range append([]string{}, m.Tachos...) {
+		if !actuales[id] {
+			cambio = m.quitarTacho(id) || cambio
+		}
+	}
+	for _, p := range points {
+		if m.indice(p) < 0 {
+			m.agregarTacho(p)
+			cambio = true
+		}
+	}
+	return cambio
+}
+
+// construirMatrizZona calcula la matriz completa para los tachos de una zona
+func construirMatrizZona(zonaID int, points []Point) *MatrizZona {
+	m := &MatrizZona{
+		IDZona:        zonaID,
+		TipoDistancia: TipoDistancia(),
+		Tachos:        make([]string, len(points)),
+		Posiciones:    make([][2]float64, len(points)),
+		DistanciasKm:  matrizDistancias(points),
+		ActualizadaEn: time.Now(),
+	}
+	for i, p := range points {
+		m.Tachos[i] = p.CustomID
+		m.Posiciones[i] = [2]float64{p.Lat, p.Lng}
+	}
+	m.DuracionesMin = make([][]float64, len(points))
+	for i, fila := range m.DistanciasKm {
+		m.DuracionesMin[i] = make([]float64, len(fila))
+		for j, km := range fila {
+			m.DuracionesMin[i][j] = duracionMinutos(km)
+		}
+	}
+	return m
+}
+
+// agregarTacho suma al final la fila y la columna de un tacho nuevo. Solo se
+// calculan las distancias desde y hacia ese tacho.
+func (m *MatrizZona) agregarTacho(p Point) {
+	if m.indice(p) >= 0 {
+		return
+	}
+	m.quitarTacho(p.CustomID)
+
+	existentes := make([]Point, len(m.Tachos))
+	for i, pos := range m.Posiciones {
+		existentes[i] = Point{Lat: pos[0], Lng: pos[1]}
+	}
+	nuevo := []Point{p}
+	fila := []float64{}
+	columna := []float64{}
+	if len(existentes) > 0 {
+		fila = distanciasEntre(nuevo, existentes)[0]
+		for _, d := range distanciasEntre(existentes, nuevo) {
+			columna = append(columna, d[0])
+		}
+	}
+
+	for i := range m.DistanciasKm {
+		m.DistanciasKm[i] = append(m.DistanciasKm[i], columna[i])
+		m.DuracionesMin[i] = append(m.DuracionesMin[i], duracionMinutos(columna[i]))
+	}
+	filaDuraciones := make([]float64, 0, len(fila)+1)
+	for _, km := range fila {
+		filaDuraciones = append(filaDuraciones, duracionMinutos(km))
+	}
+	m.DistanciasKm = append(m.DistanciasKm, append(fila, 0))
+	m.DuracionesMin = append(m.DuracionesMin, append(filaDuraciones, 0))
+	m.Tachos = append(m.Tachos, p.CustomID)
+	m.Posiciones = append(m.Posiciones, [2]float64{p.Lat, p.Lng})
+	m.ActualizadaEn = time.Now()
+}
+
+// quitarTacho elimina la fila y la columna de un tacho
+func (m *MatrizZona) quitarTacho(customID string) bool {
+	k := -1
+	for i, id := range m.Tachos {
+		if id == customID {
+			k = i
+			break
+		}
+	}
+	if k < 0 {
+		return false
+	}
+
+	quitar := func(filas [][]float64) [][]float64 {
+		filas = append(filas[:k], filas[k+1:]...)
+		for i := range filas {
+			filas[i] = append(filas[i][:k], filas[i][k+1:]...)
+		}
+		return filas
+	}
+	m.DistanciasKm = quitar(m.DistanciasKm)
+	m.DuracionesMin = quitar(m.DuracionesMin)
+	m.Tachos = append(m.Tachos[:k], m.Tachos[k+1:]...)
+	m.Posiciones = append(m.Posiciones[:k], m.Posiciones[k+1:]...)
+	m.ActualizadaEn = time.Now()
+	return true
+}
+
+// getMatrizCacheada lee la matriz de una zona de Redis; nil si no está
+func getMatrizCacheada(zonaID int) (*MatrizZona, error) {
+	if config.RedisClient == nil {
+		return nil, fmt.Errorf("redis client not available")
+	}
+
+	val, err := config.RedisClient.Get(context.Background(), matrizCacheKey(zonaID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var m MatrizZona
+	if err := json.Unmarshal([]byte(val), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// guardarMatriz guarda la matriz en Redis sin vencimiento: se mantiene al día
+// con los cambios de tachos y se reconstruye si deja de cubrir la zona
+func guardarMatriz(m *MatrizZona) error {
+	if config.RedisClient == nil {
+		return fmt.Errorf("redis client not available")
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return config.RedisClient.Set(context.Background(), matrizCacheKey(m.IDZona), b, 0).Err()
+}
+
+// matrizZonaActual devuelve la matriz guardada de la zona puesta al día con
+// los tachos actuales. Si no hay una, la red de calles cambió o se pide
+// reconstruir, se calcula completa.
+func matrizZonaActual(zonaID int, points []Point, reconstruir bool) (*MatrizZona, error) {
+	m, err := getMatrizCacheada(zonaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if m == nil || reconstruir || m.TipoDistancia != TipoDistancia() {
+		m = construirMatrizZona(zonaID, points)
+	} else if !m.sincronizar(points) {
+		return m, nil
+	}
+
+	if err := guardarMatriz(m); err != nil {
+		return nil, fmt.Errorf("error guardando matriz: %v", err)
+	}
+	return m, nil
+}
+
+// matrizParaRuta devuelve la matriz de la zona para calcular una ruta. Sin
+// Redis devuelve nil y la ruta calcula sus propias distancias.
+func matrizParaRuta(zonaID int, points []Point) *MatrizZona {
+	m, err := matrizZonaActual(zonaID, points, false)
+	if err != nil {
+		log.Printf("Warning obteniendo matriz de la zona %d: %v", zonaID, err)
+		return nil
+	}
+	return m
+}
+
+// GetMatrizZona devuelve la matriz de distancias y duraciones de una zona
+func GetMatrizZona(zonaID int, reconstruir bool) (*MatrizZona, error) {
+	points, err := getTachoPointsByZona(zonaID)
+	if err != nil {
+		return nil, err
+	}
+	return matrizZonaActual(zonaID, points, reconstruir)
+}
+
+// agregarTachoAMatrices suma un tacho nuevo a las matrices guardadas de sus
+// zonas. Las zonas sin matriz la construyen completa la próxima vez que se usa.
+func agregarTachoAMatrices(p Point) {
+	zonas, err := zonasDeTacho(p.CustomID)
+	if err != nil {
+		log.Printf("Warning obteniendo zonas del tacho %s: %v", p.CustomID, err)
+		return
+	}
+	for _, zonaID := range zonas {
+		m, err := getMatrizCacheada(zonaID)
+		if err != nil || m == nil {
+			continue
+		}
+		m.agregarTacho(p)
+		if err := guardarMatriz(m); err != nil {
+			log.Printf("Warning actualizando matriz de la zona %d: %v", zonaID, err)
+		}
+	}
+}
+
+// quitarTachoDeMatrices saca un tacho eliminado de las matrices de sus zonas.
+// Sin zonas conocidas se revisan todas las matrices guardadas.
+func quitarTachoDeMatrices(zonas []int, customID string) {
+	if len(zonas) == 0 {
+		zonas = zonasConMatriz()
+	}
+	for _, zonaID := range zonas {
+		m, err := getMatrizCacheada(zonaID)
+		if err != nil || m == nil {
+			continue
+		}
+		if !m.quitarTacho(customID) {
+			continue
+		}
+		if err := guardarMatriz(m); err != nil {
+			log.Printf("Warning actualizando matriz de la zona %d: %v", zonaID, err)
+		}
+	}
+}
+
+// zonasConMatriz devuelve las zonas que tienen matriz guardada en Redis
+func zonasConMatriz() []int {
+	if config.RedisClient == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	zonas := []int{}
+	iter := config.RedisClient.Scan(ctx, 0, "matriz:zona:*", 100).Iterator()
+	for iter.Next(ctx) {
+		var zonaID int
+		if _, err := fmt.Sscanf(iter.Val(), "matriz:zona:%d", &zonaID); err == nil {
+			zonas = append(zonas, zonaID)
+		}
+	}
+	return zonas
+}
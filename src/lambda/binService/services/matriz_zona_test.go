@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tachosMatriz() []Point {
+	return []Point{
+		{Tipo: TipoPuntoTacho, CustomID: "A|X", Lat: -34.600, Lng: -58.400},
+		{Tipo: TipoPuntoTacho, CustomID: "B|X", Lat: -34.605, Lng: -58.410},
+		{Tipo: TipoPuntoTacho, CustomID: "C|X", Lat: -34.610, Lng: -58.405},
+	}
+}
+
+func TestMatrizZona_AgregarYQuitar(t *testing.T) {
+	tachos := tachosMatriz()
+	m := construirMatrizZona(1, tachos[:2])
+
+	m.agregarTacho(tachos[2])
+	completa := construirMatrizZona(1, tachos)
+	assert.Equal(t, completa.Tachos, m.Tachos)
+	assert.InDeltaSlice(t, completa.DistanciasKm[2], m.DistanciasKm[2], 1e-9)
+	assert.InDelta(t, completa.DistanciasKm[0][2], m.DistanciasKm[0][2], 1e-9)
+	assert.InDelta(t, duracionMinutos(m.DistanciasKm[1][2]), m.DuracionesMin[1][2], 1e-9)
+
+	assert.True(t, m.quitarTacho("B|X"))
+	assert.Equal(t, []string{"A|X", "C|X"}, m.Tachos)
+	assert.Len(t, m.DistanciasKm, 2)
+	assert.Len(t, m.DistanciasKm[0], 2)
+	assert.InDelta(t, completa.DistanciasKm[0][2], m.DistanciasKm[0][1], 1e-9)
+	assert.False(t, m.quitarTacho("B|X"))
+}
+
+func TestMatrizZona_Sincronizar(t *testing.T) {
+	tachos := tachosMatriz()
+	m := construirMatrizZona(1, tachos)
+	assert.False(t, m.sincronizar(tachos), "sin cambios no se modifica")
+
+	// B se movió y C ya no está en la zona
+	movido := tachos[1]
+	movido.Lat = -34.620
+	assert.True(t, m.sincronizar([]Point{tachos[0], movido}))
+	assert.Equal(t, []string{"A|X", "B|X"}, m.Tachos)
+	assert.Equal(t, [2]float64{-34.620, -58.410}, m.Posiciones[1])
+}
+
+func TestMatrizDistanciasCon(t *testing.T) {
+	tachos := tachosMatriz()
+	base := construirMatrizZona(1, tachos)
+	origen := Point{Tipo: TipoPuntoOrigen, Lat: -34.59, Lng: -58.39}
+	points := append([]Point{origen}, tachos[2], tachos[0])
+
+	esperada := matrizDistancias(points)
+	obtenida := matrizDistanciasCon(points, base)
+	for i := range esperada {
+		assert.InDeltaSlice(t, esperada[i], obtenida[i], 1e-9)
+	}
+}
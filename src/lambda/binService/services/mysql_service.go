@@ -4,45 +4,27 @@ import (
 	"fmt"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
 )
 
-// createTachoInMySQL crea un tacho en la tabla MySQL y retorna su ID
-func createTachoInMySQL(request CreateTachoRequest, customID string) (int, error) {
-	if config.DB == nil {
-		return 0, fmt.Errorf("database connection not available")
-	}
-
-	// Query para insertar en la tabla Tacho
-	query := `
-		INSERT INTO Tacho (id_tipo, id_estado, id_neo, capacidad) 
-		VALUES (?, ?, ?, ?)
-	`
-
-	result := config.DB.Exec(query,
-		request.IdTipo,
-		request.IdEstado,
-		customID, // Usar el ID personalizado (direccion|barrio) en lugar del ID interno de Neo4j
-		request.Capacidad)
-
-	if result.Error != nil {
-		return 0, fmt.Errorf("error inserting tacho: %v", result.Error)
+// createTachoInMySQL crea un tacho en la tabla MySQL dentro de la transacción y retorna su ID
+func createTachoInMySQL(tx *gorm.DB, request CreateTachoRequest, customID string) (int, error) {
+	tacho := models.Tacho{
+		IDTipo:    int64(request.IdTipo),
+		IDEstado:  int64(request.IdEstado),
+		IDNeo:     customID, // Usar el ID personalizado (direccion|barrio) en lugar del ID interno de Neo4j
+		Capacidad: request.Capacidad,
 	}
-
-	// Obtener el ID generado por la inserción
-	var tachoID int64
-	if result := config.DB.Raw("SELECT LAST_INSERT_ID()").Scan(&tachoID); result.Error != nil {
-		return 0, fmt.Errorf("error getting inserted ID: %v", result.Error)
+	if err := tx.Create(&tacho).Error; err != nil {
+		return 0, fmt.Errorf("error inserting tacho: %v", err)
 	}
 
-	return int(tachoID), nil
+	return int(tacho.IDTacho), nil
 }
 
-// deleteTachoFromMySQL elimina un tacho de MySQL por ID o por custom ID
-func deleteTachoFromMySQL(tachoID int, customID string) error {
-	if config.DB == nil {
-		return fmt.Errorf("database connection not available")
-	}
-
+// deleteTachoFromMySQL elimina un tacho de MySQL por ID o por custom ID exacto dentro de la transacción
+func deleteTachoFromMySQL(tx *gorm.DB, tachoID int, customID string) error {
 	var query string
 	var params []interface{}
 
@@ -52,13 +34,13 @@ func deleteTachoFromMySQL(tachoID int, customID string) error {
 		params = []interface{}{tachoID}
 	} else if customID != "" {
 		// Eliminar por custom ID (que está guardado en id_neo)
-		query = "DELETE FROM Tacho WHERE id_neo LIKE ?"
-		params = []interface{}{"%" + customID + "%"}
+		query = "DELETE FROM Tacho WHERE id_neo = ?"
+		params = []interface{}{customID}
 	} else {
 		return fmt.Errorf("debe proporcionar tachoID o customID para eliminar")
 	}
 
-	result := config.DB.Exec(query, params...)
+	result := tx.Exec(query, params...)
 	if result.Error != nil {
 		return fmt.Errorf("error deleting tacho from MySQL: %v", result.Error)
 	}
@@ -252,3 +234,62 @@ func GetAllTachos() ([]TachoCompleto, error) {
 
 	return tachos, nil
 }
+
+// getTachosParaBorrar obtiene las filas que borraría deleteTachoFromMySQL con los mismos parámetros
+func getTachosParaBorrar(tachoID int, customID string) ([]TachoMySQL, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	query := config.DB.Table("Tacho").Select("id_tacho, id_tipo, id_estado, id_neo, capacidad")
+	if tachoID > 0 {
+		query = query.Where("id_tacho = ?", tachoID)
+	} else {
+		query = query.Where("id_neo = ?", customID)
+	}
+
+	var tachos []TachoMySQL
+	if err := query.Order("id_tacho").Scan(&tachos).Error; err != nil {
+		return nil, fmt.Errorf("error getting tacho: %v", err)
+	}
+	return tachos, nil
+}
+
+// borrarTachoMySQLPorID elimina un tacho por id_tacho. Si ya no existe no es error.
+func borrarTachoMySQLPorID(tachoID int) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	if err := config.DB.Exec("DELETE FROM Tacho WHERE id_tacho = ?", tachoID).Error; err != nil {
+		return fmt.Errorf("error deleting tacho from MySQL: %v", err)
+	}
+	return nil
+}
+
+// getTodosTachosMySQL obtiene todas las filas de la tabla Tacho
+func getTodosTachosMySQL() ([]TachoMySQL, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var tachos []TachoMySQL
+	if err := config.DB.Raw("SELECT id_tacho, id_tipo, id_estado, id_neo, capacidad FROM Tacho ORDER BY id_tacho").
+		Scan(&tachos).Error; err != nil {
+		return nil, fmt.Errorf("error querying tachos: %v", err)
+	}
+	return tachos, nil
+}
+
+// contarOtrosTachosConIDNeo cuenta las filas con el custom id, sin contar la indicada
+func contarOtrosTachosConIDNeo(customID string, excluir int) (int, error) {
+	if config.DB == nil {
+		return 0, fmt.Errorf("database connection not available")
+	}
+
+	var total int64
+	if err := config.DB.Table("Tacho").Where("id_neo = ? AND id_tacho <> ?", customID, excluir).
+		Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("error counting tachos: %v", err)
+	}
+	return int(total), nil
+}
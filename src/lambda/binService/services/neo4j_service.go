@@ -17,59 +17,6 @@ func getSession() (neo4j.SessionWithContext, error) {
 	return session, nil
 }
 
-// createTachoInNeo4j crea un nodo Tacho en Neo4j y retorna su ID
-func createTachoInNeo4j(request CreateTachoRequest) (string, error) {
-	session, err := getSession()
-	if err != nil {
-		return "", err
-	}
-	defer session.Close(context.Background())
-
-	// Crear el nodo con point() para la ubicación geográfica
-	query := `
-		CREATE (t:Tacho {
-			barrio: $barrio,
-			direccion: $direccion,
-			id: $id,
-			location: point({latitude: $latitude, longitude: $longitude}),
-			prioridad: $prioridad
-		})
-		RETURN elementId(t) as nodeId
-	`
-
-	// Generar un ID único para el tacho (puedes usar una estrategia diferente)
-	tachoNeoID := fmt.Sprintf("%s|%s", request.Direccion, request.Barrio)
-
-	result, err := session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
-		ctx := context.Background()
-		result, err := tx.Run(ctx, query, map[string]interface{}{
-			"barrio":    request.Barrio,
-			"direccion": request.Direccion,
-			"id":        tachoNeoID,
-			"latitude":  request.Latitude,
-			"longitude": request.Longitude,
-			"prioridad": request.Prioridad,
-		})
-		if err != nil {
-			return nil, err
-		}
-
-		record, err := result.Single(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		nodeId, _ := record.Get("nodeId")
-		return nodeId.(string), nil
-	})
-
-	if err != nil {
-		return "", err
-	}
-
-	return result.(string), nil
-}
-
 // deleteTachoFromNeo4j elimina un nodo Tacho de Neo4j por su elementId o por su id personalizado
 func deleteTachoFromNeo4j(neoNodeID string, customID string) error {
 	session, err := getSession()
@@ -277,3 +224,264 @@ func GetAllTachosCoordinates() (map[string]TachoNeo4j, error) {
 
 	return result.(map[string]TachoNeo4j), nil
 }
+
+// getTachoPointsByBarrios obtiene las ubicaciones de los tachos de uno o más barrios desde Neo4j
+func getTachoPointsByBarrios(barrios []string) ([]Point, error) {
+	return queryTachoPoints("t.barrio IN $valores", barrios)
+}
+
+// getTachoPointsByIDs obtiene las ubicaciones de los tachos con los IDs personalizados indicados
+func getTachoPointsByIDs(customIDs []string) ([]Point, error) {
+	if len(customIDs) == 0 {
+		return []Point{}, nil
+	}
+	return queryTachoPoints("t.id IN $valores", customIDs)
+}
+
+// queryTachoPoints obtiene como puntos de ruta los tachos que cumplen la condición
+func queryTachoPoints(condicion string, valores []string) ([]Point, error) {
+	session, err := getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(context.Background())
+
+	query := `
+		MATCH (t:Tacho)
+		WHERE ` + condicion + `
+		RETURN t.id AS id, t.direccion AS direccion, t.location.latitude AS lat,
+		       t.location.longitude AS lng, t.prioridad AS prioridad
+	`
+
+	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		records, err := tx.Run(ctx, query, map[string]interface{}{
+			"valores": valores,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		points := []Point{}
+		for records.Next(ctx) {
+			rec := records.Record()
+			latVal, _ := rec.Get("lat")
+			lngVal, _ := rec.Get("lng")
+
+			lat, ok1 := latVal.(float64)
+			lng, ok2 := lngVal.(float64)
+			if !ok1 || !ok2 {
+				continue
+			}
+
+			idVal, _ := rec.Get("id")
+			direccionVal, _ := rec.Get("direccion")
+			prioridadVal, _ := rec.Get("prioridad")
+
+			points = append(points, Point{
+				Tipo:      TipoPuntoTacho,
+				CustomID:  getStringValue(idVal),
+				Direccion: getStringValue(direccionVal),
+				Lat:       lat,
+				Lng:       lng,
+				Prioridad: int(getFloatValue(prioridadVal)),
+			})
+		}
+
+		return points, records.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]Point), nil
+}
+
+// buscarTachosNeo4j obtiene todos los nodos con el ID personalizado indicado
+// (puede haber duplicados); si no hay ninguno devuelve una lista vacía
+func buscarTachosNeo4j(customID string) ([]TachoNeo4j, error) {
+	session, err := getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(context.Background())
+
+	query := `
+		MATCH (t:Tacho {id: $customId})
+		RETURN t.barrio AS barrio, t.direccion AS direccion, t.id AS id,
+		       t.location.latitude AS latitude, t.location.longitude AS longitude,
+		       t.prioridad AS prioridad, elementId(t) AS nodeId
+	`
+
+	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		records, err := tx.Run(ctx, query, map[string]interface{}{"customId": customID})
+		if err != nil {
+			return nil, err
+		}
+
+		tachos := []TachoNeo4j{}
+		for records.Next(ctx) {
+			rec := records.Record()
+			barrio, _ := rec.Get("barrio")
+			direccion, _ := rec.Get("direccion")
+			id, _ := rec.Get("id")
+			latitude, _ := rec.Get("latitude")
+			longitude, _ := rec.Get("longitude")
+			prioridad, _ := rec.Get("prioridad")
+			nodeID, _ := rec.Get("nodeId")
+
+			tachos = append(tachos, TachoNeo4j{
+				NodeID:    getStringValue(nodeID),
+				Barrio:    getStringValue(barrio),
+				Direccion: getStringValue(direccion),
+				ID:        getStringValue(id),
+				Latitude:  getFloatValue(latitude),
+				Longitude: getFloatValue(longitude),
+				Prioridad: int(getFloatValue(prioridad)),
+			})
+		}
+		return tachos, records.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting tacho from Neo4j: %v", err)
+	}
+
+	return result.([]TachoNeo4j), nil
+}
+
+// deduplicarTachoNeo4j deja un solo nodo con el id personalizado: conserva
+// el de menor elementId (el primero de getNodosTachoNeo4j) y borra el resto.
+// Es idempotente.
+func deduplicarTachoNeo4j(customID string) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close(context.Background())
+
+	_, err = session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		_, err := tx.Run(ctx, `
+			MATCH (t:Tacho {id: $customId})
+			WITH t ORDER BY elementId(t)
+			WITH collect(t) AS nodos
+			FOREACH (t IN nodos[1..] | DELETE t)
+		`, map[string]interface{}{"customId": customID})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting duplicated tachos from Neo4j: %v", err)
+	}
+	return nil
+}
+
+// guardarTachoEnNeo4j deja el nodo con los datos indicados: lo busca por
+// idActual (o lo crea si no existe) y le pone id, dirección, ubicación y
+// prioridad. Es idempotente; lo usan el outbox y la reconciliación.
+func guardarTachoEnNeo4j(idActual string, tacho TachoNeo4j) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close(context.Background())
+
+	_, err = session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		_, err := tx.Run(ctx, `
+			MERGE (t:Tacho {id: $idActual})
+			SET t.id = $id,
+			    t.barrio = $barrio,
+			    t.direccion = $direccion,
+			    t.location = point({latitude: $latitude, longitude: $longitude}),
+			    t.prioridad = $prioridad
+		`, map[string]interface{}{
+			"idActual":  idActual,
+			"id":        tacho.ID,
+			"barrio":    tacho.Barrio,
+			"direccion": tacho.Direccion,
+			"latitude":  tacho.Latitude,
+			"longitude": tacho.Longitude,
+			"prioridad": tacho.Prioridad,
+		})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("error saving tacho in Neo4j: %v", err)
+	}
+	return nil
+}
+
+// borrarTachoNeo4jPorID elimina los nodos con el id personalizado indicado.
+// Si ya no existen no es error.
+func borrarTachoNeo4jPorID(customID string) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close(context.Background())
+
+	_, err = session.ExecuteWrite(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		_, err := tx.Run(ctx, `
+			MATCH (t:Tacho {id: $customId})
+			DELETE t
+		`, map[string]interface{}{"customId": customID})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting tacho from Neo4j: %v", err)
+	}
+	return nil
+}
+
+// getNodosTachoNeo4j obtiene todos los nodos Tacho con su elementId. A
+// diferencia de GetAllTachosCoordinates no agrupa por id, así se ven los duplicados.
+func getNodosTachoNeo4j() ([]TachoNeo4j, error) {
+	session, err := getSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close(context.Background())
+
+	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		ctx := context.Background()
+		records, err := tx.Run(ctx, `
+			MATCH (t:Tacho)
+			RETURN t.barrio AS barrio, t.direccion AS direccion, t.id AS id,
+			       t.location.latitude AS latitude, t.location.longitude AS longitude,
+			       t.prioridad AS prioridad, elementId(t) AS nodeId
+			ORDER BY elementId(t)
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		tachos := []TachoNeo4j{}
+		for records.Next(ctx) {
+			rec := records.Record()
+			barrio, _ := rec.Get("barrio")
+			direccion, _ := rec.Get("direccion")
+			id, _ := rec.Get("id")
+			latitude, _ := rec.Get("latitude")
+			longitude, _ := rec.Get("longitude")
+			prioridad, _ := rec.Get("prioridad")
+			nodeID, _ := rec.Get("nodeId")
+
+			tachos = append(tachos, TachoNeo4j{
+				NodeID:    getStringValue(nodeID),
+				Barrio:    getStringValue(barrio),
+				Direccion: getStringValue(direccion),
+				ID:        getStringValue(id),
+				Latitude:  getFloatValue(latitude),
+				Longitude: getFloatValue(longitude),
+				Prioridad: int(getFloatValue(prioridad)),
+			})
+		}
+		return tachos, records.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting tachos from Neo4j: %v", err)
+	}
+	return result.([]TachoNeo4j), nil
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Algoritmos de ordenamiento disponibles para /ruta-optima
+const (
+	// AlgoritmoSimple ordena por distancia al primer punto (comportamiento original)
+	AlgoritmoSimple = "simple"
+	// AlgoritmoVecino construye la ruta con la heurística del vecino más cercano
+	AlgoritmoVecino = "vecino"
+	// Algoritmo2Opt aplica 2-opt sobre la ruta del vecino más cercano
+	Algoritmo2Opt = "2opt"
+	// AlgoritmoCompleto aplica 2-opt y Or-opt sobre la ruta del vecino más cercano
+	AlgoritmoCompleto = "completo"
+)
+
+// AlgoritmoPorDefecto es el algoritmo usado cuando no se especifica ninguno
+const AlgoritmoPorDefecto = AlgoritmoCompleto
+
+// epsilon evita ciclos infinitos por errores de redondeo en las mejoras locales
+const epsilon = 1e-9
+
+// maxOrOptSegmento es el largo máximo de los segmentos que mueve Or-opt
+const maxOrOptSegmento = 3
+
+// ValidarAlgoritmo normaliza el algoritmo recibido y verifica que sea conocido
+func ValidarAlgoritmo(algoritmo string) (string, error) {
+	switch algoritmo {
+	case "":
+		return AlgoritmoPorDefecto, nil
+	case AlgoritmoSimple, AlgoritmoVecino, Algoritmo2Opt, AlgoritmoCompleto:
+		return algoritmo, nil
+	default:
+		return "", fmt.Errorf("algoritmo desconocido: %s (opciones: %s, %s, %s, %s)",
+			algoritmo, AlgoritmoSimple, AlgoritmoVecino, Algoritmo2Opt, AlgoritmoCompleto)
+	}
+}
+
+// matrizDistancias calcula la matriz de distancias (km) entre todos los puntos:
+// por calles si hay red vial cargada o haversine si no
+func matrizDistancias(points []Point) [][]float64 {
+	if g := redVialActual(); g != nil {
+		return g.matriz(points)
+	}
+
+	n := len(points)
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := haversine(points[i].Lat, points[i].Lng, points[j].Lat, points[j].Lng)
+			dist[i][j] = d
+			dist[j][i] = d
+		}
+	}
+	return dist
+}
+
+// distanciasEntre calcula la distancia (km) desde cada punto de desde a cada
+// punto de hacia, por calles si hay red vial cargada o haversine si no
+func distanciasEntre(desde, hacia []Point) [][]float64 {
+	if g := redVialActual(); g != nil {
+		return g.distancias(desde, hacia)
+	}
+
+	dist := make([][]float64, len(desde))
+	for i, a := range desde {
+		dist[i] = make([]float64, len(hacia))
+		for j, b := range hacia {
+			dist[i][j] = haversine(a.Lat, a.Lng, b.Lat, b.Lng)
+		}
+	}
+	return dist
+}
+
+// matrizDistanciasCon arma la matriz de distancias tomando de base los pares de
+// tachos ya calculados; solo se calculan las filas y columnas de los puntos que
+// no están en ella (origen, destino, centros o tachos nuevos)
+func matrizDistanciasCon(points []Point, base *MatrizZona) [][]float64 {
+	if base == nil {
+		return matrizDistancias(points)
+	}
+
+	indices := make([]int, len(points))
+	nuevos := []int{}
+	for i, p := range points {
+		indices[i] = base.indice(p)
+		if indices[i] < 0 {
+			nuevos = append(nuevos, i)
+		}
+	}
+	if len(nuevos) == len(points) {
+		return matrizDistancias(points)
+	}
+
+	n := len(points)
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+		if indices[i] < 0 {
+			continue
+		}
+		for j := range dist[i] {
+			if indices[j] >= 0 {
+				dist[i][j] = base.DistanciasKm[indices[i]][indices[j]]
+			}
+		}
+	}
+
+	if len(nuevos) > 0 {
+		puntosNuevos := make([]Point, len(nuevos))
+		for k, i := range nuevos {
+			puntosNuevos[k] = points[i]
+		}
+		filas := distanciasEntre(puntosNuevos, points)
+		columnas := distanciasEntre(points, puntosNuevos)
+		for k, i := range nuevos {
+			for j := 0; j < n; j++ {
+				if i == j {
+					continue
+				}
+				dist[i][j] = filas[k][j]
+				dist[j][i] = columnas[j][k]
+			}
+		}
+	}
+	return dist
+}
+
+// longitudRecorrido suma las distancias de un recorrido abierto
+func longitudRecorrido(dist [][]float64, recorrido []int) float64 {
+	total := 0.0
+	for i := 1; i < len(recorrido); i++ {
+		total += dist[recorrido[i-1]][recorrido[i]]
+	}
+	return total
+}
+
+// limiteMovil devuelve la cantidad de posiciones que los algoritmos pueden
+// reordenar: con final fijo el último nodo nunca se mueve
+func limiteMovil(n int, finFijo bool) int {
+	if finFijo && n > 1 {
+		return n - 1
+	}
+	return n
+}
+
+// ordenSimple reproduce el orden original: distancia al primer punto
+func ordenSimple(dist [][]float64, finFijo bool) []int {
+	n := len(dist)
+	recorrido := make([]int, n)
+	for i := range recorrido {
+		recorrido[i] = i
+	}
+	if limite := limiteMovil(n, finFijo); limite > 1 {
+		resto := recorrido[1:limite]
+		sort.SliceStable(resto, func(i, j int) bool {
+			return dist[0][resto[i]] < dist[0][resto[j]]
+		})
+	}
+	return recorrido
+}
+
+// vecinoMasCercano construye un recorrido que empieza en el nodo 0 y siempre
+// avanza al nodo no visitado más cercano. Con final fijo el último nodo se
+// visita al terminar.
+func vecinoMasCercano(dist [][]float64, finFijo bool) []int {
+	n := len(dist)
+	if n == 0 {
+		return []int{}
+	}
+	limite := limiteMovil(n, finFijo)
+
+	visitado := make([]bool, n)
+	recorrido := make([]int, 0, n)
+	actual := 0
+	visitado[actual] = true
+	recorrido = append(recorrido, actual)
+
+	for len(recorrido) < limite {
+		siguiente := -1
+		for j := 0; j < limite; j++ {
+			if visitado[j] {
+				continue
+			}
+			if siguiente == -1 || dist[actual][j] < dist[actual][siguiente] {
+				siguiente = j
+			}
+		}
+		visitado[siguiente] = true
+		recorrido = append(recorrido, siguiente)
+		actual = siguiente
+	}
+
+	if limite < n {
+		recorrido = append(recorrido, n-1)
+	}
+
+	return recorrido
+}
+
+// arista devuelve la distancia entre dos posiciones del recorrido; si alguna
+// queda fuera del recorrido (extremo libre) la arista no existe y vale 0
+func arista(dist [][]float64, recorrido []int, i, j int) float64 {
+	if i < 0 || j < 0 || i >= len(recorrido) || j >= len(recorrido) {
+		return 0
+	}
+	return dist[recorrido[i]][recorrido[j]]
+}
+
+// dosOpt mejora un recorrido abierto invirtiendo tramos mientras se reduzca la
+// distancia total. El primer nodo queda fijo y el último solo si finFijo.
+func dosOpt(dist [][]float64, recorrido []int, finFijo bool) []int {
+	n := len(recorrido)
+	limite := limiteMovil(n, finFijo)
+	if limite < 3 {
+		return recorrido
+	}
+
+	asimetrica := !esSimetrica(dist)
+
+	mejorado := true
+	for mejorado {
+		mejorado = false
+		for i := 1; i < limite-1; i++ {
+			for j := i + 1; j < limite; j++ {
+				antes := arista(dist, recorrido, i-1, i) + arista(dist, recorrido, j, j+1)
+				despues := dist[recorrido[i-1]][recorrido[j]] + arista(dist, recorrido, i, j+1)
+				if asimetrica {
+					// Con calles de una mano el tramo invertido cambia de largo
+					for k := i; k < j; k++ {
+						antes += dist[recorrido[k]][recorrido[k+1]]
+						despues += dist[recorrido[k+1]][recorrido[k]]
+					}
+				}
+				if despues < antes-epsilon {
+					invertirTramo(recorrido, i, j)
+					mejorado = true
+				}
+			}
+		}
+	}
+
+	return recorrido
+}
+
+// esSimetrica indica si la distancia de ida es igual a la de vuelta para todo par
+func esSimetrica(dist [][]float64) bool {
+	for i := range dist {
+		for j := i + 1; j < len(dist); j++ {
+			if math.Abs(dist[i][j]-dist[j][i]) > epsilon {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// invertirTramo invierte en el lugar las posiciones i..j del recorrido
+func invertirTramo(recorrido []int, i, j int) {
+	for i < j {
+		recorrido[i], recorrido[j] = recorrido[j], recorrido[i]
+		i++
+		j--
+	}
+}
+
+// orOpt mejora un recorrido abierto moviendo segmentos de 1 a 3 nodos a otra
+// posición mientras se reduzca la distancia total. El primer nodo queda fijo y
+// el último solo si finFijo.
+func orOpt(dist [][]float64, recorrido []int, finFijo bool) []int {
+	n := len(recorrido)
+	limite := limiteMovil(n, finFijo)
+	if limite < 3 {
+		return recorrido
+	}
+
+	mejorado := true
+	for mejorado {
+		mejorado = false
+		for largo := 1; largo <= maxOrOptSegmento && !mejorado; largo++ {
+			for i := 1; i+largo <= limite && !mejorado; i++ {
+				fin := i + largo - 1
+
+				// Ganancia por quitar el segmento [i, fin]
+				quitar := arista(dist, recorrido, i-1, i) + arista(dist, recorrido, fin, fin+1) -
+					arista(dist, recorrido, i-1, fin+1)
+
+				for p := 0; p < limite; p++ {
+					// Insertar entre p y p+1, fuera del segmento y sin reinsertarlo en su lugar
+					if p >= i-1 && p <= fin {
+						continue
+					}
+					// Si p es el último nodo las aristas hacia p+1 no existen y valen 0
+					insertar := dist[recorrido[p]][recorrido[i]] + arista(dist, recorrido, fin, p+1) -
+						arista(dist, recorrido, p, p+1)
+
+					if insertar < quitar-epsilon {
+						recorrido = moverSegmento(recorrido, i, fin, p)
+						mejorado = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return recorrido
+}
+
+// moverSegmento devuelve un nuevo recorrido con el segmento [i, fin] insertado
+// a continuación de la posición p (p fuera del segmento)
+func moverSegmento(recorrido []int, i, fin, p int) []int {
+	segmento := append([]int{}, recorrido[i:fin+1]...)
+	resto := make([]int, 0, len(recorrido)-len(segmento))
+	resto = append(resto, recorrido[:i]...)
+	resto = append(resto, recorrido[fin+1:]...)
+
+	// Ajustar la posición de inserción al recorrido sin el segmento
+	if p > fin {
+		p -= len(segmento)
+	}
+
+	nuevo := make([]int, 0, len(recorrido))
+	nuevo = append(nuevo, resto[:p+1]...)
+	nuevo = append(nuevo, segmento...)
+	nuevo = append(nuevo, resto[p+1:]...)
+	return nuevo
+}
+
+// ordenarRecorrido aplica el algoritmo indicado y devuelve el orden de visita
+func ordenarRecorrido(dist [][]float64, algoritmo string, finFijo bool) []int {
+	switch algoritmo {
+	case AlgoritmoSimple:
+		return ordenSimple(dist, finFijo)
+	case AlgoritmoVecino:
+		return vecinoMasCercano(dist, finFijo)
+	case Algoritmo2Opt:
+		return dosOpt(dist, vecinoMasCercano(dist, finFijo), finFijo)
+	default:
+		recorrido := dosOpt(dist, vecinoMasCercano(dist, finFijo), finFijo)
+		recorrido = orOpt(dist, recorrido, finFijo)
+		// Or-opt puede habilitar nuevas mejoras 2-opt
+		return dosOpt(dist, recorrido, finFijo)
+	}
+}
+
+// OptimizarRuta ordena los puntos con el algoritmo indicado y calcula la distancia total.
+// Si se indica origen la ruta arranca ahí; si se indica destino la ruta termina ahí.
+func OptimizarRuta(points []Point, algoritmo string, origen, destino *Point) *Ruta {
+	return optimizarConMatriz(points, algoritmo, origen, destino, nil)
+}
+
+// optimizarConMatriz es OptimizarRuta reutilizando las distancias de la matriz
+// precalculada de la zona cuando se indica
+func optimizarConMatriz(points []Point, algoritmo string, origen, destino *Point, base *MatrizZona) *Ruta {
+	nodos := make([]Point, 0, len(points)+2)
+	if origen != nil {
+		nodos = append(nodos, *origen)
+	}
+	nodos = append(nodos, points...)
+	finFijo := destino != nil
+	if finFijo {
+		nodos = append(nodos, *destino)
+	}
+	points = nodos
+
+	dist := matrizDistanciasCon(points, base)
+	recorrido := ordenarRecorrido(dist, algoritmo, finFijo)
+
+	ordenados := make([]Point, len(recorrido))
+	for i, idx := range recorrido {
+		ordenados[i] = points[idx]
+	}
+
+	return &Ruta{
+		Algoritmo:      algoritmo,
+		Puntos:         ordenados,
+		DistanciaTotal: longitudRecorrido(dist, recorrido),
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// puntosZigZag arma una fila de tachos sobre una misma calle, desordenados
+func puntosZigZag() []Point {
+	lngs := []float64{0.000, 0.004, 0.001, 0.005, 0.002, 0.006, 0.003}
+	points := make([]Point, len(lngs))
+	for i, lng := range lngs {
+		points[i] = Point{ID: i + 1, Lat: -34.6, Lng: -58.4 + lng}
+	}
+	return points
+}
+
+func TestValidarAlgoritmo(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"Vacío usa el default", "", AlgoritmoPorDefecto, false},
+		{"Simple", "simple", AlgoritmoSimple, false},
+		{"2-opt", "2opt", Algoritmo2Opt, false},
+		{"Desconocido", "genetico", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidarAlgoritmo(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOptimizarRuta_MejoraElOrdenSimple(t *testing.T) {
+	points := puntosZigZag()
+
+	simple := OptimizarRuta(points, AlgoritmoSimple, nil, nil)
+	for _, algoritmo := range []string{AlgoritmoVecino, Algoritmo2Opt, AlgoritmoCompleto} {
+		t.Run(algoritmo, func(t *testing.T) {
+			ruta := OptimizarRuta(points, algoritmo, nil, nil)
+
+			assert.Equal(t, algoritmo, ruta.Algoritmo)
+			assert.Len(t, ruta.Puntos, len(points))
+			assert.Equal(t, points[0].ID, ruta.Puntos[0].ID, "la ruta arranca en el primer tacho")
+			assert.LessOrEqual(t, ruta.DistanciaTotal, simple.DistanciaTotal+epsilon)
+		})
+	}
+
+	// Sobre una misma calle el recorrido óptimo es ir de punta a punta
+	ruta := OptimizarRuta(points, AlgoritmoCompleto, nil, nil)
+	ids := make([]int, len(ruta.Puntos))
+	for i, p := range ruta.Puntos {
+		ids[i] = p.ID
+	}
+	assert.Equal(t, []int{1, 3, 5, 7, 2, 4, 6}, ids)
+}
+
+func TestOrOpt_MueveSegmentoMalUbicado(t *testing.T) {
+	dist := [][]float64{
+		{0, 1, 2, 3, 4},
+		{1, 0, 1, 2, 3},
+		{2, 1, 0, 1, 2},
+		{3, 2, 1, 0, 1},
+		{4, 3, 2, 1, 0},
+	}
+
+	recorrido := orOpt(dist, []int{0, 3, 1, 2, 4}, false)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, recorrido)
+	assert.Equal(t, 4.0, longitudRecorrido(dist, recorrido))
+}
+
+func TestOptimizarRuta_PocosPuntos(t *testing.T) {
+	assert.Empty(t, OptimizarRuta([]Point{}, AlgoritmoCompleto, nil, nil).Puntos)
+
+	ruta := OptimizarRuta([]Point{{ID: 1, Lat: -34.6, Lng: -58.4}}, AlgoritmoCompleto, nil, nil)
+	assert.Len(t, ruta.Puntos, 1)
+	assert.Equal(t, 0.0, ruta.DistanciaTotal)
+}
+
+func TestOptimizarRuta_OrigenYDestinoFijos(t *testing.T) {
+	points := puntosZigZag()
+	origen := &Point{ID: 10, Tipo: TipoPuntoOrigen, Lat: -34.6, Lng: -58.4 + 0.0035}
+	destino := &Point{ID: 20, Tipo: TipoPuntoDestino, Lat: -34.6, Lng: -58.4 - 0.001}
+
+	for _, algoritmo := range []string{AlgoritmoSimple, AlgoritmoVecino, Algoritmo2Opt, AlgoritmoCompleto} {
+		t.Run(algoritmo, func(t *testing.T) {
+			ruta := OptimizarRuta(points, algoritmo, origen, destino)
+
+			assert.Len(t, ruta.Puntos, len(points)+2)
+			assert.Equal(t, TipoPuntoOrigen, ruta.Puntos[0].Tipo)
+			assert.Equal(t, TipoPuntoDestino, ruta.Puntos[len(ruta.Puntos)-1].Tipo)
+		})
+	}
+
+	// Desde el medio de la calle conviene ir primero hacia el extremo opuesto al destino
+	ruta := OptimizarRuta(points, AlgoritmoCompleto, origen, destino)
+	optimo := []Point{*origen, points[1], points[3], points[5], points[6], points[4], points[2], points[0], *destino}
+	dist := matrizDistancias(optimo)
+	assert.InDelta(t, longitudRecorrido(dist, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}), ruta.DistanciaTotal, 1e-6)
+}
+
+func TestDosOpt_MatrizAsimetrica(t *testing.T) {
+	// Con distancias simétricas convendría invertir 1-2, pero volver de 2 a 1 es muy caro
+	dist := [][]float64{
+		{0, 5, 1, 9},
+		{9, 0, 1, 1},
+		{9, 20, 0, 5},
+		{9, 9, 9, 0},
+	}
+	recorrido := []int{0, 1, 2, 3}
+	antes := longitudRecorrido(dist, recorrido)
+
+	mejorado := dosOpt(dist, append([]int{}, recorrido...), false)
+
+	assert.LessOrEqual(t, longitudRecorrido(dist, mejorado), antes)
+	assert.Equal(t, recorrido, mejorado)
+}
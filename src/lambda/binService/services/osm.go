@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+
+	"github.com/paulmach/osm"
+	"github.com/paulmach/osm/osmpbf"
+)
+
+// callesTransitables son los valores de highway por los que puede circular un camión
+var callesTransitables = map[string]bool{
+	"motorway": true, "trunk": true, "primary": true, "secondary": true, "tertiary": true,
+	"unclassified": true, "residential": true, "service": true, "living_street": true,
+	"motorway_link": true, "trunk_link": true, "primary_link": true, "secondary_link": true,
+	"tertiary_link": true,
+}
+
+// ErrExtractoOSM indica que el extracto no se puede leer o no tiene red de calles
+var ErrExtractoOSM = errors.New("extracto OSM inválido")
+
+// NodoVial es una intersección de la red de calles
+type NodoVial struct {
+	OsmID int64   `json:"osm_id"`
+	Lat   float64 `json:"lat"`
+	Lng   float64 `json:"lng"`
+}
+
+// TramoVial es una cuadra (o más) entre dos intersecciones, en sentido Desde -> Hasta
+type TramoVial struct {
+	Desde      int64
+	Hasta      int64
+	Nombre     string
+	LongitudKm float64
+	UnaMano    bool
+	// Geometria incluye los extremos, como posiciones [lat, lng]
+	Geometria [][2]float64
+}
+
+// nodoOSM y caminoOSM son los elementos de OSM XML que se usan para la red
+type nodoOSM struct {
+	ID  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type caminoOSM struct {
+	Nodos []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []struct {
+		K string `xml:"k,attr"`
+		V string `xml:"v,attr"`
+	} `xml:"tag"`
+}
+
+// calleOSM es un way transitable ya filtrado
+type calleOSM struct {
+	Nodos   []int64
+	Nombre  string
+	UnaMano bool
+}
+
+// ParseOSM lee un extracto OSM, en XML o PBF, y devuelve las intersecciones y
+// tramos de la red de calles transitables
+func ParseOSM(r io.Reader) ([]NodoVial, []TramoVial, error) {
+	lector := bufio.NewReader(r)
+	leer := leerOSMXML
+	if cabecera, err := lector.Peek(16); err == nil && bytes.Contains(cabecera, []byte("OSMHeader")) {
+		leer = leerOSMPBF
+	}
+
+	coordenadas, calles, err := leer(lector)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(calles) == 0 {
+		return nil, nil, fmt.Errorf("%w: no tiene calles transitables", ErrExtractoOSM)
+	}
+
+	nodos, tramos := construirTramos(coordenadas, calles)
+	return nodos, tramos, nil
+}
+
+// leerOSMXML lee las coordenadas de los nodos y las calles de un extracto OSM XML
+func leerOSMXML(r io.Reader) (map[int64][2]float64, []calleOSM, error) {
+	coordenadas := make(map[int64][2]float64)
+	calles := []calleOSM{}
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: XML mal formado: %v", ErrExtractoOSM, err)
+		}
+
+		inicio, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch inicio.Name.Local {
+		case "node":
+			var nodo nodoOSM
+			if err := decoder.DecodeElement(&nodo, &inicio); err != nil {
+				return nil, nil, fmt.Errorf("%w: nodo mal formado: %v", ErrExtractoOSM, err)
+			}
+			coordenadas[nodo.ID] = [2]float64{nodo.Lat, nodo.Lon}
+		case "way":
+			var camino caminoOSM
+			if err := decoder.DecodeElement(&camino, &inicio); err != nil {
+				return nil, nil, fmt.Errorf("%w: way mal formado: %v", ErrExtractoOSM, err)
+			}
+			tags := make(map[string]string, len(camino.Tags))
+			for _, tag := range camino.Tags {
+				tags[tag.K] = tag.V
+			}
+			refs := make([]int64, 0, len(camino.Nodos))
+			for _, nd := range camino.Nodos {
+				refs = append(refs, nd.Ref)
+			}
+			if calle, ok := filtrarCalle(refs, tags); ok {
+				calles = append(calles, calle)
+			}
+		}
+	}
+	return coordenadas, calles, nil
+}
+
+// leerOSMPBF lee las coordenadas de los nodos y las calles de un extracto OSM
+// PBF. Las relaciones no se usan para la red y se saltean. El decoder solo
+// lee nodos en formato DenseNodes, que es como los escriben osmium y los
+// extractos de Geofabrik.
+func leerOSMPBF(r io.Reader) (map[int64][2]float64, []calleOSM, error) {
+	coordenadas := make(map[int64][2]float64)
+	calles := []calleOSM{}
+
+	scanner := osmpbf.New(context.Background(), r, runtime.GOMAXPROCS(0))
+	defer scanner.Close()
+	scanner.SkipRelations = true
+
+	for scanner.Scan() {
+		switch objeto := scanner.Object().(type) {
+		case *osm.Node:
+			coordenadas[int64(objeto.ID)] = [2]float64{objeto.Lat, objeto.Lon}
+		case *osm.Way:
+			refs := make([]int64, 0, len(objeto.Nodes))
+			for _, nd := range objeto.Nodes {
+				refs = append(refs, int64(nd.ID))
+			}
+			if calle, ok := filtrarCalle(refs, objeto.Tags.Map()); ok {
+				calles = append(calles, calle)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: PBF mal formado: %v", ErrExtractoOSM, err)
+	}
+	return coordenadas, calles, nil
+}
+
+// filtrarCalle convierte un way (sus nodos y tags) en calle si es transitable,
+// resolviendo el sentido
+func filtrarCalle(nodos []int64, tags map[string]string) (calleOSM, bool) {
+	if !callesTransitables[tags["highway"]] || len(nodos) < 2 {
+		return calleOSM{}, false
+	}
+
+	calle := calleOSM{Nombre: tags["name"], Nodos: nodos}
+
+	switch tags["oneway"] {
+	case "yes", "true", "1":
+		calle.UnaMano = true
+	case "-1", "reverse":
+		// Mano contraria al orden de los nodos
+		calle.UnaMano = true
+		for i, j := 0, len(calle.Nodos)-1; i < j; i, j = i+1, j-1 {
+			calle.Nodos[i], calle.Nodos[j] = calle.Nodos[j], calle.Nodos[i]
+		}
+	case "no", "false", "0":
+	default:
+		if tags["junction"] == "roundabout" || tags["highway"] == "motorway" {
+			calle.UnaMano = true
+		}
+	}
+
+	return calle, true
+}
+
+// construirTramos corta las calles en las intersecciones (nodos compartidos por
+// más de una calle o extremos) y calcula el largo de cada tramo
+func construirTramos(coordenadas map[int64][2]float64, calles []calleOSM) ([]NodoVial, []TramoVial) {
+	usos := make(map[int64]int)
+	for _, calle := range calles {
+		for i, id := range calle.Nodos {
+			usos[id]++
+			// Los extremos siempre son intersección
+			if i == 0 || i == len(calle.Nodos)-1 {
+				usos[id]++
+			}
+		}
+	}
+
+	intersecciones := make(map[int64]bool)
+	tramos := []TramoVial{}
+	for _, calle := range calles {
+		var actual *TramoVial
+		for _, id := range calle.Nodos {
+			coord, ok := coordenadas[id]
+			if !ok {
+				// Nodo fuera del extracto: se corta la calle
+				actual = nil
+				continue
+			}
+
+			if actual != nil {
+				anterior := actual.Geometria[len(actual.Geometria)-1]
+				actual.LongitudKm += haversine(anterior[0], anterior[1], coord[0], coord[1])
+				actual.Geometria = append(actual.Geometria, coord)
+			}
+
+			if usos[id] < 2 {
+				continue
+			}
+
+			intersecciones[id] = true
+			if actual != nil && actual.Desde != id {
+				actual.Hasta = id
+				tramos = append(tramos, *actual)
+			}
+			actual = &TramoVial{
+				Desde:     id,
+				Nombre:    calle.Nombre,
+				UnaMano:   calle.UnaMano,
+				Geometria: [][2]float64{coord},
+			}
+		}
+	}
+
+	ids := make([]int64, 0, len(intersecciones))
+	for id := range intersecciones {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	nodos := make([]NodoVial, 0, len(ids))
+	for _, id := range ids {
+		coord := coordenadas[id]
+		nodos = append(nodos, NodoVial{OsmID: id, Lat: coord[0], Lng: coord[1]})
+	}
+	return nodos, tramos
+}
@@ -0,0 +1,683 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+)
+
+// Estados de un evento del outbox
+const (
+	OutboxPendiente = "pendiente"
+	OutboxAplicado  = "aplicado"
+	// OutboxFallido agotó los reintentos automáticos (dead-letter) y requiere
+	// revisión: los eventos siguientes de su recurso esperan hasta que se
+	// reintente con éxito o se descarte
+	OutboxFallido = "fallido"
+	// OutboxDescartado es un fallido que un operador decidió no aplicar
+	OutboxDescartado = "descartado"
+)
+
+// Entidades que se sincronizan con Neo4j. La clave es el ID de MySQL; los
+// nodos sin fila que borra la reconciliación usan su custom id. Como el nodo
+// se identifica por custom id, una baja no se aplica si después se guardó
+// otro recurso con ese custom id (ver bajaReemplazada).
+const (
+	EntidadOutboxTacho  = "tacho"
+	EntidadOutboxCentro = "centro"
+)
+
+// Operaciones de un evento. Son idempotentes para poder reintentarlas.
+const (
+	outboxGuardar  = "guardar"
+	outboxEliminar = "eliminar"
+	// outboxDeduplicar deja un solo nodo con el custom id (el de menor elementId)
+	outboxDeduplicar = "deduplicar"
+)
+
+const (
+	maxIntentosOutbox = 8
+	esperaOutboxBase  = 5 * time.Second
+	esperaOutboxMax   = 10 * time.Minute
+	intervaloOutbox   = 10 * time.Second
+	loteOutbox        = 100
+	// Los eventos aplicados o descartados se borran pasada la retención,
+	// salvo el último de cada recurso (la reconciliación lo usa)
+	retencionOutbox         = 7 * 24 * time.Hour
+	intervaloLimpiezaOutbox = time.Hour
+)
+
+// Datos de cada operación sobre un tacho
+type outboxGuardarTachoPayload struct {
+	// IDActual es el id con el que se busca el nodo; Tacho.ID el que queda
+	IDActual string     `json:"id_actual"`
+	Tacho    TachoNeo4j `json:"tacho"`
+}
+
+type outboxEliminarTachoPayload struct {
+	ID string `json:"id"`
+}
+
+// outboxDeduplicarPayload es el custom id cuyos nodos repetidos se borran
+type outboxDeduplicarPayload struct {
+	ID string `json:"id"`
+}
+
+// Datos de cada operación sobre un centro
+type outboxGuardarCentroPayload struct {
+	// IDActual es el id con el que se busca el nodo; Centro.ID el que queda
+	IDActual string      `json:"id_actual"`
+	Centro   CentroNeo4j `json:"centro"`
+}
+
+type outboxEliminarCentroPayload struct {
+	ID string `json:"id"`
+}
+
+// EventoOutbox es un evento del outbox en la respuesta de la API
+type EventoOutbox struct {
+	IDEvento       int             `json:"id_evento"`
+	Entidad        string          `json:"entidad"`
+	Clave          string          `json:"clave"`
+	Operacion      string          `json:"operacion"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Estado         string          `json:"estado"`
+	Intentos       int             `json:"intentos"`
+	UltimoError    string          `json:"ultimo_error,omitempty"`
+	ProximoIntento time.Time       `json:"proximo_intento"`
+	CreadoEn       time.Time       `json:"creado_en"`
+	AplicadoEn     *time.Time      `json:"aplicado_en,omitempty"`
+}
+
+// ResumenOutbox indica cuánto falta sincronizar con Neo4j
+type ResumenOutbox struct {
+	Pendientes int `json:"pendientes"`
+	Fallidos   int `json:"fallidos"`
+	// LagSegundos es la antigüedad del evento pendiente más viejo
+	LagSegundos float64 `json:"lag_segundos"`
+}
+
+// outboxMu evita que el worker y un despacho inmediato apliquen a la vez
+var outboxMu sync.Mutex
+
+// encolarOutbox guarda un evento dentro de la transacción de la escritura en MySQL
+func encolarOutbox(tx *gorm.DB, entidad, clave, operacion string, payload interface{}) error {
+	datos, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding outbox payload: %v", err)
+	}
+
+	evento := models.OutboxEvento{
+		Entidad:        entidad,
+		Clave:          clave,
+		Operacion:      operacion,
+		Payload:        string(datos),
+		Estado:         OutboxPendiente,
+		ProximoIntento: time.Now(),
+	}
+	if err := tx.Create(&evento).Error; err != nil {
+		return fmt.Errorf("error inserting outbox event: %v", err)
+	}
+	return nil
+}
+
+// aplicarEventoOutbox ejecuta un evento en Neo4j a partir de su payload
+func aplicarEventoOutbox(entidad, operacion string, payload []byte) error {
+	switch entidad {
+	case EntidadOutboxTacho:
+		return aplicarOutboxTacho(operacion, payload)
+	case EntidadOutboxCentro:
+		return aplicarOutboxCentro(operacion, payload)
+	default:
+		return fmt.Errorf("entidad de outbox desconocida: %s", entidad)
+	}
+}
+
+func aplicarOutboxTacho(operacion string, payload []byte) error {
+	switch operacion {
+	case outboxGuardar:
+		var p outboxGuardarTachoPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return guardarTachoEnNeo4j(p.IDActual, p.Tacho)
+	case outboxEliminar:
+		var p outboxEliminarTachoPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return borrarTachoNeo4jPorID(p.ID)
+	case outboxDeduplicar:
+		var p outboxDeduplicarPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return deduplicarTachoNeo4j(p.ID)
+	default:
+		return fmt.Errorf("operación de outbox desconocida: %s", operacion)
+	}
+}
+
+// aplicarOutboxCentro ejecuta una operación sobre el nodo de un centro
+func aplicarOutboxCentro(operacion string, payload []byte) error {
+	switch operacion {
+	case outboxGuardar:
+		var p outboxGuardarCentroPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return guardarCentroEnNeo4j(p.IDActual, p.Centro)
+	case outboxEliminar:
+		var p outboxEliminarCentroPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return borrarCentroNeo4jPorID(p.ID)
+	case outboxDeduplicar:
+		var p outboxDeduplicarPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return deduplicarCentroNeo4j(p.ID)
+	default:
+		return fmt.Errorf("operación de outbox desconocida: %s", operacion)
+	}
+}
+
+// despachadorOutbox aplica eventos y guarda su resultado; las funciones se
+// reemplazan en los tests
+type despachadorOutbox struct {
+	aplicar     func(entidad, operacion string, payload []byte) error
+	guardar     func(evento *models.OutboxEvento) error
+	reemplazada func(evento *models.OutboxEvento) (bool, error)
+}
+
+func nuevoDespachadorOutbox() despachadorOutbox {
+	return despachadorOutbox{
+		aplicar:     aplicarEventoOutbox,
+		reemplazada: bajaReemplazada,
+		guardar: func(evento *models.OutboxEvento) error {
+			return config.DB.Save(evento).Error
+		},
+	}
+}
+
+// bajaReemplazada indica si después de una baja se encoló un guardar que deja
+// un nodo con el mismo custom id. Las claves son por ID de MySQL, así que ese
+// guardar puede ser de otro tacho y aplicarse antes; borrar el nodo entonces
+// borraría el del recurso nuevo.
+func bajaReemplazada(evento *models.OutboxEvento) (bool, error) {
+	var p outboxEliminarTachoPayload
+	if err := json.Unmarshal([]byte(evento.Payload), &p); err != nil {
+		return false, err
+	}
+	ruta := "$.tacho.id"
+	if evento.Entidad == EntidadOutboxCentro {
+		ruta = "$.centro.id"
+	}
+
+	var cantidad int64
+	if err := config.DB.Model(&models.OutboxEvento{}).
+		Where("entidad = ? AND operacion = ? AND id_evento > ? AND estado <> ?",
+			evento.Entidad, outboxGuardar, evento.IDEvento, OutboxDescartado).
+		Where("JSON_UNQUOTE(JSON_EXTRACT(payload, ?)) = ?", ruta, p.ID).
+		Count(&cantidad).Error; err != nil {
+		return false, fmt.Errorf("error querying outbox: %v", err)
+	}
+	return cantidad > 0, nil
+}
+
+// despachar procesa los eventos pendientes, que vienen ordenados por id. Los
+// eventos de un mismo recurso se aplican en orden: si uno falla, pasa a
+// fallido o todavía está esperando su reintento, los siguientes de esa clave esperan.
+func (d despachadorOutbox) despachar(eventos []models.OutboxEvento, ahora time.Time) int {
+	aplicados := 0
+	bloqueadas := make(map[string]bool)
+	for i := range eventos {
+		evento := &eventos[i]
+		clave := evento.Entidad + ":" + evento.Clave
+		if bloqueadas[clave] {
+			continue
+		}
+		if evento.ProximoIntento.After(ahora) {
+			bloqueadas[clave] = true
+			continue
+		}
+
+		d.procesar(evento, ahora)
+		if evento.Estado == OutboxAplicado {
+			aplicados++
+		}
+		if evento.Estado != OutboxAplicado {
+			bloqueadas[clave] = true
+		}
+	}
+	return aplicados
+}
+
+// procesar aplica un evento y guarda el resultado en la fila
+func (d despachadorOutbox) procesar(evento *models.OutboxEvento, ahora time.Time) {
+	err := d.aplicarEvento(evento)
+	evento.Intentos++
+	switch {
+	case err == nil:
+		evento.Estado = OutboxAplicado
+		evento.UltimoError = ""
+		evento.AplicadoEn = &ahora
+	case evento.Intentos >= maxIntentosOutbox:
+		evento.Estado = OutboxFallido
+		evento.UltimoError = err.Error()
+		log.Printf("❌ Evento de outbox %d (%s %s %s) pasó a fallido: %v",
+			evento.IDEvento, evento.Operacion, evento.Entidad, evento.Clave, err)
+	default:
+		evento.Estado = OutboxPendiente
+		evento.UltimoError = err.Error()
+		evento.ProximoIntento = ahora.Add(esperaExponencial(evento.Intentos, esperaOutboxBase, esperaOutboxMax))
+	}
+	middleware.IncrementOutboxProcesados(resultadoOutbox(evento.Estado))
+
+	if err := d.guardar(evento); err != nil {
+		log.Printf("Error guardando evento de outbox %d: %v", evento.IDEvento, err)
+	}
+}
+
+// aplicarEvento ejecuta el evento en Neo4j. Una baja reemplazada por un
+// guardar posterior con el mismo custom id se da por aplicada sin borrar nada.
+func (d despachadorOutbox) aplicarEvento(evento *models.OutboxEvento) error {
+	if evento.Operacion == outboxEliminar && d.reemplazada != nil {
+		reemplazada, err := d.reemplazada(evento)
+		if err != nil {
+			return err
+		}
+		if reemplazada {
+			log.Printf("⏭️ Evento de outbox %d (%s %s %s) omitido: el custom id se volvió a guardar después",
+				evento.IDEvento, evento.Operacion, evento.Entidad, evento.Clave)
+			return nil
+		}
+	}
+	return d.aplicar(evento.Entidad, evento.Operacion, []byte(evento.Payload))
+}
+
+// esperaExponencial duplica base por cada intento después del primero, sin pasar de max
+func esperaExponencial(intentos int, base, max time.Duration) time.Duration {
+	espera := base
+	for i := 1; i < intentos && espera < max; i++ {
+		espera *= 2
+	}
+	if espera > max {
+		espera = max
+	}
+	return espera
+}
+
+// resultadoOutbox es la etiqueta de la métrica de eventos procesados
+func resultadoOutbox(estado string) string {
+	if estado == OutboxPendiente {
+		return "error"
+	}
+	return estado
+}
+
+// procesarOutbox aplica los eventos pendientes; con clave solo los de ese recurso
+func procesarOutbox(entidad, clave string) {
+	if config.DB == nil {
+		return
+	}
+
+	outboxMu.Lock()
+	defer outboxMu.Unlock()
+
+	// Solo los eventos listos cuyo recurso no tiene uno anterior fallido o
+	// esperando su reintento; así los que esperan no ocupan el lote
+	ahora := time.Now()
+	query := config.DB.Table("Outbox_evento AS o").
+		Where("o.estado = ? AND o.proximo_intento <= ?", OutboxPendiente, ahora).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM Outbox_evento a
+			WHERE a.entidad = o.entidad AND a.clave = o.clave AND a.id_evento < o.id_evento
+			  AND (a.estado = ? OR (a.estado = ? AND a.proximo_intento > ?)))`,
+			OutboxFallido, OutboxPendiente, ahora).
+		Order("o.id_evento").Limit(loteOutbox)
+	if clave != "" {
+		query = query.Where("o.entidad = ? AND o.clave = ?", entidad, clave)
+	}
+	var eventos []models.OutboxEvento
+	if err := query.Find(&eventos).Error; err != nil {
+		log.Printf("Error querying outbox: %v", err)
+		return
+	}
+
+	nuevoDespachadorOutbox().despachar(eventos, ahora)
+	actualizarMetricasOutbox()
+}
+
+// despacharOutbox intenta aplicar enseguida los eventos de un recurso recién
+// escrito; si Neo4j falla los reintenta el worker
+func despacharOutbox(entidad, clave string) {
+	procesarOutbox(entidad, clave)
+}
+
+// ProcesarOutbox aplica en Neo4j los eventos pendientes cuyo reintento ya venció
+func ProcesarOutbox() {
+	procesarOutbox("", "")
+}
+
+// IniciarWorkerOutbox aplica periódicamente los eventos pendientes del outbox
+// y borra los viejos que ya no hacen falta
+func IniciarWorkerOutbox() {
+	go func() {
+		ticker := time.NewTicker(intervaloOutbox)
+		defer ticker.Stop()
+		limpieza := time.NewTicker(intervaloLimpiezaOutbox)
+		defer limpieza.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ProcesarOutbox()
+			case <-limpieza.C:
+				LimpiarOutbox()
+			}
+		}
+	}()
+}
+
+// LimpiarOutbox borra los eventos aplicados o descartados más viejos que la
+// retención. Se conserva el último evento de cada recurso aunque sea viejo.
+func LimpiarOutbox() {
+	if config.DB == nil {
+		return
+	}
+
+	// MySQL no deja usar la misma tabla en la subconsulta de un DELETE salvo
+	// a través de una tabla derivada
+	result := config.DB.Exec(`
+		DELETE FROM Outbox_evento
+		WHERE estado IN (?, ?) AND creado_en < ?
+		  AND id_evento NOT IN (
+			SELECT id_evento FROM (
+				SELECT MAX(id_evento) AS id_evento FROM Outbox_evento GROUP BY entidad, clave
+			) ultimos
+		  )
+	`, OutboxAplicado, OutboxDescartado, time.Now().Add(-retencionOutbox))
+	if result.Error != nil {
+		log.Printf("Error limpiando outbox: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🧹 Outbox: %d eventos viejos borrados", result.RowsAffected)
+	}
+}
+
+// getResumenOutbox cuenta los eventos pendientes y fallidos y calcula el lag
+func getResumenOutbox() (*ResumenOutbox, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var conteos []struct {
+		Estado   string
+		Cantidad int
+	}
+	if err := config.DB.Model(&models.OutboxEvento{}).Select("estado, COUNT(*) AS cantidad").
+		Where("estado IN ?", []string{OutboxPendiente, OutboxFallido}).Group("estado").Scan(&conteos).Error; err != nil {
+		return nil, fmt.Errorf("error counting outbox events: %v", err)
+	}
+
+	resumen := &ResumenOutbox{}
+	for _, conteo := range conteos {
+		if conteo.Estado == OutboxPendiente {
+			resumen.Pendientes = conteo.Cantidad
+		} else {
+			resumen.Fallidos = conteo.Cantidad
+		}
+	}
+
+	var masViejo []models.OutboxEvento
+	if err := config.DB.Where("estado = ?", OutboxPendiente).Order("id_evento").Limit(1).Find(&masViejo).Error; err != nil {
+		return nil, fmt.Errorf("error querying outbox lag: %v", err)
+	}
+	if len(masViejo) > 0 {
+		resumen.LagSegundos = time.Since(masViejo[0].CreadoEn).Seconds()
+	}
+	return resumen, nil
+}
+
+// actualizarMetricasOutbox publica el lag y los eventos por estado en Prometheus
+func actualizarMetricasOutbox() {
+	resumen, err := getResumenOutbox()
+	if err != nil {
+		log.Printf("Error updating outbox metrics: %v", err)
+		return
+	}
+	middleware.UpdateOutboxMetrics(resumen.LagSegundos, resumen.Pendientes, resumen.Fallidos)
+}
+
+// eventoOutboxDesdeModelo convierte una fila de Outbox_evento en la respuesta de la API
+func eventoOutboxDesdeModelo(fila models.OutboxEvento) EventoOutbox {
+	return EventoOutbox{
+		IDEvento:       int(fila.IDEvento),
+		Entidad:        fila.Entidad,
+		Clave:          fila.Clave,
+		Operacion:      fila.Operacion,
+		Payload:        json.RawMessage(fila.Payload),
+		Estado:         fila.Estado,
+		Intentos:       fila.Intentos,
+		UltimoError:    fila.UltimoError,
+		ProximoIntento: fila.ProximoIntento,
+		CreadoEn:       fila.CreadoEn,
+		AplicadoEn:     fila.AplicadoEn,
+	}
+}
+
+// GetEventosOutbox lista los eventos del outbox, opcionalmente por estado,
+// junto con el resumen de lo que falta sincronizar
+func GetEventosOutbox(estado string) ([]EventoOutbox, *ResumenOutbox, error) {
+	if config.DB == nil {
+		return nil, nil, fmt.Errorf("database connection not available")
+	}
+
+	query := config.DB.Order("id_evento DESC").Limit(500)
+	if estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+	var filas []models.OutboxEvento
+	if err := query.Find(&filas).Error; err != nil {
+		return nil, nil, fmt.Errorf("error querying outbox: %v", err)
+	}
+
+	resumen, err := getResumenOutbox()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eventos := make([]EventoOutbox, 0, len(filas))
+	for _, fila := range filas {
+		eventos = append(eventos, eventoOutboxDesdeModelo(fila))
+	}
+	return eventos, resumen, nil
+}
+
+// ReintentarEventoOutbox vuelve a poner en cola un evento pendiente o fallido
+// y lo aplica ahora, respetando el orden de los eventos de su recurso
+func ReintentarEventoOutbox(id int) (*EventoOutbox, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var fila models.OutboxEvento
+	result := config.DB.Where("id_evento = ?", id).Limit(1).Find(&fila)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying outbox event: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("evento with ID %d not found", id)
+	}
+	if fila.Estado == OutboxAplicado {
+		return nil, fmt.Errorf("transición inválida: el evento %d ya está %s", id, fila.Estado)
+	}
+
+	// Un reintento manual da otra tanda de intentos automáticos
+	if fila.Estado == OutboxFallido {
+		fila.Intentos = 0
+	}
+	fila.Estado = OutboxPendiente
+	fila.ProximoIntento = time.Now()
+	if err := config.DB.Save(&fila).Error; err != nil {
+		return nil, fmt.Errorf("error updating outbox event: %v", err)
+	}
+
+	despacharOutbox(fila.Entidad, fila.Clave)
+
+	if err := config.DB.Where("id_evento = ?", id).First(&fila).Error; err != nil {
+		return nil, fmt.Errorf("error querying outbox event: %v", err)
+	}
+	evento := eventoOutboxDesdeModelo(fila)
+	return &evento, nil
+}
+
+// DescartarEventoOutbox marca como descartado un evento fallido para que los
+// siguientes de su recurso se apliquen. Lo que el evento no aplicó queda para
+// la reconciliación (ver /admin/reconciliacion).
+func DescartarEventoOutbox(id int) (*EventoOutbox, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var fila models.OutboxEvento
+	result := config.DB.Where("id_evento = ?", id).Limit(1).Find(&fila)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying outbox event: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("evento with ID %d not found", id)
+	}
+	if fila.Estado != OutboxFallido {
+		return nil, fmt.Errorf("transición inválida: solo se descartan eventos fallidos y el %d está %s", id, fila.Estado)
+	}
+
+	fila.Estado = OutboxDescartado
+	if err := config.DB.Save(&fila).Error; err != nil {
+		return nil, fmt.Errorf("error updating outbox event: %v", err)
+	}
+
+	despacharOutbox(fila.Entidad, fila.Clave)
+
+	evento := eventoOutboxDesdeModelo(fila)
+	return &evento, nil
+}
+
+// estadoOutboxTachos resume los eventos de tachos: por id_tacho, los últimos
+// datos que MySQL escribió para Neo4j, y los custom ids con eventos sin aplicar
+func estadoOutboxTachos(eventos []models.OutboxEvento) (map[int]TachoNeo4j, map[string]bool) {
+	ultimos := make(map[int]TachoNeo4j)
+	pendientes := make(map[string]bool)
+	for _, evento := range eventos {
+		// La clave no es numérica en los borrados de nodos sin fila
+		idTacho, errClave := strconv.Atoi(evento.Clave)
+
+		switch evento.Operacion {
+		case outboxGuardar:
+			var p outboxGuardarTachoPayload
+			if errClave != nil || json.Unmarshal([]byte(evento.Payload), &p) != nil {
+				continue
+			}
+			ultimos[idTacho] = p.Tacho
+			if evento.Estado == OutboxPendiente {
+				pendientes[p.IDActual] = true
+				pendientes[p.Tacho.ID] = true
+			}
+		case outboxEliminar, outboxDeduplicar:
+			var p outboxEliminarTachoPayload
+			if err := json.Unmarshal([]byte(evento.Payload), &p); err != nil {
+				continue
+			}
+			if evento.Operacion == outboxEliminar && errClave == nil {
+				delete(ultimos, idTacho)
+			}
+			if evento.Estado == OutboxPendiente {
+				pendientes[p.ID] = true
+			}
+		}
+	}
+	return ultimos, pendientes
+}
+
+// estadoOutboxCentros es estadoOutboxTachos para los centros, por id_centro
+func estadoOutboxCentros(eventos []models.OutboxEvento) (map[int]CentroNeo4j, map[string]bool) {
+	ultimos := make(map[int]CentroNeo4j)
+	pendientes := make(map[string]bool)
+	for _, evento := range eventos {
+		idCentro, errClave := strconv.Atoi(evento.Clave)
+
+		switch evento.Operacion {
+		case outboxGuardar:
+			var p outboxGuardarCentroPayload
+			if errClave != nil || json.Unmarshal([]byte(evento.Payload), &p) != nil {
+				continue
+			}
+			ultimos[idCentro] = p.Centro
+			if evento.Estado == OutboxPendiente {
+				pendientes[p.IDActual] = true
+				pendientes[p.Centro.ID] = true
+			}
+		case outboxEliminar, outboxDeduplicar:
+			var p outboxEliminarCentroPayload
+			if err := json.Unmarshal([]byte(evento.Payload), &p); err != nil {
+				continue
+			}
+			if evento.Operacion == outboxEliminar && errClave == nil {
+				delete(ultimos, idCentro)
+			}
+			if evento.Estado == OutboxPendiente {
+				pendientes[p.ID] = true
+			}
+		}
+	}
+	return ultimos, pendientes
+}
+
+// getUltimosEventosOutbox lee el último evento de cada recurso de la entidad y
+// los pendientes: no hace falta leer todo el historial
+func getUltimosEventosOutbox(entidad string) ([]models.OutboxEvento, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var eventos []models.OutboxEvento
+	if err := config.DB.Select("id_evento, clave, operacion, payload, estado").
+		Where("entidad = ?", entidad).
+		Where("estado = ? OR id_evento IN (SELECT MAX(id_evento) FROM Outbox_evento WHERE entidad = ? GROUP BY clave)",
+			OutboxPendiente, entidad).
+		Order("id_evento").Find(&eventos).Error; err != nil {
+		return nil, fmt.Errorf("error querying outbox: %v", err)
+	}
+	return eventos, nil
+}
+
+// getEstadoOutboxTachos resume los eventos de tachos (ver estadoOutboxTachos)
+func getEstadoOutboxTachos() (map[int]TachoNeo4j, map[string]bool, error) {
+	eventos, err := getUltimosEventosOutbox(EntidadOutboxTacho)
+	if err != nil {
+		return nil, nil, err
+	}
+	ultimos, pendientes := estadoOutboxTachos(eventos)
+	return ultimos, pendientes, nil
+}
+
+// getEstadoOutboxCentros resume los eventos de centros (ver estadoOutboxCentros)
+func getEstadoOutboxCentros() (map[int]CentroNeo4j, map[string]bool, error) {
+	eventos, err := getUltimosEventosOutbox(EntidadOutboxCentro)
+	if err != nil {
+		return nil, nil, err
+	}
+	ultimos, pendientes := estadoOutboxCentros(eventos)
+	return ultimos, pendientes, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// despachadorDePrueba cuenta los eventos guardados y falla los de las claves indicadas
+func despachadorDePrueba(fallan ...string) (despachadorOutbox, *int) {
+	guardados := 0
+	d := despachadorOutbox{
+		guardar: func(evento *models.OutboxEvento) error {
+			guardados++
+			return nil
+		},
+	}
+	d.aplicar = func(entidad, operacion string, payload []byte) error {
+		for _, clave := range fallan {
+			if string(payload) == clave {
+				return errors.New("neo4j caído")
+			}
+		}
+		return nil
+	}
+	return d, &guardados
+}
+
+func eventoDePrueba(id int64, clave string, proximo time.Time) models.OutboxEvento {
+	return models.OutboxEvento{
+		IDEvento:       id,
+		Entidad:        EntidadOutboxTacho,
+		Clave:          clave,
+		Operacion:      outboxGuardar,
+		Payload:        clave,
+		Estado:         OutboxPendiente,
+		ProximoIntento: proximo,
+	}
+}
+
+func TestDespacharOutboxAplicaEnOrden(t *testing.T) {
+	ahora := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	d, guardados := despachadorDePrueba()
+	eventos := []models.OutboxEvento{
+		eventoDePrueba(1, "7", ahora),
+		eventoDePrueba(2, "8", ahora),
+	}
+
+	assert.Equal(t, 2, d.despachar(eventos, ahora))
+	assert.Equal(t, 2, *guardados)
+	for _, evento := range eventos {
+		assert.Equal(t, OutboxAplicado, evento.Estado)
+		assert.Equal(t, 1, evento.Intentos)
+		assert.Equal(t, &ahora, evento.AplicadoEn)
+	}
+}
+
+func TestDespacharOutboxBloqueaLaClaveQueFalla(t *testing.T) {
+	ahora := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	d, _ := despachadorDePrueba("7")
+	eventos := []models.OutboxEvento{
+		eventoDePrueba(1, "7", ahora),
+		eventoDePrueba(2, "8", ahora),
+		eventoDePrueba(3, "7", ahora),
+	}
+
+	assert.Equal(t, 1, d.despachar(eventos, ahora))
+
+	assert.Equal(t, OutboxPendiente, eventos[0].Estado)
+	assert.Equal(t, "neo4j caído", eventos[0].UltimoError)
+	assert.Equal(t, ahora.Add(esperaOutboxBase), eventos[0].ProximoIntento)
+	assert.Equal(t, OutboxAplicado, eventos[1].Estado, "otra clave no espera")
+	assert.Equal(t, 0, eventos[2].Intentos, "el siguiente evento de la clave espera al anterior")
+}
+
+func TestDespacharOutboxRespetaElReintento(t *testing.T) {
+	ahora := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	d, guardados := despachadorDePrueba()
+	eventos := []models.OutboxEvento{
+		eventoDePrueba(1, "7", ahora.Add(time.Minute)),
+		eventoDePrueba(2, "7", ahora),
+	}
+
+	assert.Equal(t, 0, d.despachar(eventos, ahora))
+	assert.Equal(t, 0, *guardados)
+	assert.Equal(t, OutboxPendiente, eventos[1].Estado)
+}
+
+func TestDespacharOutboxFallidoRetieneLaClave(t *testing.T) {
+	ahora := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	d, _ := despachadorDePrueba("7")
+	evento := eventoDePrueba(1, "7", ahora)
+	evento.Intentos = maxIntentosOutbox - 1
+	siguiente := eventoDePrueba(2, "7", ahora)
+	siguiente.Payload = "otro"
+	eventos := []models.OutboxEvento{evento, siguiente}
+
+	assert.Equal(t, 0, d.despachar(eventos, ahora))
+	assert.Equal(t, OutboxFallido, eventos[0].Estado)
+	assert.Equal(t, maxIntentosOutbox, eventos[0].Intentos)
+	assert.Equal(t, OutboxPendiente, eventos[1].Estado, "un evento fallido retiene los siguientes de su clave")
+	assert.Equal(t, 0, eventos[1].Intentos)
+}
+
+func TestDespacharOutboxOmiteBajaReemplazada(t *testing.T) {
+	ahora := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	d, _ := despachadorDePrueba()
+	aplicados := 0
+	d.aplicar = func(entidad, operacion string, payload []byte) error {
+		aplicados++
+		return nil
+	}
+	d.reemplazada = func(evento *models.OutboxEvento) (bool, error) {
+		return evento.IDEvento == 1, nil
+	}
+	baja := eventoDePrueba(1, "5", ahora)
+	baja.Operacion = outboxEliminar
+	otra := eventoDePrueba(2, "6", ahora)
+	otra.Operacion = outboxEliminar
+	eventos := []models.OutboxEvento{baja, otra}
+
+	assert.Equal(t, 2, d.despachar(eventos, ahora))
+	assert.Equal(t, OutboxAplicado, eventos[0].Estado, "la baja reemplazada se da por aplicada")
+	assert.Equal(t, 1, aplicados, "solo se borra el nodo de la baja vigente")
+}
+
+func TestEsperaOutbox(t *testing.T) {
+	assert.Equal(t, 5*time.Second, esperaExponencial(1, esperaOutboxBase, esperaOutboxMax))
+	assert.Equal(t, 40*time.Second, esperaExponencial(4, esperaOutboxBase, esperaOutboxMax))
+	assert.Equal(t, esperaOutboxMax, esperaExponencial(20, esperaOutboxBase, esperaOutboxMax))
+}
+
+func TestAplicarEventoOutboxDesconocido(t *testing.T) {
+	assert.EqualError(t, aplicarEventoOutbox("camion", outboxGuardar, nil), "entidad de outbox desconocida: camion")
+	assert.EqualError(t, aplicarEventoOutbox(EntidadOutboxTacho, "mover", nil), "operación de outbox desconocida: mover")
+}
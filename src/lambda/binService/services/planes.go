@@ -0,0 +1,442 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Estados de una ruta planificada
+const (
+	EstadoPlanificada = "planificada"
+	EstadoEnCurso     = "en_curso"
+	EstadoCompletada  = "completada"
+	EstadoCancelada   = "cancelada"
+)
+
+// transicionesPlan indica a qué estados puede pasar una ruta desde cada estado
+var transicionesPlan = map[string][]string{
+	EstadoPlanificada: {EstadoEnCurso, EstadoCancelada},
+	EstadoEnCurso:     {EstadoCompletada, EstadoCancelada},
+}
+
+// PlanRuta es una ruta guardada con su asignación y estado
+type PlanRuta struct {
+	IDRuta            int           `json:"id_ruta"`
+	IDZona            int           `json:"id_zona"`
+	IDCamion          int           `json:"id_camion,omitempty"`
+	IDPersona         string        `json:"id_persona,omitempty"`
+	Estado            string        `json:"estado"`
+	Algoritmo         string        `json:"algoritmo"`
+	DistanciaTotal    float64       `json:"distancia_total_km"`
+	TipoDistancia     string        `json:"tipo_distancia,omitempty"`
+	CapacidadLitros   float64       `json:"capacidad_camion_litros,omitempty"`
+	Descargas         int           `json:"descargas,omitempty"`
+	Omitidos          int           `json:"tachos_omitidos,omitempty"`
+	CantidadParadas   int           `json:"cantidad_paradas"`
+	MotivoCancelacion string        `json:"motivo_cancelacion,omitempty"`
+	CreadaEn          time.Time     `json:"creada_en"`
+	ActualizadaEn     time.Time     `json:"actualizada_en"`
+	IniciadaEn        *time.Time    `json:"iniciada_en,omitempty"`
+	FinalizadaEn      *time.Time    `json:"finalizada_en,omitempty"`
+	ETA               *time.Time    `json:"eta,omitempty"`
+	Revision          int           `json:"revision,omitempty"`
+	IDCalendario      *int64        `json:"id_calendario,omitempty"`
+	ProgramadaPara    *time.Time    `json:"programada_para,omitempty"`
+	Progreso          *ProgresoPlan `json:"progreso,omitempty"`
+	Paradas           []ParadaPlan  `json:"paradas,omitempty"`
+	Polilinea         [][2]float64  `json:"polilinea,omitempty"`
+}
+
+// CrearPlanRequest representa los datos para planificar y guardar una ruta
+type CrearPlanRequest struct {
+	IDZona        int      `json:"id_zona" example:"1"`
+	IDCamion      int      `json:"id_camion" example:"2"`
+	IDPersona     string   `json:"id_persona" example:"3"`
+	Algoritmo     string   `json:"algoritmo" example:"completo"`
+	OrigenCentro  int      `json:"origen_centro"`
+	DestinoCentro int      `json:"destino_centro"`
+	MinCapacidad  float64  `json:"min_capacidad"`
+	PesoPrioridad *float64 `json:"peso_prioridad"`
+}
+
+// CancelarPlanRequest representa el motivo de cancelación de una ruta
+type CancelarPlanRequest struct {
+	Motivo string `json:"motivo" example:"Camión en el taller"`
+}
+
+// FiltroPlanes filtra el listado de rutas; los campos vacíos no filtran
+type FiltroPlanes struct {
+	Fecha     *time.Time
+	IDZona    int
+	IDCamion  int
+	IDPersona string
+	Estado    string
+}
+
+// PlanesResponse es el listado de rutas guardadas
+type PlanesResponse struct {
+	Rutas []PlanRuta `json:"rutas"`
+	Total int        `json:"total"`
+}
+
+// ValidarEstadoPlan verifica que el estado sea uno de los conocidos
+func ValidarEstadoPlan(estado string) error {
+	switch estado {
+	case EstadoPlanificada, EstadoEnCurso, EstadoCompletada, EstadoCancelada:
+		return nil
+	default:
+		return fmt.Errorf("estado desconocido: %s (opciones: %s, %s, %s, %s)",
+			estado, EstadoPlanificada, EstadoEnCurso, EstadoCompletada, EstadoCancelada)
+	}
+}
+
+// puedeTransicionar indica si una ruta puede pasar de un estado a otro
+func puedeTransicionar(desde, hacia string) bool {
+	for _, estado := range transicionesPlan[desde] {
+		if estado == hacia {
+			return true
+		}
+	}
+	return false
+}
+
+// CrearPlanRuta calcula la ruta de una zona y la guarda como planificada.
+// Si se indica persona, su zona y camión se usan cuando no vienen en el pedido.
+func CrearPlanRuta(request CrearPlanRequest) (*PlanRuta, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	ruta, err := calcularRutaPlan(&request, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return GuardarPlanRuta(request.IDZona, request.IDCamion, request.IDPersona, ruta)
+}
+
+// calcularRutaPlan completa zona y camión desde la persona y calcula la ruta
+// del pedido. La ruta sale en salida: los tachos que no entran en sus ventanas
+// horarias o en el turno de la persona quedan omitidos.
+func calcularRutaPlan(request *CrearPlanRequest, salida time.Time) (*Ruta, error) {
+	var persona map[string]interface{}
+	if request.IDPersona != "" {
+		var err error
+		persona, err = GetPersonaByKey("persona:" + request.IDPersona)
+		if err != nil {
+			return nil, fmt.Errorf("persona with ID %s not found", request.IDPersona)
+		}
+		if request.IDZona == 0 {
+			request.IDZona, _ = strconv.Atoi(fmt.Sprint(persona["zona_id"]))
+		}
+		if request.IDCamion == 0 {
+			request.IDCamion, _ = strconv.Atoi(fmt.Sprint(persona["camion_id"]))
+		}
+	}
+	if request.IDZona <= 0 {
+		return nil, fmt.Errorf("id_zona es requerido")
+	}
+
+	opciones := RutaOpciones{
+		Algoritmo:    request.Algoritmo,
+		IDCamion:     request.IDCamion,
+		MinCapacidad: request.MinCapacidad,
+		horario:      nuevoHorarioRuta(salida, persona),
+	}
+	if request.PesoPrioridad != nil {
+		opciones.Priorizar = true
+		opciones.PesoPrioridad = *request.PesoPrioridad
+	}
+	if err := ValidarSeleccion(opciones.MinCapacidad, opciones.PesoPrioridad); err != nil {
+		return nil, err
+	}
+	if request.OrigenCentro > 0 {
+		origen, err := GetCentroPoint(request.OrigenCentro, TipoPuntoOrigen)
+		if err != nil {
+			return nil, err
+		}
+		opciones.Origen = origen
+	}
+	if request.DestinoCentro > 0 {
+		destino, err := GetCentroPoint(request.DestinoCentro, TipoPuntoDestino)
+		if err != nil {
+			return nil, err
+		}
+		opciones.Destino = destino
+	}
+
+	return GetDistances(request.IDZona, opciones)
+}
+
+// GuardarPlanRuta guarda una ruta calculada con sus paradas como planificada
+func GuardarPlanRuta(zonaID, camionID int, personaID string, ruta *Ruta) (*PlanRuta, error) {
+	return guardarPlan(models.RutaPlan{
+		IDZona:    int64(zonaID),
+		IDCamion:  int64(camionID),
+		IDPersona: personaID,
+	}, ruta)
+}
+
+// guardarPlan completa el plan con los datos de la ruta y lo guarda con sus paradas
+func guardarPlan(plan models.RutaPlan, ruta *Ruta) (*PlanRuta, error) {
+	polilinea, err := json.Marshal(ruta.Polilinea)
+	if err != nil {
+		return nil, fmt.Errorf("error serializando polilínea: %v", err)
+	}
+
+	plan.Algoritmo = ruta.Algoritmo
+	plan.Estado = EstadoPlanificada
+	plan.DistanciaTotal = ruta.DistanciaTotal
+	plan.TipoDistancia = ruta.TipoDistancia
+	plan.CapacidadLitros = ruta.CapacidadLitros
+	plan.Descargas = ruta.Descargas
+	plan.Omitidos = ruta.Omitidos
+	plan.Polilinea = string(polilinea)
+	plan.Revision = 1
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&plan).Error; err != nil {
+			return fmt.Errorf("error inserting ruta: %w", err)
+		}
+
+		paradas := make([]models.RutaParada, 0, len(ruta.Puntos))
+		for i, p := range ruta.Puntos {
+			paradas = append(paradas, paradaDesdePunto(plan.IDRuta, i+1, p))
+		}
+		if len(paradas) > 0 {
+			if err := tx.CreateInBatches(paradas, 200).Error; err != nil {
+				return fmt.Errorf("error inserting paradas: %v", err)
+			}
+		}
+		return guardarRevision(tx, plan, paradas, models.RutaRevision{Motivo: MotivoPlanInicial})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPlanRuta(int(plan.IDRuta))
+}
+
+// paradaDesdePunto convierte una parada de la ruta en su fila de Ruta_parada
+func paradaDesdePunto(rutaID int64, secuencia int, p Point) models.RutaParada {
+	estado := ""
+	if p.Tipo == TipoPuntoTacho {
+		estado = EstadoParadaPendiente
+	}
+	return models.RutaParada{
+		IDRuta:             rutaID,
+		Secuencia:          secuencia,
+		Tipo:               p.Tipo,
+		IDPunto:            p.ID,
+		IDTacho:            p.IDTacho,
+		IDNeo:              p.CustomID,
+		Nombre:             p.Nombre,
+		Direccion:          p.Direccion,
+		Lat:                p.Lat,
+		Lng:                p.Lng,
+		Capacidad:          p.Capacidad,
+		Prioridad:          p.Prioridad,
+		Urgencia:           p.Urgencia,
+		Volumen:            p.Volumen,
+		Carga:              p.Carga,
+		DistanciaTramo:     p.DistanciaTramo,
+		DistanciaAcumulada: p.DistanciaAcumulada,
+		Estado:             estado,
+	}
+}
+
+// puntoDesdeParada convierte una fila de Ruta_parada en parada de la ruta
+func puntoDesdeParada(parada models.RutaParada) Point {
+	return Point{
+		ID:                 parada.IDPunto,
+		Tipo:               parada.Tipo,
+		Nombre:             parada.Nombre,
+		IDTacho:            parada.IDTacho,
+		CustomID:           parada.IDNeo,
+		Direccion:          parada.Direccion,
+		Lat:                parada.Lat,
+		Lng:                parada.Lng,
+		Capacidad:          parada.Capacidad,
+		Prioridad:          parada.Prioridad,
+		Urgencia:           parada.Urgencia,
+		Volumen:            parada.Volumen,
+		Carga:              parada.Carga,
+		Secuencia:          parada.Secuencia,
+		DistanciaTramo:     parada.DistanciaTramo,
+		DistanciaAcumulada: parada.DistanciaAcumulada,
+	}
+}
+
+// planDesdeModelo convierte la fila de Ruta_plan en la respuesta de la API
+func planDesdeModelo(plan models.RutaPlan) PlanRuta {
+	return PlanRuta{
+		IDRuta:            int(plan.IDRuta),
+		IDZona:            int(plan.IDZona),
+		IDCamion:          int(plan.IDCamion),
+		IDPersona:         plan.IDPersona,
+		Estado:            plan.Estado,
+		Algoritmo:         plan.Algoritmo,
+		DistanciaTotal:    plan.DistanciaTotal,
+		TipoDistancia:     plan.TipoDistancia,
+		CapacidadLitros:   plan.CapacidadLitros,
+		Descargas:         plan.Descargas,
+		Omitidos:          plan.Omitidos,
+		MotivoCancelacion: plan.MotivoCancelacion,
+		CreadaEn:          plan.CreadaEn,
+		ActualizadaEn:     plan.ActualizadaEn,
+		IniciadaEn:        plan.IniciadaEn,
+		FinalizadaEn:      plan.FinalizadaEn,
+		ETA:               plan.ETA,
+		Revision:          plan.Revision,
+		IDCalendario:      plan.IDCalendario,
+		ProgramadaPara:    plan.ProgramadaPara,
+	}
+}
+
+// getPlanModelo obtiene la fila de Ruta_plan de una ruta
+func getPlanModelo(tx *gorm.DB, rutaID int) (*models.RutaPlan, error) {
+	var plan models.RutaPlan
+	result := tx.Where("id_ruta = ?", rutaID).Limit(1).Find(&plan)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying ruta: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("ruta with ID %d not found", rutaID)
+	}
+	return &plan, nil
+}
+
+// GetPlanRuta obtiene una ruta guardada con sus paradas y polilínea
+func GetPlanRuta(rutaID int) (*PlanRuta, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	modelo, err := getPlanModelo(config.DB, rutaID)
+	if err != nil {
+		return nil, err
+	}
+
+	var paradas []models.RutaParada
+	if err := config.DB.Where("id_ruta = ?", rutaID).Order("secuencia").Find(&paradas).Error; err != nil {
+		return nil, fmt.Errorf("error querying paradas: %v", err)
+	}
+
+	plan := planDesdeModelo(*modelo)
+	plan.CantidadParadas = len(paradas)
+	plan.Paradas = paradasPlan(paradas)
+	progreso := calcularProgreso(paradas, modelo.DistanciaTotal)
+	plan.Progreso = &progreso
+	if modelo.Polilinea != "" {
+		if err := json.Unmarshal([]byte(modelo.Polilinea), &plan.Polilinea); err != nil {
+			return nil, fmt.Errorf("error leyendo polilínea: %v", err)
+		}
+	}
+
+	return &plan, nil
+}
+
+// GetPlanesRuta lista las rutas guardadas, de la más nueva a la más vieja
+func GetPlanesRuta(filtro FiltroPlanes) (*PlanesResponse, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	query := config.DB.Model(&models.RutaPlan{})
+	if filtro.Fecha != nil {
+		desde := time.Date(filtro.Fecha.Year(), filtro.Fecha.Month(), filtro.Fecha.Day(), 0, 0, 0, 0, config.ZonaHoraria())
+		query = query.Where("creada_en >= ? AND creada_en < ?", desde, desde.AddDate(0, 0, 1))
+	}
+	if filtro.IDZona > 0 {
+		query = query.Where("id_zona = ?", filtro.IDZona)
+	}
+	if filtro.IDCamion > 0 {
+		query = query.Where("id_camion = ?", filtro.IDCamion)
+	}
+	if filtro.IDPersona != "" {
+		query = query.Where("id_persona = ?", filtro.IDPersona)
+	}
+	if filtro.Estado != "" {
+		query = query.Where("estado = ?", filtro.Estado)
+	}
+
+	var modelos []models.RutaPlan
+	if err := query.Order("creada_en DESC, id_ruta DESC").Find(&modelos).Error; err != nil {
+		return nil, fmt.Errorf("error querying rutas: %v", err)
+	}
+
+	// Cantidad de paradas por ruta en una sola consulta
+	cantidades := make(map[int64]int)
+	if len(modelos) > 0 {
+		ids := make([]int64, 0, len(modelos))
+		for _, m := range modelos {
+			ids = append(ids, m.IDRuta)
+		}
+		var filas []struct {
+			IDRuta   int64 `gorm:"column:id_ruta"`
+			Cantidad int   `gorm:"column:cantidad"`
+		}
+		if err := config.DB.Raw("SELECT id_ruta, COUNT(*) AS cantidad FROM Ruta_parada WHERE id_ruta IN ? GROUP BY id_ruta", ids).Scan(&filas).Error; err != nil {
+			return nil, fmt.Errorf("error counting paradas: %v", err)
+		}
+		for _, fila := range filas {
+			cantidades[fila.IDRuta] = fila.Cantidad
+		}
+	}
+
+	rutas := make([]PlanRuta, 0, len(modelos))
+	for _, m := range modelos {
+		plan := planDesdeModelo(m)
+		plan.CantidadParadas = cantidades[m.IDRuta]
+		rutas = append(rutas, plan)
+	}
+
+	return &PlanesResponse{Rutas: rutas, Total: len(rutas)}, nil
+}
+
+// cambiarEstadoPlan pasa una ruta a otro estado si la transición es válida.
+// Bloquea la fila para que dos cambios simultáneos no se pisen.
+func cambiarEstadoPlan(tx *gorm.DB, rutaID int, estado string, cambios map[string]interface{}) error {
+	plan, err := getPlanModelo(tx.Clauses(clause.Locking{Strength: "UPDATE"}), rutaID)
+	if err != nil {
+		return err
+	}
+	if !puedeTransicionar(plan.Estado, estado) {
+		return fmt.Errorf("transición inválida: la ruta %d está %s y no puede pasar a %s", rutaID, plan.Estado, estado)
+	}
+
+	if cambios == nil {
+		cambios = map[string]interface{}{}
+	}
+	cambios["estado"] = estado
+	if err := tx.Model(&models.RutaPlan{}).Where("id_ruta = ?", rutaID).Updates(cambios).Error; err != nil {
+		return fmt.Errorf("error updating ruta: %v", err)
+	}
+	return nil
+}
+
+// CancelarPlanRuta cancela una ruta planificada o en curso
+func CancelarPlanRuta(rutaID int, motivo string) (*PlanRuta, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return cambiarEstadoPlan(tx, rutaID, EstadoCancelada, map[string]interface{}{
+			"motivo_cancelacion": motivo,
+			"finalizada_en":      time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPlanRuta(rutaID)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPuedeTransicionar(t *testing.T) {
+	assert.True(t, puedeTransicionar(EstadoPlanificada, EstadoEnCurso))
+	assert.True(t, puedeTransicionar(EstadoPlanificada, EstadoCancelada))
+	assert.True(t, puedeTransicionar(EstadoEnCurso, EstadoCompletada))
+	assert.True(t, puedeTransicionar(EstadoEnCurso, EstadoCancelada))
+
+	assert.False(t, puedeTransicionar(EstadoPlanificada, EstadoCompletada), "no se completa sin iniciar")
+	assert.False(t, puedeTransicionar(EstadoCompletada, EstadoCancelada))
+	assert.False(t, puedeTransicionar(EstadoCancelada, EstadoEnCurso))
+}
+
+func TestValidarEstadoPlan(t *testing.T) {
+	assert.NoError(t, ValidarEstadoPlan(EstadoEnCurso))
+	assert.Error(t, ValidarEstadoPlan("pausada"))
+	assert.Error(t, ValidarEstadoPlan(""))
+}
+
+func TestParadaDesdePunto_IdaYVuelta(t *testing.T) {
+	p := Point{
+		ID: 7, Tipo: TipoPuntoTacho, Nombre: "Tacho 7", IDTacho: 12, CustomID: "Av. Rivadavia 100|Caballito",
+		Direccion: "Av. Rivadavia 100", Lat: -34.61, Lng: -58.43, Capacidad: 80, Prioridad: 2,
+		Urgencia: 0.7, Volumen: 960, Carga: 1920, Secuencia: 3, DistanciaTramo: 0.4, DistanciaAcumulada: 1.2,
+	}
+
+	parada := paradaDesdePunto(5, 3, p)
+	assert.Equal(t, int64(5), parada.IDRuta)
+	assert.Equal(t, 3, parada.Secuencia)
+	assert.Equal(t, p, puntoDesdeParada(parada))
+}
@@ -0,0 +1,692 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+)
+
+// Base que se toma como verdadera al corregir
+const (
+	FuenteMySQL = "mysql"
+	FuenteNeo4j = "neo4j"
+)
+
+// Entidades que se comparan
+const (
+	EntidadTacho  = "tacho"
+	EntidadCentro = "centro"
+)
+
+// Tipos de inconsistencia entre MySQL y Neo4j
+const (
+	// InconsistenciaSinNodo es una fila de MySQL cuyo id_neo no tiene nodo
+	InconsistenciaSinNodo = "sin_nodo"
+	// InconsistenciaSinFila es un nodo de Neo4j sin fila en MySQL
+	InconsistenciaSinFila = "sin_fila"
+	// InconsistenciaCoordenadas es un nodo sin ubicación o con una ubicación
+	// distinta de la que MySQL registró en el outbox
+	InconsistenciaCoordenadas = "coordenadas"
+	// InconsistenciaDuplicado es un custom id repetido en una de las bases
+	InconsistenciaDuplicado = "duplicado"
+)
+
+// toleranciaCoordenadasKm es la diferencia de ubicación que se ignora (1 m)
+const toleranciaCoordenadasKm = 0.001
+
+// Inconsistencia es una diferencia entre MySQL y Neo4j para un custom id
+type Inconsistencia struct {
+	Entidad  string `json:"entidad"`
+	Tipo     string `json:"tipo"`
+	CustomID string `json:"custom_id"`
+	// Base donde está el problema en los duplicados (mysql o neo4j)
+	Base    string   `json:"base,omitempty"`
+	IDs     []int    `json:"ids_mysql,omitempty"`
+	NodeIDs []string `json:"node_ids,omitempty"`
+	Detalle string   `json:"detalle"`
+	// Corregible indica si el reconciliador sabe corregirla
+	Corregible bool `json:"corregible"`
+	// Resultado de la corrección, si se pidió
+	Corregida bool   `json:"corregida"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReconciliarRequest indica si corregir y con qué base como verdadera
+type ReconciliarRequest struct {
+	// Fuente es la base verdadera: mysql o neo4j
+	Fuente string `json:"fuente"`
+	// Tipos limita la corrección a esos tipos de inconsistencia; vacío corrige todos
+	Tipos []string `json:"tipos,omitempty"`
+	// IDTipo e IDEstado se usan al crear en MySQL un tacho que solo existe en Neo4j
+	IDTipo   int `json:"id_tipo,omitempty"`
+	IDEstado int `json:"id_estado,omitempty"`
+	// IDTipoCentro se usa al crear en MySQL un centro que solo existe en Neo4j
+	IDTipoCentro int `json:"id_tipo_centro,omitempty"`
+}
+
+// ReporteReconciliacion es el resultado de comparar (y opcionalmente corregir) las bases
+type ReporteReconciliacion struct {
+	Fuente          string           `json:"fuente,omitempty"`
+	TachosMySQL     int              `json:"tachos_mysql"`
+	TachosNeo4j     int              `json:"tachos_neo4j"`
+	CentrosMySQL    int              `json:"centros_mysql"`
+	CentrosNeo4j    int              `json:"centros_neo4j"`
+	Inconsistencias []Inconsistencia `json:"inconsistencias"`
+	Total           int              `json:"total"`
+	Corregidas      int              `json:"corregidas"`
+	GeneradoEn      time.Time        `json:"generado_en"`
+}
+
+// estadoTachos es lo que se compara de los tachos
+type estadoTachos struct {
+	filas []TachoMySQL
+	nodos []TachoNeo4j
+	// ultimos son los datos que MySQL escribió para Neo4j (ver outbox), por id_tacho
+	ultimos map[int]TachoNeo4j
+	// pendientes son los custom ids con eventos sin aplicar: todavía se están sincronizando
+	pendientes map[string]bool
+}
+
+// estadoCentros es lo que se compara de los centros
+type estadoCentros struct {
+	filas []centroFila
+	nodos []nodoCentro
+	// ultimos son los datos que MySQL escribió para Neo4j, por id_centro
+	ultimos    map[int]CentroNeo4j
+	pendientes map[string]bool
+}
+
+// ValidarReconciliar revisa la fuente y los tipos pedidos
+func ValidarReconciliar(request *ReconciliarRequest) error {
+	request.Fuente = strings.ToLower(strings.TrimSpace(request.Fuente))
+	if request.Fuente != FuenteMySQL && request.Fuente != FuenteNeo4j {
+		return fmt.Errorf("reconciliación inválida: fuente %q (use %s o %s)", request.Fuente, FuenteMySQL, FuenteNeo4j)
+	}
+	for _, tipo := range request.Tipos {
+		switch tipo {
+		case InconsistenciaSinNodo, InconsistenciaSinFila, InconsistenciaCoordenadas, InconsistenciaDuplicado:
+		default:
+			return fmt.Errorf("reconciliación inválida: tipo de inconsistencia desconocido %q", tipo)
+		}
+	}
+	return nil
+}
+
+// filaReconciliable y nodoReconciliable son lo que se compara de una fila de
+// MySQL y de un nodo de Neo4j, sea de un tacho o de un centro
+type filaReconciliable struct {
+	ID       int
+	CustomID string
+}
+
+type nodoReconciliable struct {
+	NodeID   string
+	CustomID string
+	Lat      float64
+	Lng      float64
+}
+
+// estadoEntidad es el estado de una entidad en las dos bases, listo para comparar
+type estadoEntidad struct {
+	entidad string
+	filas   []filaReconciliable
+	nodos   []nodoReconciliable
+	// ultimos son las ubicaciones que MySQL escribió para Neo4j, por ID de MySQL
+	ultimos    map[int]nodoReconciliable
+	pendientes map[string]bool
+	// sinDatos completa el detalle de una fila sin nodo que no se puede recrear
+	sinDatos string
+}
+
+// entidad devuelve el estado de los tachos para compararlo
+func (estado estadoTachos) entidad() estadoEntidad {
+	e := estadoEntidad{
+		entidad:    EntidadTacho,
+		ultimos:    make(map[int]nodoReconciliable, len(estado.ultimos)),
+		pendientes: estado.pendientes,
+		sinDatos:   "MySQL no registró su ubicación para recrearlo",
+	}
+	for _, fila := range estado.filas {
+		e.filas = append(e.filas, filaReconciliable{ID: fila.ID, CustomID: fila.IdNeo})
+	}
+	for _, nodo := range estado.nodos {
+		e.nodos = append(e.nodos, nodoReconciliable{NodeID: nodo.NodeID, CustomID: nodo.ID, Lat: nodo.Latitude, Lng: nodo.Longitude})
+	}
+	for id, ultimo := range estado.ultimos {
+		e.ultimos[id] = nodoReconciliable{CustomID: ultimo.ID, Lat: ultimo.Latitude, Lng: ultimo.Longitude}
+	}
+	return e
+}
+
+// entidad devuelve el estado de los centros para compararlo
+func (estado estadoCentros) entidad() estadoEntidad {
+	e := estadoEntidad{
+		entidad:    EntidadCentro,
+		ultimos:    make(map[int]nodoReconciliable, len(estado.ultimos)),
+		pendientes: estado.pendientes,
+		sinDatos:   "MySQL no registró sus datos para recrearlo",
+	}
+	for _, fila := range estado.filas {
+		e.filas = append(e.filas, filaReconciliable{ID: fila.IDCentro, CustomID: fila.IDNeo})
+	}
+	for _, nodo := range estado.nodos {
+		e.nodos = append(e.nodos, nodoReconciliable{NodeID: nodo.NodeID, CustomID: nodo.ID, Lat: nodo.Latitud, Lng: nodo.Longitud})
+	}
+	for id, ultimo := range estado.ultimos {
+		e.ultimos[id] = nodoReconciliable{CustomID: ultimo.ID, Lat: ultimo.Latitud, Lng: ultimo.Longitud}
+	}
+	return e
+}
+
+// compararEntidad busca las inconsistencias entre las filas y los nodos de una entidad
+func compararEntidad(estado estadoEntidad) []Inconsistencia {
+	filasPorID := make(map[string][]int)
+	for _, fila := range estado.filas {
+		filasPorID[fila.CustomID] = append(filasPorID[fila.CustomID], fila.ID)
+	}
+	nodosPorID := make(map[string][]nodoReconciliable)
+	for _, nodo := range estado.nodos {
+		nodosPorID[nodo.CustomID] = append(nodosPorID[nodo.CustomID], nodo)
+	}
+
+	var inconsistencias []Inconsistencia
+	for customID, ids := range filasPorID {
+		if len(ids) > 1 {
+			inconsistencias = append(inconsistencias, Inconsistencia{
+				Entidad: estado.entidad, Tipo: InconsistenciaDuplicado, CustomID: customID, Base: FuenteMySQL,
+				IDs:        ids,
+				Detalle:    fmt.Sprintf("%d filas en MySQL con el mismo id_neo", len(ids)),
+				Corregible: true,
+			})
+		}
+		if estado.pendientes[customID] {
+			continue
+		}
+
+		ultimo, conDatos := ultimoRegistrado(estado.ultimos, ids, customID)
+		nodos, ok := nodosPorID[customID]
+		if !ok {
+			detalle := "la fila de MySQL no tiene nodo en Neo4j"
+			if !conDatos {
+				detalle += "; " + estado.sinDatos
+			}
+			inconsistencias = append(inconsistencias, Inconsistencia{
+				Entidad: estado.entidad, Tipo: InconsistenciaSinNodo, CustomID: customID,
+				IDs:        ids,
+				Detalle:    detalle,
+				Corregible: true,
+			})
+			continue
+		}
+
+		nodo := nodos[0]
+		switch {
+		case nodo.Lat == 0 && nodo.Lng == 0:
+			inconsistencias = append(inconsistencias, Inconsistencia{
+				Entidad: estado.entidad, Tipo: InconsistenciaCoordenadas, CustomID: customID,
+				IDs: ids, NodeIDs: []string{nodo.NodeID},
+				Detalle:    "el nodo de Neo4j no tiene ubicación",
+				Corregible: conDatos,
+			})
+		case conDatos && haversine(nodo.Lat, nodo.Lng, ultimo.Lat, ultimo.Lng) > toleranciaCoordenadasKm:
+			inconsistencias = append(inconsistencias, Inconsistencia{
+				Entidad: estado.entidad, Tipo: InconsistenciaCoordenadas, CustomID: customID,
+				IDs: ids, NodeIDs: []string{nodo.NodeID},
+				Detalle: fmt.Sprintf("Neo4j (%.6f, %.6f) y MySQL (%.6f, %.6f) no coinciden",
+					nodo.Lat, nodo.Lng, ultimo.Lat, ultimo.Lng),
+				Corregible: true,
+			})
+		}
+	}
+
+	for customID, nodos := range nodosPorID {
+		nodeIDs := make([]string, 0, len(nodos))
+		for _, nodo := range nodos {
+			nodeIDs = append(nodeIDs, nodo.NodeID)
+		}
+		if len(nodos) > 1 {
+			inconsistencias = append(inconsistencias, Inconsistencia{
+				Entidad: estado.entidad, Tipo: InconsistenciaDuplicado, CustomID: customID, Base: FuenteNeo4j,
+				NodeIDs:    nodeIDs,
+				Detalle:    fmt.Sprintf("%d nodos en Neo4j con el mismo id", len(nodos)),
+				Corregible: true,
+			})
+		}
+		if _, ok := filasPorID[customID]; ok || estado.pendientes[customID] {
+			continue
+		}
+		inconsistencias = append(inconsistencias, Inconsistencia{
+			Entidad: estado.entidad, Tipo: InconsistenciaSinFila, CustomID: customID,
+			NodeIDs:    nodeIDs,
+			Detalle:    "el nodo de Neo4j no tiene fila en MySQL",
+			Corregible: true,
+		})
+	}
+
+	ordenarInconsistencias(inconsistencias)
+	return inconsistencias
+}
+
+// ultimoRegistrado devuelve la última ubicación registrada en el outbox para alguna de las filas
+func ultimoRegistrado(ultimos map[int]nodoReconciliable, ids []int, customID string) (nodoReconciliable, bool) {
+	for _, id := range ids {
+		if ultimo, ok := ultimos[id]; ok && ultimo.CustomID == customID {
+			return ultimo, true
+		}
+	}
+	return nodoReconciliable{}, false
+}
+
+// ultimoCentro devuelve los últimos datos registrados en el outbox para alguna de las filas
+func ultimoCentro(ultimos map[int]CentroNeo4j, ids []int, customID string) (CentroNeo4j, bool) {
+	for _, id := range ids {
+		if centro, ok := ultimos[id]; ok && centro.ID == customID {
+			return centro, true
+		}
+	}
+	return CentroNeo4j{}, false
+}
+
+// ultimoTacho devuelve los últimos datos registrados en el outbox para alguna de las filas
+func ultimoTacho(ultimos map[int]TachoNeo4j, ids []int, customID string) (TachoNeo4j, bool) {
+	for _, id := range ids {
+		if tacho, ok := ultimos[id]; ok && tacho.ID == customID {
+			return tacho, true
+		}
+	}
+	return TachoNeo4j{}, false
+}
+
+func idsTachos(filas []TachoMySQL) []int {
+	ids := make([]int, 0, len(filas))
+	for _, fila := range filas {
+		ids = append(ids, fila.ID)
+	}
+	return ids
+}
+
+func nodeIDsTachos(nodos []TachoNeo4j) []string {
+	ids := make([]string, 0, len(nodos))
+	for _, nodo := range nodos {
+		ids = append(ids, nodo.NodeID)
+	}
+	return ids
+}
+
+// ordenarInconsistencias deja el reporte en un orden estable
+func ordenarInconsistencias(inconsistencias []Inconsistencia) {
+	sort.Slice(inconsistencias, func(i, j int) bool {
+		a, b := inconsistencias[i], inconsistencias[j]
+		if a.CustomID != b.CustomID {
+			return a.CustomID < b.CustomID
+		}
+		if a.Tipo != b.Tipo {
+			return a.Tipo < b.Tipo
+		}
+		return a.Base < b.Base
+	})
+}
+
+// getEstadoTachos lee los tachos de las dos bases y el estado del outbox
+func getEstadoTachos() (*estadoTachos, error) {
+	filas, err := getTodosTachosMySQL()
+	if err != nil {
+		return nil, err
+	}
+	nodos, err := getNodosTachoNeo4j()
+	if err != nil {
+		return nil, err
+	}
+	ultimos, pendientes, err := getEstadoOutboxTachos()
+	if err != nil {
+		return nil, err
+	}
+	return &estadoTachos{filas: filas, nodos: nodos, ultimos: ultimos, pendientes: pendientes}, nil
+}
+
+// getEstadoCentros lee los centros de las dos bases y el estado del outbox
+func getEstadoCentros() (*estadoCentros, error) {
+	filas, err := getCentrosMySQL()
+	if err != nil {
+		return nil, err
+	}
+	nodos, err := getNodosCentroNeo4j()
+	if err != nil {
+		return nil, err
+	}
+	ultimos, pendientes, err := getEstadoOutboxCentros()
+	if err != nil {
+		return nil, err
+	}
+	return &estadoCentros{filas: filas, nodos: nodos, ultimos: ultimos, pendientes: pendientes}, nil
+}
+
+// Reconciliar compara tachos y centros entre MySQL y Neo4j. Con request nil
+// solo informa; si no, corrige las inconsistencias tomando request.Fuente
+// como verdadera.
+func Reconciliar(request *ReconciliarRequest) (*ReporteReconciliacion, error) {
+	if request != nil {
+		if err := ValidarReconciliar(request); err != nil {
+			return nil, err
+		}
+	}
+
+	estado, err := getEstadoTachos()
+	if err != nil {
+		return nil, err
+	}
+	centros, err := getEstadoCentros()
+	if err != nil {
+		return nil, err
+	}
+
+	reporte := &ReporteReconciliacion{
+		TachosMySQL:  len(estado.filas),
+		TachosNeo4j:  len(estado.nodos),
+		CentrosMySQL: len(centros.filas),
+		CentrosNeo4j: len(centros.nodos),
+		GeneradoEn:   time.Now(),
+	}
+	tachos, centrosEntidad := estado.entidad(), centros.entidad()
+	inconsistencias := append(compararEntidad(tachos), compararEntidad(centrosEntidad)...)
+
+	if request != nil {
+		reporte.Fuente = request.Fuente
+		for i := range inconsistencias {
+			inconsistencia := &inconsistencias[i]
+			if !inconsistencia.Corregible || !tipoPedido(request.Tipos, inconsistencia.Tipo) {
+				continue
+			}
+			entidad, correcciones := tachos, correccionesTachos(estado)
+			if inconsistencia.Entidad == EntidadCentro {
+				entidad, correcciones = centrosEntidad, correccionesCentros(centros)
+			}
+			if err := corregirEntidad(*inconsistencia, entidad, correcciones, request); err != nil {
+				inconsistencia.Error = err.Error()
+				continue
+			}
+			inconsistencia.Corregida = true
+			reporte.Corregidas++
+		}
+		if reporte.Corregidas > 0 {
+			InvalidarRutasZonas(nil, MotivoReconciliacion)
+		}
+	}
+
+	if inconsistencias == nil {
+		inconsistencias = []Inconsistencia{}
+	}
+	reporte.Inconsistencias = inconsistencias
+	reporte.Total = len(inconsistencias)
+	return reporte, nil
+}
+
+func tipoPedido(tipos []string, tipo string) bool {
+	if len(tipos) == 0 {
+		return true
+	}
+	for _, t := range tipos {
+		if t == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// verificarDuplicado impide corregir un duplicado en la base tomada como
+// verdadera: solo se toca la otra base
+func verificarDuplicado(inconsistencia Inconsistencia, fuente string) error {
+	if inconsistencia.Base == fuente {
+		return fmt.Errorf("el duplicado está en %s, la base tomada como verdadera; corríjalo con la otra fuente o a mano", fuente)
+	}
+	return nil
+}
+
+// correccionesEntidad son las operaciones propias de cada entidad con las que
+// corregirEntidad arregla una inconsistencia. Los cambios en Neo4j pasan por el outbox.
+type correccionesEntidad struct {
+	entidadOutbox string
+	// borrarFilas elimina filas de MySQL por ID
+	borrarFilas func(ids []int) error
+	// sinFilas se llama cuando se borraron todas las filas de un custom id
+	sinFilas func(customID string) error
+	// recrearNodo vuelve a escribir el nodo con los últimos datos registrados en MySQL
+	recrearNodo func(ids []int, customID string) error
+	// tomarNodo registra en MySQL los datos del nodo como vigentes
+	tomarNodo func(ids []int, customID string) error
+	// eliminarNodo borra de Neo4j un nodo sin fila
+	eliminarNodo func(customID string) error
+	// crearFila crea la fila de MySQL de un nodo que solo existe en Neo4j
+	crearFila func(customID string, request *ReconciliarRequest) error
+}
+
+// corregirEntidad corrige una inconsistencia con la fuente pedida
+func corregirEntidad(inconsistencia Inconsistencia, estado estadoEntidad, c correccionesEntidad, request *ReconciliarRequest) error {
+	customID := inconsistencia.CustomID
+	var ids []int
+	for _, fila := range estado.filas {
+		if fila.CustomID == customID {
+			ids = append(ids, fila.ID)
+		}
+	}
+
+	switch inconsistencia.Tipo {
+	case InconsistenciaDuplicado:
+		if err := verificarDuplicado(inconsistencia, request.Fuente); err != nil {
+			return err
+		}
+		// Se conserva la fila más antigua o el nodo de menor elementId
+		if inconsistencia.Base == FuenteMySQL {
+			return c.borrarFilas(ids[1:])
+		}
+		clave := customID
+		if len(ids) > 0 {
+			clave = strconv.Itoa(ids[0])
+		}
+		return encolarReconciliacion(c.entidadOutbox, clave, outboxDeduplicar, outboxDeduplicarPayload{ID: customID})
+
+	case InconsistenciaSinNodo:
+		if request.Fuente == FuenteNeo4j {
+			if err := c.borrarFilas(ids); err != nil {
+				return err
+			}
+			if c.sinFilas != nil {
+				return c.sinFilas(customID)
+			}
+			return nil
+		}
+		return c.recrearNodo(ids, customID)
+
+	case InconsistenciaSinFila:
+		if request.Fuente == FuenteMySQL {
+			return c.eliminarNodo(customID)
+		}
+		return c.crearFila(customID, request)
+
+	case InconsistenciaCoordenadas:
+		if request.Fuente == FuenteMySQL {
+			return c.recrearNodo(ids, customID)
+		}
+		for _, nodo := range estado.nodos {
+			if nodo.CustomID != customID {
+				continue
+			}
+			if nodo.Lat == 0 && nodo.Lng == 0 {
+				return fmt.Errorf("el nodo de Neo4j no tiene ubicación para tomar como verdadera")
+			}
+			return c.tomarNodo(ids, customID)
+		}
+	}
+	return fmt.Errorf("tipo de inconsistencia desconocido: %s", inconsistencia.Tipo)
+}
+
+// correccionesTachos son las correcciones de los tachos
+func correccionesTachos(estado *estadoTachos) correccionesEntidad {
+	return correccionesEntidad{
+		entidadOutbox: EntidadOutboxTacho,
+		borrarFilas: func(ids []int) error {
+			for _, id := range ids {
+				if err := borrarTachoMySQLPorID(id); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		sinFilas: desasignarTacho,
+		recrearNodo: func(ids []int, customID string) error {
+			ultimo, ok := ultimoTacho(estado.ultimos, ids, customID)
+			if !ok {
+				return fmt.Errorf("MySQL no registró la ubicación del tacho %s", customID)
+			}
+			return encolarGuardarTacho(ids[0], ultimo)
+		},
+		tomarNodo: func(ids []int, customID string) error {
+			// MySQL registra la ubicación de Neo4j como vigente y se recalcula la zona
+			nodo := nodosDeTacho(estado.nodos, customID)[0]
+			if err := encolarGuardarTacho(ids[0], nodo); err != nil {
+				return err
+			}
+			return asignarTacho(nodo.ID, nodo.Latitude, nodo.Longitude)
+		},
+		eliminarNodo: func(customID string) error {
+			return encolarReconciliacion(EntidadOutboxTacho, customID, outboxEliminar,
+				outboxEliminarTachoPayload{ID: customID})
+		},
+		crearFila: func(customID string, request *ReconciliarRequest) error {
+			return crearFilaDesdeNeo4j(nodosDeTacho(estado.nodos, customID)[0], request)
+		},
+	}
+}
+
+// correccionesCentros son las correcciones de los centros
+func correccionesCentros(estado *estadoCentros) correccionesEntidad {
+	return correccionesEntidad{
+		entidadOutbox: EntidadOutboxCentro,
+		borrarFilas:   borrarCentrosMySQL,
+		recrearNodo: func(ids []int, customID string) error {
+			ultimo, ok := ultimoCentro(estado.ultimos, ids, customID)
+			if !ok {
+				return fmt.Errorf("MySQL no registró los datos del centro %s", customID)
+			}
+			return encolarReconciliacion(EntidadOutboxCentro, strconv.Itoa(ids[0]), outboxGuardar,
+				outboxGuardarCentroPayload{IDActual: customID, Centro: ultimo})
+		},
+		tomarNodo: func(ids []int, customID string) error {
+			// MySQL registra los datos de Neo4j como vigentes
+			nodo := nodosDeCentro(estado.nodos, customID)[0]
+			return encolarReconciliacion(EntidadOutboxCentro, strconv.Itoa(ids[0]), outboxGuardar,
+				outboxGuardarCentroPayload{IDActual: nodo.ID, Centro: nodo.centro()})
+		},
+		eliminarNodo: func(customID string) error {
+			return encolarReconciliacion(EntidadOutboxCentro, customID, outboxEliminar,
+				outboxEliminarCentroPayload{ID: customID})
+		},
+		crearFila: func(customID string, request *ReconciliarRequest) error {
+			return crearCentroDesdeNeo4j(nodosDeCentro(estado.nodos, customID)[0], request)
+		},
+	}
+}
+
+// encolarGuardarTacho deja en el outbox los datos del nodo y los aplica enseguida
+func encolarGuardarTacho(idTacho int, tacho TachoNeo4j) error {
+	tacho.NodeID = ""
+	return encolarReconciliacion(EntidadOutboxTacho, strconv.Itoa(idTacho), outboxGuardar,
+		outboxGuardarTachoPayload{IDActual: tacho.ID, Tacho: tacho})
+}
+
+// encolarReconciliacion deja una corrección de Neo4j en el outbox y la aplica enseguida
+func encolarReconciliacion(entidad, clave, operacion string, payload interface{}) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	if err := encolarOutbox(config.DB, entidad, clave, operacion, payload); err != nil {
+		return err
+	}
+	despacharOutbox(entidad, clave)
+	return nil
+}
+
+// borrarCentrosMySQL elimina las filas de centros indicadas
+func borrarCentrosMySQL(ids []int) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := config.DB.Where("id_centro IN ?", ids).Delete(&models.Centro{}).Error; err != nil {
+		return fmt.Errorf("error deleting centro: %v", err)
+	}
+	return nil
+}
+
+// crearCentroDesdeNeo4j crea la fila de MySQL de un centro que solo existe en Neo4j
+func crearCentroDesdeNeo4j(nodo nodoCentro, request *ReconciliarRequest) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	if request.IDTipoCentro <= 0 {
+		return fmt.Errorf("se necesita id_tipo_centro para crear el centro en MySQL")
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		fila := models.Centro{IDTipo: int64(request.IDTipoCentro), IDNeo: nodo.ID}
+		if err := tx.Create(&fila).Error; err != nil {
+			return fmt.Errorf("error creating centro: %v", err)
+		}
+		// Registra los datos para las próximas comparaciones; aplicarlo no cambia el nodo
+		return encolarOutbox(tx, EntidadOutboxCentro, strconv.FormatInt(fila.IDCentro, 10), outboxGuardar,
+			outboxGuardarCentroPayload{IDActual: nodo.ID, Centro: nodo.centro()})
+	})
+}
+
+func nodosDeCentro(nodos []nodoCentro, customID string) []nodoCentro {
+	var resultado []nodoCentro
+	for _, nodo := range nodos {
+		if nodo.ID == customID {
+			resultado = append(resultado, nodo)
+		}
+	}
+	return resultado
+}
+
+// crearFilaDesdeNeo4j crea la fila de MySQL de un tacho que solo existe en Neo4j
+func crearFilaDesdeNeo4j(nodo TachoNeo4j, request *ReconciliarRequest) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+	if request.IDTipo <= 0 || request.IDEstado <= 0 {
+		return fmt.Errorf("se necesitan id_tipo e id_estado para crear el tacho en MySQL")
+	}
+
+	nodo.NodeID = ""
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		fila := models.Tacho{IDTipo: int64(request.IDTipo), IDEstado: int64(request.IDEstado), IDNeo: nodo.ID}
+		if err := tx.Create(&fila).Error; err != nil {
+			return fmt.Errorf("error inserting tacho: %v", err)
+		}
+		// Registra la ubicación para las próximas comparaciones; aplicarlo no cambia el nodo
+		return encolarOutbox(tx, EntidadOutboxTacho, strconv.FormatInt(fila.IDTacho, 10), outboxGuardar,
+			outboxGuardarTachoPayload{IDActual: nodo.ID, Tacho: nodo})
+	})
+	if err != nil {
+		return err
+	}
+	return asignarTacho(nodo.ID, nodo.Latitude, nodo.Longitude)
+}
+
+func nodosDeTacho(nodos []TachoNeo4j, customID string) []TachoNeo4j {
+	var resultado []TachoNeo4j
+	for _, nodo := range nodos {
+		if nodo.ID == customID {
+			resultado = append(resultado, nodo)
+		}
+	}
+	return resultado
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tiposInconsistencias(inconsistencias []Inconsistencia) []string {
+	tipos := []string{}
+	for _, i := range inconsistencias {
+		tipos = append(tipos, i.CustomID+" "+i.Tipo+" "+i.Base)
+	}
+	return tipos
+}
+
+func TestCompararTachos(t *testing.T) {
+	estado := estadoTachos{
+		filas: []TachoMySQL{
+			{ID: 1, IdNeo: "Calle 1|BOEDO"},
+			{ID: 2, IdNeo: "Calle 2|BOEDO"},
+			{ID: 3, IdNeo: "Calle 3|BOEDO"},
+			{ID: 4, IdNeo: "Calle 3|BOEDO"},
+			{ID: 5, IdNeo: "Calle 5|BOEDO"},
+			{ID: 6, IdNeo: "Calle 6|BOEDO"},
+		},
+		nodos: []TachoNeo4j{
+			{NodeID: "n1", ID: "Calle 1|BOEDO", Latitude: -34.62, Longitude: -58.41},
+			{NodeID: "n3", ID: "Calle 3|BOEDO", Latitude: -34.63, Longitude: -58.42},
+			{NodeID: "n5", ID: "Calle 5|BOEDO"},
+			{NodeID: "n7", ID: "Calle 7|BOEDO", Latitude: -34.64, Longitude: -58.43},
+			{NodeID: "n8", ID: "Calle 7|BOEDO", Latitude: -34.64, Longitude: -58.43},
+			{NodeID: "n9", ID: "Calle 9|BOEDO", Latitude: -34.65, Longitude: -58.44},
+		},
+		ultimos: map[int]TachoNeo4j{
+			1: {ID: "Calle 1|BOEDO", Latitude: -34.60, Longitude: -58.41},
+			3: {ID: "Calle 3|BOEDO", Latitude: -34.63, Longitude: -58.42},
+		},
+		// Calle 6 y Calle 9 todavía se están sincronizando
+		pendientes: map[string]bool{"Calle 6|BOEDO": true, "Calle 9|BOEDO": true},
+	}
+
+	inconsistencias := compararEntidad(estado.entidad())
+
+	assert.Equal(t, []string{
+		"Calle 1|BOEDO coordenadas ",
+		"Calle 2|BOEDO sin_nodo ",
+		"Calle 3|BOEDO duplicado mysql",
+		"Calle 5|BOEDO coordenadas ",
+		"Calle 7|BOEDO duplicado neo4j",
+		"Calle 7|BOEDO sin_fila ",
+	}, tiposInconsistencias(inconsistencias))
+
+	assert.Contains(t, inconsistencias[0].Detalle, "no coinciden")
+	assert.Contains(t, inconsistencias[1].Detalle, "no registró su ubicación")
+	assert.Equal(t, []int{3, 4}, inconsistencias[2].IDs)
+	assert.False(t, inconsistencias[3].Corregible, "sin ubicación en ninguna base no se puede corregir")
+	assert.Equal(t, []string{"n7", "n8"}, inconsistencias[4].NodeIDs)
+}
+
+func TestCompararCentros(t *testing.T) {
+	estado := estadoCentros{
+		filas: []centroFila{
+			{IDCentro: 1, IDNeo: "c1"},
+			{IDCentro: 2, IDNeo: "c2"},
+			{IDCentro: 4, IDNeo: "c4"},
+			{IDCentro: 5, IDNeo: "c4"},
+			{IDCentro: 6, IDNeo: "c6"},
+		},
+		nodos: []nodoCentro{
+			{NodeID: "n1", ID: "c1", Latitud: -34.6, Longitud: -58.4},
+			{NodeID: "n3", ID: "c3"},
+			{NodeID: "n4", ID: "c4", Latitud: -34.6, Longitud: -58.4},
+			{NodeID: "n6", ID: "c6"},
+			{NodeID: "n7", ID: "c7", Latitud: -34.6, Longitud: -58.4},
+			{NodeID: "n8", ID: "c7", Latitud: -34.6, Longitud: -58.4},
+		},
+		ultimos: map[int]CentroNeo4j{
+			1: {ID: "c1", Latitud: -34.7, Longitud: -58.4},
+		},
+		// c7 todavía se está sincronizando
+		pendientes: map[string]bool{"c7": true},
+	}
+
+	inconsistencias := compararEntidad(estado.entidad())
+
+	assert.Equal(t, []string{
+		"c1 coordenadas ",
+		"c2 sin_nodo ",
+		"c3 sin_fila ",
+		"c4 duplicado mysql",
+		"c6 coordenadas ",
+		"c7 duplicado neo4j",
+	}, tiposInconsistencias(inconsistencias))
+
+	for _, i := range inconsistencias {
+		assert.Equal(t, EntidadCentro, i.Entidad)
+	}
+	assert.Contains(t, inconsistencias[0].Detalle, "no coinciden")
+	assert.True(t, inconsistencias[0].Corregible)
+	assert.Contains(t, inconsistencias[1].Detalle, "no registró sus datos")
+	assert.True(t, inconsistencias[2].Corregible)
+	assert.Equal(t, []int{4, 5}, inconsistencias[3].IDs)
+	assert.False(t, inconsistencias[4].Corregible, "sin ubicación en ninguna base no se puede corregir")
+	assert.Equal(t, []string{"n7", "n8"}, inconsistencias[5].NodeIDs)
+}
+
+func TestVerificarDuplicadoRespetaLaFuente(t *testing.T) {
+	enMySQL := Inconsistencia{Tipo: InconsistenciaDuplicado, Base: FuenteMySQL}
+
+	assert.Error(t, verificarDuplicado(enMySQL, FuenteMySQL), "no se borra en la base tomada como verdadera")
+	assert.NoError(t, verificarDuplicado(enMySQL, FuenteNeo4j))
+}
+
+func TestCorregirEntidad(t *testing.T) {
+	estado := estadoEntidad{
+		entidad: EntidadCentro,
+		filas:   []filaReconciliable{{ID: 4, CustomID: "c4"}, {ID: 5, CustomID: "c4"}, {ID: 6, CustomID: "c6"}},
+		nodos:   []nodoReconciliable{{NodeID: "n6", CustomID: "c6"}, {NodeID: "n7", CustomID: "c7", Lat: -34.6, Lng: -58.4}},
+	}
+	var hechas []string
+	correcciones := correccionesEntidad{
+		borrarFilas: func(ids []int) error {
+			hechas = append(hechas, fmt.Sprintf("borrar %v", ids))
+			return nil
+		},
+		recrearNodo: func(ids []int, customID string) error {
+			hechas = append(hechas, fmt.Sprintf("recrear %s %v", customID, ids))
+			return nil
+		},
+		tomarNodo: func(ids []int, customID string) error {
+			hechas = append(hechas, "tomar "+customID)
+			return nil
+		},
+		eliminarNodo: func(customID string) error {
+			hechas = append(hechas, "eliminar "+customID)
+			return nil
+		},
+		crearFila: func(customID string, request *ReconciliarRequest) error {
+			hechas = append(hechas, "crear "+customID)
+			return nil
+		},
+	}
+	mysql := &ReconciliarRequest{Fuente: FuenteMySQL}
+	neo4j := &ReconciliarRequest{Fuente: FuenteNeo4j}
+
+	assert.NoError(t, corregirEntidad(Inconsistencia{Tipo: InconsistenciaDuplicado, CustomID: "c4", Base: FuenteMySQL}, estado, correcciones, neo4j))
+	assert.NoError(t, corregirEntidad(Inconsistencia{Tipo: InconsistenciaSinNodo, CustomID: "c4"}, estado, correcciones, mysql))
+	assert.NoError(t, corregirEntidad(Inconsistencia{Tipo: InconsistenciaSinFila, CustomID: "c7"}, estado, correcciones, mysql))
+	assert.NoError(t, corregirEntidad(Inconsistencia{Tipo: InconsistenciaSinFila, CustomID: "c7"}, estado, correcciones, neo4j))
+	assert.ErrorContains(t, corregirEntidad(Inconsistencia{Tipo: InconsistenciaCoordenadas, CustomID: "c6"}, estado, correcciones, neo4j),
+		"no tiene ubicación")
+
+	assert.Equal(t, []string{"borrar [5]", "recrear c4 [4 5]", "eliminar c7", "crear c7"}, hechas,
+		"se conserva la fila más antigua del duplicado")
+}
+
+func TestValidarReconciliar(t *testing.T) {
+	request := ReconciliarRequest{Fuente: " MySQL ", Tipos: []string{InconsistenciaSinFila}}
+	require.NoError(t, ValidarReconciliar(&request))
+	assert.Equal(t, FuenteMySQL, request.Fuente)
+
+	assert.ErrorContains(t, ValidarReconciliar(&ReconciliarRequest{}), "reconciliación inválida")
+	assert.ErrorContains(t, ValidarReconciliar(&ReconciliarRequest{Fuente: FuenteNeo4j, Tipos: []string{"otro"}}), "reconciliación inválida")
+}
+
+func TestEstadoOutboxTachos(t *testing.T) {
+	eventos := []models.OutboxEvento{
+		{Clave: "1", Operacion: outboxGuardar, Estado: OutboxAplicado,
+			Payload: `{"id_actual":"Calle 1|BOEDO","tacho":{"id":"Calle 1|BOEDO","latitude":-34.6}}`},
+		{Clave: "1", Operacion: outboxGuardar, Estado: OutboxPendiente,
+			Payload: `{"id_actual":"Calle 1|BOEDO","tacho":{"id":"Calle 1|BOEDO","latitude":-34.7}}`},
+		{Clave: "2", Operacion: outboxGuardar, Estado: OutboxAplicado,
+			Payload: `{"id_actual":"Calle 2|BOEDO","tacho":{"id":"Calle 2|BOEDO"}}`},
+		{Clave: "2", Operacion: outboxEliminar, Estado: OutboxAplicado, Payload: `{"id":"Calle 2|BOEDO"}`},
+	}
+
+	ultimos, pendientes := estadoOutboxTachos(eventos)
+
+	require.Len(t, ultimos, 1)
+	assert.Equal(t, -34.7, ultimos[1].Latitude, "gana el último evento")
+	assert.Equal(t, map[string]bool{"Calle 1|BOEDO": true}, pendientes)
+}
+
+func TestEstadoOutboxCentros(t *testing.T) {
+	eventos := []models.OutboxEvento{
+		{Clave: "1", Operacion: outboxGuardar, Estado: OutboxAplicado,
+			Payload: `{"id_actual":"c1","centro":{"id":"c1","latitud":-34.6}}`},
+		{Clave: "2", Operacion: outboxGuardar, Estado: OutboxAplicado, Payload: `{"id_actual":"c2","centro":{"id":"c2"}}`},
+		{Clave: "2", Operacion: outboxEliminar, Estado: OutboxAplicado, Payload: `{"id":"c2"}`},
+		{Clave: "c3", Operacion: outboxDeduplicar, Estado: OutboxPendiente, Payload: `{"id":"c3"}`},
+	}
+
+	ultimos, pendientes := estadoOutboxCentros(eventos)
+
+	require.Len(t, ultimos, 1)
+	assert.Equal(t, -34.6, ultimos[1].Latitud)
+	assert.Equal(t, map[string]bool{"c3": true}, pendientes, "la clave puede ser el id personalizado")
+}
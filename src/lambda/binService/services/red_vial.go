@@ -0,0 +1,674 @@
+package services
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Tipos de distancia informados en una ruta
+const (
+	DistanciaVial   = "vial"
+	DistanciaLineal = "lineal"
+)
+
+// loteRedVial es la cantidad de nodos o tramos por transacción al importar
+const loteRedVial = 5000
+
+// celdaGrilla es el tamaño (en grados) de las celdas del índice de intersecciones
+const celdaGrilla = 0.005
+
+// maxAccesoKm es la distancia máxima entre un punto y la intersección más
+// cercana para usar la red; más lejos el punto se considera fuera de la red
+const maxAccesoKm = 1.0
+
+// ImportacionRedVial es el resultado de importar un extracto OSM
+type ImportacionRedVial struct {
+	Intersecciones int `json:"intersecciones"`
+	Tramos         int `json:"tramos"`
+	UnaMano        int `json:"tramos_una_mano"`
+}
+
+// aristaVial es un tramo dirigido del grafo en memoria
+type aristaVial struct {
+	hasta     int
+	km        float64
+	geometria [][2]float64
+}
+
+// grafoVial es la red de calles cargada en memoria para calcular caminos mínimos
+type grafoVial struct {
+	nodos  []NodoVial
+	indice map[int64]int
+	ady    [][]aristaVial
+	grilla map[[2]int][]int
+}
+
+var (
+	redVial   *grafoVial
+	redVialMu sync.RWMutex
+)
+
+// redVialActual devuelve la red de calles cargada o nil si no hay una
+func redVialActual() *grafoVial {
+	redVialMu.RLock()
+	defer redVialMu.RUnlock()
+	return redVial
+}
+
+// TipoDistancia indica si las rutas se calculan sobre la red de calles o en línea recta
+func TipoDistancia() string {
+	if redVialActual() != nil {
+		return DistanciaVial
+	}
+	return DistanciaLineal
+}
+
+// nuevoGrafoVial arma el grafo en memoria a partir de intersecciones y tramos.
+// Los tramos de doble mano se agregan en ambos sentidos.
+func nuevoGrafoVial(nodos []NodoVial, tramos []TramoVial) *grafoVial {
+	g := &grafoVial{
+		nodos:  nodos,
+		indice: make(map[int64]int, len(nodos)),
+		ady:    make([][]aristaVial, len(nodos)),
+		grilla: make(map[[2]int][]int),
+	}
+	for i, nodo := range nodos {
+		g.indice[nodo.OsmID] = i
+		celda := celdaDe(nodo.Lat, nodo.Lng)
+		g.grilla[celda] = append(g.grilla[celda], i)
+	}
+
+	for _, tramo := range tramos {
+		desde, ok1 := g.indice[tramo.Desde]
+		hasta, ok2 := g.indice[tramo.Hasta]
+		if !ok1 || !ok2 {
+			continue
+		}
+		if len(tramo.Geometria) < 2 {
+			tramo.Geometria = [][2]float64{{nodos[desde].Lat, nodos[desde].Lng}, {nodos[hasta].Lat, nodos[hasta].Lng}}
+		}
+		g.ady[desde] = append(g.ady[desde], aristaVial{hasta: hasta, km: tramo.LongitudKm, geometria: tramo.Geometria})
+		if !tramo.UnaMano {
+			inversa := make([][2]float64, len(tramo.Geometria))
+			for i, pos := range tramo.Geometria {
+				inversa[len(inversa)-1-i] = pos
+			}
+			g.ady[hasta] = append(g.ady[hasta], aristaVial{hasta: desde, km: tramo.LongitudKm, geometria: inversa})
+		}
+	}
+	return g
+}
+
+// celdaDe devuelve la celda de la grilla que contiene la posición
+func celdaDe(lat, lng float64) [2]int {
+	return [2]int{int(math.Floor(lat / celdaGrilla)), int(math.Floor(lng / celdaGrilla))}
+}
+
+// masCercano devuelve la intersección más cercana a la posición y su distancia,
+// buscando en anillos de celdas cada vez más grandes. Devuelve -1 si no hay
+// ninguna a menos de maxAccesoKm.
+func (g *grafoVial) masCercano(lat, lng float64) (int, float64) {
+	centro := celdaDe(lat, lng)
+	mejor, mejorDist := -1, math.Inf(1)
+	// Ancho de una celda en km a esta latitud (el lado más corto)
+	anchoCelda := celdaGrilla * 111 * math.Cos(lat*math.Pi/180)
+	maxRadio := int(maxAccesoKm/anchoCelda) + 1
+	for radio := 0; radio <= maxRadio; radio++ {
+		for dLat := -radio; dLat <= radio; dLat++ {
+			for dLng := -radio; dLng <= radio; dLng++ {
+				if abs(dLat) != radio && abs(dLng) != radio {
+					continue
+				}
+				for _, i := range g.grilla[[2]int{centro[0] + dLat, centro[1] + dLng}] {
+					if d := haversine(lat, lng, g.nodos[i].Lat, g.nodos[i].Lng); d < mejorDist {
+						mejor, mejorDist = i, d
+					}
+				}
+			}
+		}
+		// Lo que quede en anillos más lejanos está a más de radio celdas
+		if mejor >= 0 && mejorDist <= float64(radio)*anchoCelda {
+			break
+		}
+	}
+	if mejorDist > maxAccesoKm {
+		return -1, 0
+	}
+	return mejor, mejorDist
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// colaDijkstra es una cola de prioridad de nodos por distancia
+type colaDijkstra []itemDijkstra
+
+type itemDijkstra struct {
+	nodo int
+	dist float64
+}
+
+func (c colaDijkstra) Len() int            { return len(c) }
+func (c colaDijkstra) Less(i, j int) bool  { return c[i].dist < c[j].dist }
+func (c colaDijkstra) Swap(i, j int)       { c[i], c[j] = c[j], c[i] }
+func (c *colaDijkstra) Push(x interface{}) { *c = append(*c, x.(itemDijkstra)) }
+func (c *colaDijkstra) Pop() interface{} {
+	old := *c
+	item := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return item
+}
+
+// dijkstra calcula las distancias mínimas desde origen. Si se indican destinos,
+// termina en cuanto los alcanza a todos. previo guarda la arista usada para
+// llegar a cada nodo (índice del nodo anterior y posición en su adyacencia).
+func (g *grafoVial) dijkstra(origen int, destinos map[int]bool) ([]float64, [][2]int) {
+	dist := make([]float64, len(g.nodos))
+	previo := make([][2]int, len(g.nodos))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		previo[i] = [2]int{-1, -1}
+	}
+	dist[origen] = 0
+
+	pendientes := len(destinos)
+	cerrado := make([]bool, len(g.nodos))
+	cola := &colaDijkstra{{nodo: origen}}
+	for cola.Len() > 0 {
+		item := heap.Pop(cola).(itemDijkstra)
+		if cerrado[item.nodo] {
+			continue
+		}
+		cerrado[item.nodo] = true
+		if destinos[item.nodo] {
+			pendientes--
+			if pendientes == 0 {
+				break
+			}
+		}
+
+		for k, arista := range g.ady[item.nodo] {
+			if d := item.dist + arista.km; d < dist[arista.hasta] {
+				dist[arista.hasta] = d
+				previo[arista.hasta] = [2]int{item.nodo, k}
+				heap.Push(cola, itemDijkstra{nodo: arista.hasta, dist: d})
+			}
+		}
+	}
+	return dist, previo
+}
+
+// matriz calcula la distancia por calles (km) entre todos los puntos
+func (g *grafoVial) matriz(points []Point) [][]float64 {
+	dist := g.distancias(points, points)
+	for i := range dist {
+		dist[i][i] = 0
+	}
+	return dist
+}
+
+// distancias calcula la distancia por calles (km) desde cada punto de desde a
+// cada punto de hacia. El acceso desde cada punto a su intersección más cercana
+// se suma en línea recta. Los pares sin camino (o puntos fuera de la red) usan
+// la distancia haversine.
+func (g *grafoVial) distancias(desde, hacia []Point) [][]float64 {
+	nodosDesde := make([]int, len(desde))
+	accesoDesde := make([]float64, len(desde))
+	for i, p := range desde {
+		nodosDesde[i], accesoDesde[i] = g.masCercano(p.Lat, p.Lng)
+	}
+	nodosHacia := make([]int, len(hacia))
+	accesoHacia := make([]float64, len(hacia))
+	destinos := make(map[int]bool)
+	for j, p := range hacia {
+		nodosHacia[j], accesoHacia[j] = g.masCercano(p.Lat, p.Lng)
+		if nodosHacia[j] >= 0 {
+			destinos[nodosHacia[j]] = true
+		}
+	}
+
+	desdeNodo := make(map[int][]float64)
+	for _, nodo := range nodosDesde {
+		if nodo >= 0 {
+			if _, ok := desdeNodo[nodo]; !ok {
+				desdeNodo[nodo], _ = g.dijkstra(nodo, destinos)
+			}
+		}
+	}
+
+	dist := make([][]float64, len(desde))
+	for i, a := range desde {
+		dist[i] = make([]float64, len(hacia))
+		for j, b := range hacia {
+			d := math.Inf(1)
+			if nodosDesde[i] >= 0 && nodosHacia[j] >= 0 {
+				d = accesoDesde[i] + desdeNodo[nodosDesde[i]][nodosHacia[j]] + accesoHacia[j]
+			}
+			if math.IsInf(d, 1) {
+				d = haversine(a.Lat, a.Lng, b.Lat, b.Lng)
+			}
+			dist[i][j] = d
+		}
+	}
+	return dist
+}
+
+// camino devuelve la distancia y la polilínea [lat, lng] del camino mínimo
+// entre dos puntos. Si no hay camino se usa la línea recta.
+func (g *grafoVial) camino(a, b Point) (float64, [][2]float64) {
+	recta := [][2]float64{{a.Lat, a.Lng}, {b.Lat, b.Lng}}
+	desde, accesoA := g.masCercano(a.Lat, a.Lng)
+	hasta, accesoB := g.masCercano(b.Lat, b.Lng)
+	if desde < 0 || hasta < 0 {
+		return haversine(a.Lat, a.Lng, b.Lat, b.Lng), recta
+	}
+
+	dist, previo := g.dijkstra(desde, map[int]bool{hasta: true})
+	if math.IsInf(dist[hasta], 1) {
+		return haversine(a.Lat, a.Lng, b.Lat, b.Lng), recta
+	}
+
+	// Reconstruir las aristas desde el destino hacia el origen
+	aristas := [][][2]float64{}
+	for nodo := hasta; nodo != desde; {
+		p := previo[nodo]
+		aristas = append(aristas, g.ady[p[0]][p[1]].geometria)
+		nodo = p[0]
+	}
+
+	polilinea := [][2]float64{{a.Lat, a.Lng}, {g.nodos[desde].Lat, g.nodos[desde].Lng}}
+	for i := len(aristas) - 1; i >= 0; i-- {
+		polilinea = append(polilinea, aristas[i][1:]...)
+	}
+	polilinea = append(polilinea, [2]float64{b.Lat, b.Lng})
+
+	return accesoA + dist[hasta] + accesoB, polilinea
+}
+
+// completarRecorrido numera las paradas, calcula la distancia de cada tramo y
+// la acumulada, y arma la polilínea: por calles si hay red vial cargada o en
+// línea recta si no
+func completarRecorrido(ruta *Ruta) {
+	g := redVialActual()
+	ruta.TipoDistancia = DistanciaLineal
+	if g != nil {
+		ruta.TipoDistancia = DistanciaVial
+	}
+
+	ruta.DistanciaTotal = 0
+	ruta.Polilinea = [][2]float64{}
+	for i := range ruta.Puntos {
+		p := &ruta.Puntos[i]
+		p.Secuencia = i + 1
+		if i == 0 {
+			p.DistanciaTramo, p.DistanciaAcumulada = 0, 0
+			ruta.Polilinea = append(ruta.Polilinea, [2]float64{p.Lat, p.Lng})
+			continue
+		}
+
+		anterior := ruta.Puntos[i-1]
+		if g == nil {
+			p.DistanciaTramo = haversine(anterior.Lat, anterior.Lng, p.Lat, p.Lng)
+			ruta.Polilinea = append(ruta.Polilinea, [2]float64{p.Lat, p.Lng})
+		} else {
+			km, tramo := g.camino(anterior, *p)
+			p.DistanciaTramo = km
+			ruta.Polilinea = append(ruta.Polilinea, tramo[1:]...)
+		}
+		ruta.DistanciaTotal += p.DistanciaTramo
+		p.DistanciaAcumulada = ruta.DistanciaTotal
+	}
+}
+
+// CargarRedVial carga en memoria la red de calles guardada en Neo4j. Si no hay
+// red importada (o RED_VIAL_HABILITADA=false) las rutas usan distancia en línea recta.
+func CargarRedVial() error {
+	if os.Getenv("RED_VIAL_HABILITADA") == "false" {
+		return nil
+	}
+
+	nodos, tramos, err := getRedVialFromNeo4j()
+	if err != nil {
+		return err
+	}
+
+	var g *grafoVial
+	if len(nodos) > 0 {
+		g = nuevoGrafoVial(nodos, tramos)
+	}
+
+	redVialMu.Lock()
+	redVial = g
+	redVialMu.Unlock()
+
+	log.Printf("Red vial cargada: %d intersecciones, %d tramos", len(nodos), len(tramos))
+	return nil
+}
+
+// ImportarRedVial reemplaza la red de calles de Neo4j con la de un extracto OSM
+// y la vuelve a cargar en memoria. Si la importación falla, la red anterior
+// sigue vigente.
+func ImportarRedVial(r io.Reader) (*ImportacionRedVial, error) {
+	nodos, tramos, err := ParseOSM(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := guardarRedVialEnNeo4j(nodos, tramos); err != nil {
+		return nil, fmt.Errorf("error guardando red vial en Neo4j: %v", err)
+	}
+
+	if err := CargarRedVial(); err != nil {
+		return nil, fmt.Errorf("red vial importada, pero falló la carga en memoria: %v", err)
+	}
+
+	resultado := &ImportacionRedVial{Intersecciones: len(nodos), Tramos: len(tramos)}
+	for _, tramo := range tramos {
+		if tramo.UnaMano {
+			resultado.UnaMano++
+		}
+	}
+	return resultado, nil
+}
+
+// guardarRedVialEnNeo4j crea intersecciones (:Interseccion) y tramos [:CALLE]
+// por lotes con una versión nueva. Solo cuando está completa pasa a ser la
+// vigente en el nodo (:RedVial); recién entonces se borran las anteriores.
+func guardarRedVialEnNeo4j(nodos []NodoVial, tramos []TramoVial) error {
+	session, err := getSession()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	defer session.Close(ctx)
+
+	if err := ejecutarEscritura(ctx, session, "CREATE INDEX interseccion_version_osm_id IF NOT EXISTS FOR (i:Interseccion) ON (i.version, i.osm_id)", nil); err != nil {
+		return err
+	}
+
+	version := time.Now().UnixNano()
+	if err := crearVersionRedVial(ctx, session, version, nodos, tramos); err != nil {
+		descartarVersionRedVial(ctx, session, version)
+		return err
+	}
+
+	activada, err := activarVersionRedVial(ctx, session, version)
+	if err != nil {
+		descartarVersionRedVial(ctx, session, version)
+		return err
+	}
+	if !activada {
+		descartarVersionRedVial(ctx, session, version)
+		return fmt.Errorf("otra importación más reciente ya reemplazó la red vial")
+	}
+
+	// La red nueva ya es la vigente: si el borrado falla, la próxima importación lo completa
+	if err := borrarIntersecciones(ctx, session, "i.version IS NULL OR i.version < $version", version); err != nil {
+		log.Printf("Error borrando la red vial anterior: %v", err)
+	}
+	return nil
+}
+
+// crearVersionRedVial guarda las intersecciones y tramos con la versión indicada
+func crearVersionRedVial(ctx context.Context, session neo4j.SessionWithContext, version int64, nodos []NodoVial, tramos []TramoVial) error {
+	for inicio := 0; inicio < len(nodos); inicio += loteRedVial {
+		fin := min(inicio+loteRedVial, len(nodos))
+		filas := make([]map[string]interface{}, 0, fin-inicio)
+		for _, nodo := range nodos[inicio:fin] {
+			filas = append(filas, map[string]interface{}{"osm_id": nodo.OsmID, "lat": nodo.Lat, "lng": nodo.Lng})
+		}
+		err := ejecutarEscritura(ctx, session, `
+			UNWIND $filas AS fila
+			CREATE (:Interseccion {
+				osm_id: fila.osm_id,
+				version: $version,
+				location: point({latitude: fila.lat, longitude: fila.lng})
+			})
+		`, map[string]interface{}{"filas": filas, "version": version})
+		if err != nil {
+			return err
+		}
+	}
+
+	for inicio := 0; inicio < len(tramos); inicio += loteRedVial {
+		fin := min(inicio+loteRedVial, len(tramos))
+		filas := make([]map[string]interface{}, 0, fin-inicio)
+		for _, tramo := range tramos[inicio:fin] {
+			lats := make([]float64, len(tramo.Geometria))
+			lngs := make([]float64, len(tramo.Geometria))
+			for i, pos := range tramo.Geometria {
+				lats[i], lngs[i] = pos[0], pos[1]
+			}
+			filas = append(filas, map[string]interface{}{
+				"desde": tramo.Desde, "hasta": tramo.Hasta, "nombre": tramo.Nombre,
+				"longitud_km": tramo.LongitudKm, "una_mano": tramo.UnaMano,
+				"lats": lats, "lngs": lngs,
+			})
+		}
+		err := ejecutarEscritura(ctx, session, `
+			UNWIND $filas AS fila
+			MATCH (a:Interseccion {version: $version, osm_id: fila.desde}),
+			      (b:Interseccion {version: $version, osm_id: fila.hasta})
+			CREATE (a)-[:CALLE {
+				nombre: fila.nombre,
+				longitud_km: fila.longitud_km,
+				una_mano: fila.una_mano,
+				lats: fila.lats,
+				lngs: fila.lngs
+			}]->(b)
+		`, map[string]interface{}{"filas": filas, "version": version})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activarVersionRedVial marca la versión como vigente en una sola transacción.
+// No la activa si ya hay una más nueva.
+func activarVersionRedVial(ctx context.Context, session neo4j.SessionWithContext, version int64) (bool, error) {
+	activada, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		result, err := tx.Run(ctx, `
+			MERGE (r:RedVial)
+			WITH r
+			WHERE r.version IS NULL OR r.version < $version
+			SET r.version = $version
+			RETURN count(r) AS activadas
+		`, map[string]interface{}{"version": version})
+		if err != nil {
+			return nil, err
+		}
+		record, err := result.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		activadas, _ := record.Get("activadas")
+		return activadas, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return activada.(int64) > 0, nil
+}
+
+// descartarVersionRedVial borra lo que se llegó a crear de una importación fallida
+func descartarVersionRedVial(ctx context.Context, session neo4j.SessionWithContext, version int64) {
+	if err := borrarIntersecciones(ctx, session, "i.version = $version", version); err != nil {
+		log.Printf("Error descartando la red vial %d: %v", version, err)
+	}
+}
+
+// borrarIntersecciones borra por lotes, para no exceder la memoria de la
+// transacción, las intersecciones que cumplen la condición
+func borrarIntersecciones(ctx context.Context, session neo4j.SessionWithContext, condicion string, version int64) error {
+	for {
+		borrados, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+			result, err := tx.Run(ctx, `
+				MATCH (i:Interseccion)
+				WHERE `+condicion+`
+				WITH i LIMIT $lote
+				DETACH DELETE i
+				RETURN count(*) AS borrados
+			`, map[string]interface{}{"lote": loteRedVial, "version": version})
+			if err != nil {
+				return nil, err
+			}
+			record, err := result.Single(ctx)
+			if err != nil {
+				return nil, err
+			}
+			borrados, _ := record.Get("borrados")
+			return borrados, nil
+		})
+		if err != nil {
+			return err
+		}
+		if borrados.(int64) == 0 {
+			return nil
+		}
+	}
+}
+
+// ejecutarEscritura corre una consulta de escritura en su propia transacción
+func ejecutarEscritura(ctx context.Context, session neo4j.SessionWithContext, query string, params map[string]interface{}) error {
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return result.Consume(ctx)
+	})
+	return err
+}
+
+// filtroVersionRedVial elige las intersecciones de la versión vigente. Sin
+// nodo (:RedVial) la red es anterior al versionado y no tiene versión.
+const filtroVersionRedVial = "(i.version = $version OR ($version IS NULL AND i.version IS NULL))"
+
+// getRedVialFromNeo4j lee intersecciones y tramos de la red vigente en Neo4j
+func getRedVialFromNeo4j() ([]NodoVial, []TramoVial, error) {
+	session, err := getSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := context.Background()
+	defer session.Close(ctx)
+
+	version, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		result, err := tx.Run(ctx, "OPTIONAL MATCH (r:RedVial) RETURN r.version AS version LIMIT 1", nil)
+		if err != nil {
+			return nil, err
+		}
+		record, err := result.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		version, _ := record.Get("version")
+		return version, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error leyendo la versión de la red vial: %v", err)
+	}
+	params := map[string]interface{}{"version": version}
+
+	nodos, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, `
+			MATCH (i:Interseccion)
+			WHERE `+filtroVersionRedVial+`
+			RETURN i.osm_id AS osm_id, i.location.latitude AS lat, i.location.longitude AS lng
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+
+		nodos := []NodoVial{}
+		for records.Next(ctx) {
+			rec := records.Record()
+			osmID, _ := rec.Get("osm_id")
+			lat, _ := rec.Get("lat")
+			lng, _ := rec.Get("lng")
+			id, ok := osmID.(int64)
+			if !ok {
+				continue
+			}
+			nodos = append(nodos, NodoVial{OsmID: id, Lat: getFloatValue(lat), Lng: getFloatValue(lng)})
+		}
+		return nodos, records.Err()
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error leyendo intersecciones: %v", err)
+	}
+
+	tramos, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		records, err := tx.Run(ctx, `
+			MATCH (i:Interseccion)-[c:CALLE]->(b:Interseccion)
+			WHERE `+filtroVersionRedVial+`
+			RETURN i.osm_id AS desde, b.osm_id AS hasta, c.longitud_km AS km,
+			       c.una_mano AS una_mano, c.lats AS lats, c.lngs AS lngs
+		`, params)
+		if err != nil {
+			return nil, err
+		}
+
+		tramos := []TramoVial{}
+		for records.Next(ctx) {
+			rec := records.Record()
+			desde, _ := rec.Get("desde")
+			hasta, _ := rec.Get("hasta")
+			km, _ := rec.Get("km")
+			unaMano, _ := rec.Get("una_mano")
+			lats, _ := rec.Get("lats")
+			lngs, _ := rec.Get("lngs")
+
+			tramo := TramoVial{LongitudKm: getFloatValue(km)}
+			tramo.Desde, _ = desde.(int64)
+			tramo.Hasta, _ = hasta.(int64)
+			tramo.UnaMano, _ = unaMano.(bool)
+
+			latList, _ := lats.([]interface{})
+			lngList, _ := lngs.([]interface{})
+			for i := 0; i < len(latList) && i < len(lngList); i++ {
+				tramo.Geometria = append(tramo.Geometria, [2]float64{getFloatValue(latList[i]), getFloatValue(lngList[i])})
+			}
+			tramos = append(tramos, tramo)
+		}
+		return tramos, records.Err()
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error leyendo tramos: %v", err)
+	}
+
+	return nodos.([]NodoVial), tramos.([]TramoVial), nil
+}
+
+// EstadoRedVial resume la red de calles cargada en memoria
+type EstadoRedVial struct {
+	TipoDistancia  string `json:"tipo_distancia"`
+	Intersecciones int    `json:"intersecciones"`
+	Tramos         int    `json:"tramos"`
+}
+
+// GetEstadoRedVial informa si las rutas usan la red de calles y su tamaño
+func GetEstadoRedVial() EstadoRedVial {
+	estado := EstadoRedVial{TipoDistancia: TipoDistancia()}
+	if g := redVialActual(); g != nil {
+		estado.Intersecciones = len(g.nodos)
+		for _, aristas := range g.ady {
+			estado.Tramos += len(aristas)
+		}
+	}
+	return estado
+}
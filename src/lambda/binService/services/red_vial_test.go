@@ -0,0 +1,133 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Cuadrícula de 2x2 cuadras: la calle de arriba (1-2-3) es de una mano hacia el este
+const extractoOSM = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <node id="1" lat="-34.600" lon="-58.400"/>
+  <node id="2" lat="-34.600" lon="-58.399"/>
+  <node id="3" lat="-34.600" lon="-58.398"/>
+  <node id="4" lat="-34.601" lon="-58.400"/>
+  <node id="5" lat="-34.601" lon="-58.398"/>
+  <node id="6" lat="-34.6005" lon="-58.398"/>
+  <way id="10"><nd ref="1"/><nd ref="2"/><nd ref="3"/>
+    <tag k="highway" v="residential"/><tag k="oneway" v="yes"/><tag k="name" v="Arriba"/></way>
+  <way id="11"><nd ref="1"/><nd ref="4"/><nd ref="5"/><nd ref="6"/><nd ref="3"/>
+    <tag k="highway" v="residential"/><tag k="name" v="Vuelta"/></way>
+  <way id="12"><nd ref="2"/><nd ref="6"/><tag k="footway" v="sidewalk"/><tag k="highway" v="footway"/></way>
+</osm>`
+
+func TestParseOSM(t *testing.T) {
+	nodos, tramos, err := ParseOSM(strings.NewReader(extractoOSM))
+	assert.NoError(t, err)
+
+	// Solo los extremos compartidos son intersecciones; la vereda se ignora
+	ids := []int64{}
+	for _, n := range nodos {
+		ids = append(ids, n.OsmID)
+	}
+	assert.Equal(t, []int64{1, 3}, ids)
+	if assert.Len(t, tramos, 2) {
+		assert.True(t, tramos[0].UnaMano)
+		assert.Len(t, tramos[0].Geometria, 3)
+		assert.False(t, tramos[1].UnaMano)
+		assert.Len(t, tramos[1].Geometria, 5)
+	}
+
+	_, _, err = ParseOSM(strings.NewReader("\x00\x00\x00\x0d\x0a\x09OSMHeader\x18"))
+	assert.ErrorIs(t, err, ErrExtractoOSM)
+	_, _, err = ParseOSM(strings.NewReader(`<osm version="0.6"></osm>`))
+	assert.ErrorIs(t, err, ErrExtractoOSM)
+}
+
+func TestParseOSMPBF(t *testing.T) {
+	// El mismo extracto que extractoOSM, en formato PBF
+	archivo, err := os.Open("testdata/cuadricula.osm.pbf")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer archivo.Close()
+
+	nodos, tramos, err := ParseOSM(archivo)
+	assert.NoError(t, err)
+	nodosXML, tramosXML, _ := ParseOSM(strings.NewReader(extractoOSM))
+
+	// PBF guarda las coordenadas en enteros: pueden diferir en el último decimal
+	if assert.Len(t, nodos, len(nodosXML)) {
+		for i := range nodos {
+			assert.Equal(t, nodosXML[i].OsmID, nodos[i].OsmID)
+			assert.InDelta(t, nodosXML[i].Lat, nodos[i].Lat, 1e-9)
+			assert.InDelta(t, nodosXML[i].Lng, nodos[i].Lng, 1e-9)
+		}
+	}
+	if assert.Len(t, tramos, len(tramosXML)) {
+		for i := range tramos {
+			assert.Equal(t, tramosXML[i].Desde, tramos[i].Desde)
+			assert.Equal(t, tramosXML[i].Hasta, tramos[i].Hasta)
+			assert.Equal(t, tramosXML[i].Nombre, tramos[i].Nombre)
+			assert.Equal(t, tramosXML[i].UnaMano, tramos[i].UnaMano)
+			assert.Len(t, tramos[i].Geometria, len(tramosXML[i].Geometria))
+			assert.InDelta(t, tramosXML[i].LongitudKm, tramos[i].LongitudKm, 1e-6)
+		}
+	}
+}
+
+func TestGrafoVial_UnaMano(t *testing.T) {
+	nodos, tramos, err := ParseOSM(strings.NewReader(extractoOSM))
+	assert.NoError(t, err)
+	g := nuevoGrafoVial(nodos, tramos)
+
+	oeste := Point{Lat: -34.600, Lng: -58.400}
+	este := Point{Lat: -34.600, Lng: -58.398}
+
+	ida, polIda := g.camino(oeste, este)
+	vuelta, polVuelta := g.camino(este, oeste)
+
+	// A favor de la mano se va derecho; en contra hay que dar la vuelta a la manzana
+	assert.InDelta(t, tramos[0].LongitudKm, ida, 1e-9)
+	assert.InDelta(t, tramos[1].LongitudKm, vuelta, 1e-9)
+	assert.Greater(t, vuelta, ida)
+	assert.Equal(t, [2]float64{-34.600, -58.399}, polIda[2])
+	assert.Contains(t, polVuelta, [2]float64{-34.601, -58.400})
+
+	dist := g.matriz([]Point{oeste, este})
+	assert.InDelta(t, ida, dist[0][1], 1e-9)
+	assert.InDelta(t, vuelta, dist[1][0], 1e-9)
+}
+
+func TestCompletarRecorrido_SinRedVial(t *testing.T) {
+	ruta := &Ruta{Puntos: []Point{{Lat: -34.6, Lng: -58.4}, {Lat: -34.61, Lng: -58.4}}}
+	completarRecorrido(ruta)
+
+	assert.Equal(t, DistanciaLineal, ruta.TipoDistancia)
+	assert.Len(t, ruta.Polilinea, 2)
+	assert.InDelta(t, haversine(-34.6, -58.4, -34.61, -58.4), ruta.DistanciaTotal, 1e-9)
+}
+
+func TestCompletarRecorrido_SecuenciaYTramos(t *testing.T) {
+	ruta := &Ruta{Puntos: []Point{
+		{Tipo: TipoPuntoOrigen, Lat: -34.60, Lng: -58.40},
+		{ID: 17, IDTacho: 17, CustomID: "Av. Corrientes 1234|ALMAGRO", Tipo: TipoPuntoTacho, Lat: -34.61, Lng: -58.40},
+		{ID: 9, IDTacho: 9, CustomID: "Medrano 500|ALMAGRO", Tipo: TipoPuntoTacho, Lat: -34.61, Lng: -58.41},
+	}}
+	completarRecorrido(ruta)
+
+	tramo1 := haversine(-34.60, -58.40, -34.61, -58.40)
+	tramo2 := haversine(-34.61, -58.40, -34.61, -58.41)
+	for i, p := range ruta.Puntos {
+		assert.Equal(t, i+1, p.Secuencia)
+	}
+	assert.Equal(t, 0.0, ruta.Puntos[0].DistanciaAcumulada)
+	assert.InDelta(t, tramo1, ruta.Puntos[1].DistanciaTramo, 1e-9)
+	assert.InDelta(t, tramo2, ruta.Puntos[2].DistanciaTramo, 1e-9)
+	assert.InDelta(t, tramo1+tramo2, ruta.Puntos[2].DistanciaAcumulada, 1e-9)
+	assert.InDelta(t, ruta.DistanciaTotal, ruta.Puntos[2].DistanciaAcumulada, 1e-9)
+	assert.Equal(t, "Medrano 500|ALMAGRO", ruta.Puntos[2].CustomID)
+}
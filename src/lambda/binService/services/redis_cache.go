@@ -4,10 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/middleware"
+)
+
+// Motivos de invalidación de rutas cacheadas (etiqueta de la métrica)
+const (
+	MotivoTachoCreado    = "tacho_creado"
+	MotivoTachoEliminado = "tacho_eliminado"
+	MotivoCapacidad      = "capacidad"
+	MotivoPrioridad      = "prioridad"
+	MotivoAsignacion     = "asignacion"
+	MotivoReconciliacion = "reconciliacion"
+	MotivoTipo           = "tipo"
+	MotivoEstado         = "estado"
+	MotivoDireccion      = "direccion"
 )
 
 // TTL por defecto en segundos (puede ser override por env REDIS_TTL_SECONDS)
@@ -21,39 +38,174 @@ func init() {
 	}
 }
 
+// routeCacheKey arma la clave de la ruta cacheada para una zona y sus opciones
+func routeCacheKey(zonaID int, opciones RutaOpciones) string {
+	return fmt.Sprintf("ruta:zona:%d:%s", zonaID, opciones.claveCache())
+}
+
 // GetCachedRoute intenta obtener la ruta cacheada para una zona
-func GetCachedRoute(zonaID int) ([]Point, error) {
+func GetCachedRoute(zonaID int, opciones RutaOpciones) (*Ruta, error) {
 	if config.RedisClient == nil {
 		return nil, fmt.Errorf("redis client not available")
 	}
 
 	ctx := context.Background()
-	key := fmt.Sprintf("ruta:zona:%d", zonaID)
-	val, err := config.RedisClient.Get(ctx, key).Result()
+	val, err := config.RedisClient.Get(ctx, routeCacheKey(zonaID, opciones)).Result()
 	if err != nil {
+		middleware.IncrementRutaCache("miss")
 		return nil, err
 	}
 
-	var points []Point
-	if err := json.Unmarshal([]byte(val), &points); err != nil {
+	var ruta Ruta
+	if err := json.Unmarshal([]byte(val), &ruta); err != nil {
+		middleware.IncrementRutaCache("miss")
 		return nil, err
 	}
 
-	return points, nil
+	middleware.IncrementRutaCache("hit")
+	return &ruta, nil
 }
 
 // SetCachedRoute guarda la ruta en Redis con TTL
-func SetCachedRoute(zonaID int, points []Point) error {
+func SetCachedRoute(zonaID int, opciones RutaOpciones, ruta *Ruta) error {
 	if config.RedisClient == nil {
 		return fmt.Errorf("redis client not available")
 	}
 
 	ctx := context.Background()
-	key := fmt.Sprintf("ruta:zona:%d", zonaID)
-	b, err := json.Marshal(points)
+	b, err := json.Marshal(ruta)
 	if err != nil {
 		return err
 	}
 
-	return config.RedisClient.Set(ctx, key, b, defaultTTL).Err()
+	return config.RedisClient.Set(ctx, routeCacheKey(zonaID, opciones), b, defaultTTL).Err()
+}
+
+// RutaZona es la ruta de una zona junto con el origen del resultado
+type RutaZona struct {
+	Ruta *Ruta
+	// DesdeCache es true si la ruta se tomó de Redis sin recalcular
+	DesdeCache bool
+}
+
+// GetRutaZona devuelve la ruta de una zona desde la caché de Redis o, si no
+// está, la calcula, registra las métricas de cálculo y la guarda en caché
+func GetRutaZona(zonaID int, opciones RutaOpciones) (*RutaZona, error) {
+	if cached, err := GetCachedRoute(zonaID, opciones); err == nil && len(cached.Puntos) > 0 {
+		return &RutaZona{Ruta: cached, DesdeCache: true}, nil
+	}
+
+	inicio := time.Now()
+	ruta, err := GetDistances(zonaID, opciones)
+	if err != nil {
+		return nil, err
+	}
+	ruta.CalculadaEn = &inicio
+
+	zona := strconv.Itoa(zonaID)
+	middleware.IncrementRutasOptimas(zona)
+	middleware.ObserveRutaCalculoTime(zona, time.Since(inicio).Seconds())
+
+	// Un error de caché no afecta la respuesta
+	if err := SetCachedRoute(zonaID, opciones, ruta); err != nil {
+		log.Printf("Warning: failed to set cached route for zona %d: %v", zonaID, err)
+	}
+
+	return &RutaZona{Ruta: ruta}, nil
+}
+
+// invalidarRutas borra las rutas cacheadas cuyas claves coinciden con el patrón
+func invalidarRutas(patron, motivo string) error {
+	if config.RedisClient == nil {
+		return fmt.Errorf("redis client not available")
+	}
+
+	ctx := context.Background()
+	borradas := 0
+	iter := config.RedisClient.Scan(ctx, 0, patron, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := config.RedisClient.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+		borradas++
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if borradas > 0 {
+		middleware.AddRutasCacheInvalidadas(motivo, borradas)
+	}
+	return nil
+}
+
+// InvalidarRutasZonas borra las rutas cacheadas de las zonas indicadas.
+// Sin zonas se borran las rutas de todas las zonas. Un error solo se registra:
+// la escritura que lo provocó ya está guardada y la ruta vence por TTL.
+func InvalidarRutasZonas(zonas []int, motivo string) {
+	patrones := []string{"ruta:zona:*"}
+	if len(zonas) > 0 {
+		patrones = patrones[:0]
+		for _, zonaID := range zonas {
+			patrones = append(patrones, fmt.Sprintf("ruta:zona:%d:*", zonaID))
+		}
+	}
+
+	for _, patron := range patrones {
+		if err := invalidarRutas(patron, motivo); err != nil {
+			log.Printf("Warning invalidando rutas cacheadas %s: %v", patron, err)
+		}
+	}
+}
+
+// zonasDeTacho devuelve las zonas cuyas rutas pueden incluir al tacho: la
+// asignada por ubicación y las que cubren su barrio (por Zona_barrio o por
+// nombre de zona si no tiene barrios cargados)
+func zonasDeTacho(customID string) ([]int, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	barrio := ""
+	if partes := strings.SplitN(customID, "|", 2); len(partes) == 2 {
+		barrio = strings.ToUpper(strings.TrimSpace(partes[1]))
+	}
+
+	var zonas []int
+	err := config.DB.Raw(`
+		SELECT id_zona FROM Tacho_zona WHERE id_neo = ?
+		UNION
+		SELECT id_zona FROM Zona_barrio WHERE UPPER(barrio) = ?
+		UNION
+		SELECT id_zona FROM Zona
+		WHERE UPPER(TRIM(nombre)) = ? AND id_zona NOT IN (SELECT id_zona FROM Zona_barrio)
+	`, customID, barrio, barrio).Scan(&zonas).Error
+	if err != nil {
+		return nil, fmt.Errorf("error querying zonas del tacho: %v", err)
+	}
+	return zonas, nil
+}
+
+// InvalidarRutasTacho borra las rutas cacheadas de las zonas que incluyen al
+// tacho. Si no se pueden obtener sus zonas se borran las de todas.
+func InvalidarRutasTacho(customID, motivo string) {
+	zonas, err := zonasDeTacho(customID)
+	if err != nil {
+		log.Printf("Warning obteniendo zonas del tacho %s: %v", customID, err)
+		InvalidarRutasZonas(nil, motivo)
+		return
+	}
+	if len(zonas) > 0 {
+		InvalidarRutasZonas(zonas, motivo)
+	}
+}
+
+// InvalidarRutasTachoPorID es InvalidarRutasTacho a partir del ID de MySQL
+func InvalidarRutasTachoPorID(tachoID int, motivo string) {
+	customID, err := getTachoIDNeo(tachoID)
+	if err != nil || customID == "" {
+		InvalidarRutasZonas(nil, motivo)
+		return
+	}
+	InvalidarRutasTacho(customID, motivo)
 }
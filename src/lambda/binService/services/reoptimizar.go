@@ -0,0 +1,491 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MotivoPlanInicial es el motivo de la primera revisión de una ruta
+const MotivoPlanInicial = "plan inicial"
+
+// ReoptimizarRequest representa un cambio sobre una ruta en ejecución: la
+// posición actual del camión y los tachos (id_tacho) que pasan a ser urgentes
+// o que se sacan del recorrido
+type ReoptimizarRequest struct {
+	Lat      *float64 `json:"lat" example:"-34.6037"`
+	Lng      *float64 `json:"lng" example:"-58.3816"`
+	Urgentes []int    `json:"urgentes"`
+	Quitar   []int    `json:"quitar"`
+	Motivo   string   `json:"motivo" example:"Emergencia: tacho desbordado"`
+}
+
+// RevisionPlan es una versión del recorrido de una ruta
+type RevisionPlan struct {
+	Revision       int          `json:"revision"`
+	Motivo         string       `json:"motivo"`
+	Posicion       *[2]float64  `json:"posicion,omitempty"`
+	Urgentes       []int        `json:"urgentes,omitempty"`
+	Quitados       []int        `json:"quitados,omitempty"`
+	DistanciaTotal float64      `json:"distancia_total_km"`
+	CreadaEn       time.Time    `json:"creada_en"`
+	Paradas        []ParadaPlan `json:"paradas"`
+	Polilinea      [][2]float64 `json:"polilinea,omitempty"`
+}
+
+// RevisionesResponse es el historial de revisiones de una ruta
+type RevisionesResponse struct {
+	IDRuta     int            `json:"id_ruta"`
+	Revisiones []RevisionPlan `json:"revisiones"`
+	Total      int            `json:"total"`
+}
+
+// ValidarReoptimizar verifica la posición y que los tachos no se repitan
+func ValidarReoptimizar(request ReoptimizarRequest) error {
+	if request.Lat == nil || request.Lng == nil {
+		return fmt.Errorf("reoptimización inválida: lat y lng de la posición actual son requeridos")
+	}
+	if *request.Lat < -90 || *request.Lat > 90 || *request.Lng < -180 || *request.Lng > 180 {
+		return fmt.Errorf("reoptimización inválida: lat y lng deben ser coordenadas válidas")
+	}
+
+	vistos := make(map[int]bool)
+	for _, id := range append(append([]int{}, request.Urgentes...), request.Quitar...) {
+		if id <= 0 {
+			return fmt.Errorf("reoptimización inválida: ID de tacho inválido: %d", id)
+		}
+		if vistos[id] {
+			return fmt.Errorf("reoptimización inválida: el tacho %d está repetido", id)
+		}
+		vistos[id] = true
+	}
+	return nil
+}
+
+// guardarRevision guarda el recorrido actual de la ruta como revisión plan.Revision
+func guardarRevision(tx *gorm.DB, plan models.RutaPlan, paradas []models.RutaParada, revision models.RutaRevision) error {
+	paradasJSON, err := json.Marshal(paradasPlan(paradas))
+	if err != nil {
+		return fmt.Errorf("error serializando paradas: %v", err)
+	}
+
+	revision.IDRuta = plan.IDRuta
+	revision.Revision = plan.Revision
+	revision.DistanciaTotal = plan.DistanciaTotal
+	revision.Paradas = string(paradasJSON)
+	revision.Polilinea = plan.Polilinea
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("error inserting revisión: %v", err)
+	}
+	return nil
+}
+
+// dividirParadas separa las paradas de una ruta en las que ya quedaron atrás
+// (tachos atendidos y puntos anteriores al primer tacho pendiente), los tachos
+// pendientes y el destino final. Las descargas pendientes se descartan: el
+// control de capacidad las vuelve a calcular sobre el nuevo recorrido.
+func dividirParadas(filas []models.RutaParada) (fijas []models.RutaParada, pendientes []Point, destino *Point) {
+	primerPendiente := -1
+	for _, fila := range filas {
+		if fila.Tipo == TipoPuntoTacho && fila.Estado == EstadoParadaPendiente {
+			if primerPendiente < 0 || fila.Secuencia < primerPendiente {
+				primerPendiente = fila.Secuencia
+			}
+		}
+	}
+
+	for _, fila := range filas {
+		switch {
+		case fila.Tipo == TipoPuntoTacho && fila.Estado == EstadoParadaPendiente:
+			pendientes = append(pendientes, puntoDesdeParada(fila))
+		case fila.Tipo == TipoPuntoTacho:
+			fijas = append(fijas, fila)
+		case fila.Tipo == TipoPuntoDestino:
+			p := puntoDesdeParada(fila)
+			destino = &p
+		case primerPendiente < 0 || fila.Secuencia < primerPendiente:
+			fijas = append(fijas, fila)
+		}
+	}
+	return fijas, pendientes, destino
+}
+
+// urgentesSinAtender devuelve los tachos urgentes que no están entre las
+// paradas fijas: un tacho ya atendido no se vuelve a agregar al recorrido
+func urgentesSinAtender(fijas []models.RutaParada, urgentes []int) map[int]bool {
+	esUrgente := make(map[int]bool, len(urgentes))
+	for _, id := range urgentes {
+		esUrgente[id] = true
+	}
+	for _, fila := range fijas {
+		if fila.Tipo == TipoPuntoTacho {
+			delete(esUrgente, fila.IDTacho)
+		}
+	}
+	return esUrgente
+}
+
+// cargaActual estima los litros que lleva el camión al final de las paradas
+// fijas: suma los tachos recolectados desde la última descarga
+func cargaActual(fijas []models.RutaParada) float64 {
+	carga := 0.0
+	for _, fila := range fijas {
+		switch {
+		case fila.Tipo == TipoPuntoDescarga:
+			carga = 0
+		case fila.Tipo == TipoPuntoTacho && fila.Estado == EstadoParadaRecolectada:
+			carga += volumenEstimado(fila.Capacidad)
+		}
+	}
+	return carga
+}
+
+// getCentrosPlan obtiene los centros de descarga compatibles con el camión de
+// la ruta. Devuelve nil si la ruta se planificó sin control de capacidad.
+func getCentrosPlan(rutaID int) ([]Point, error) {
+	plan, err := getPlanModelo(config.DB, rutaID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.CapacidadLitros <= 0 || plan.IDCamion <= 0 {
+		return nil, nil
+	}
+
+	camion, err := GetCamionByID(int(plan.IDCamion))
+	if err != nil {
+		return nil, err
+	}
+	tipos, err := getTiposCamion()
+	if err != nil {
+		return nil, err
+	}
+	centros, err := getCentrosDescarga()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo centros de descarga: %v", err)
+	}
+	return centrosCompatibles(centros, tipoCamionOrDefault(tipos, camion.Camion.IDTipo).NombreTipo), nil
+}
+
+// polilineaConRecorrido une la polilínea de lo ya recorrido (las paradas fijas
+// hasta la posición actual, que es el primer punto del tramo) con la del tramo nuevo
+func polilineaConRecorrido(fijas []models.RutaParada, tramo *Ruta) [][2]float64 {
+	if len(fijas) == 0 {
+		return tramo.Polilinea
+	}
+
+	recorrido := &Ruta{Puntos: make([]Point, 0, len(fijas)+1)}
+	for _, fila := range fijas {
+		recorrido.Puntos = append(recorrido.Puntos, puntoDesdeParada(fila))
+	}
+	recorrido.Puntos = append(recorrido.Puntos, tramo.Puntos[0])
+	completarRecorrido(recorrido)
+	if len(tramo.Polilinea) == 0 {
+		return recorrido.Polilinea
+	}
+	return append(recorrido.Polilinea, tramo.Polilinea[1:]...)
+}
+
+// reordenarRestante arma el resto del recorrido desde la posición actual:
+// primero los tachos urgentes y después el resto, terminando en el destino
+// si hay uno. No incluye la posición actual.
+func reordenarRestante(posicion Point, urgentes, resto []Point, destino *Point, algoritmo string) []Point {
+	if len(urgentes) == 0 || len(resto) == 0 {
+		ruta := OptimizarRuta(append(urgentes, resto...), algoritmo, &posicion, destino)
+		return ruta.Puntos[1:]
+	}
+
+	primero := OptimizarRuta(urgentes, algoritmo, &posicion, nil)
+	ultimo := primero.Puntos[len(primero.Puntos)-1]
+	segundo := OptimizarRuta(resto, algoritmo, &ultimo, destino)
+	return append(primero.Puntos[1:], segundo.Puntos[1:]...)
+}
+
+// getPuntosUrgentes obtiene los tachos indicados por id_tacho como paradas
+func getPuntosUrgentes(tachoIDs []int) (map[int]Point, error) {
+	puntos := make(map[int]Point)
+	if len(tachoIDs) == 0 {
+		return puntos, nil
+	}
+
+	porCustomID := make(map[string]int)
+	customIDs := make([]string, 0, len(tachoIDs))
+	for _, id := range tachoIDs {
+		customID, err := getTachoIDNeo(id)
+		if err != nil {
+			return nil, err
+		}
+		porCustomID[customID] = id
+		customIDs = append(customIDs, customID)
+	}
+
+	points, err := getTachoPointsByIDs(customIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := completarDatosMySQL(points); err != nil {
+		return nil, err
+	}
+	for _, p := range points {
+		puntos[porCustomID[p.CustomID]] = p
+	}
+	for _, id := range tachoIDs {
+		if _, ok := puntos[id]; !ok {
+			return nil, fmt.Errorf("tacho with ID %d not found", id)
+		}
+	}
+	return puntos, nil
+}
+
+// ReoptimizarPlanRuta recalcula el resto de una ruta planificada o en curso
+// desde la posición actual del camión. Los tachos urgentes se visitan primero
+// (se agregan si no estaban), los quitados y los bloqueados salen del
+// recorrido y las paradas ya atendidas se conservan. Cada reoptimización
+// queda guardada como una nueva revisión.
+func ReoptimizarPlanRuta(rutaID int, request ReoptimizarRequest) (*PlanRuta, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if err := ValidarReoptimizar(request); err != nil {
+		return nil, err
+	}
+	if request.Motivo == "" {
+		request.Motivo = "reoptimización"
+	}
+
+	urgentesNuevos, err := getPuntosUrgentes(request.Urgentes)
+	if err != nil {
+		return nil, err
+	}
+	centros, err := getCentrosPlan(rutaID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		plan, err := getPlanModelo(tx.Clauses(clause.Locking{Strength: "UPDATE"}), rutaID)
+		if err != nil {
+			return err
+		}
+		if plan.Estado != EstadoPlanificada && plan.Estado != EstadoEnCurso {
+			return fmt.Errorf("transición inválida: la ruta %d está %s", rutaID, plan.Estado)
+		}
+
+		var filas []models.RutaParada
+		if err := tx.Where("id_ruta = ?", rutaID).Order("secuencia").Find(&filas).Error; err != nil {
+			return fmt.Errorf("error querying paradas: %v", err)
+		}
+
+		// Las rutas guardadas antes del historial no tienen su revisión original
+		if plan.Revision == 0 {
+			plan.Revision = 1
+			if err := guardarRevision(tx, *plan, filas, models.RutaRevision{Motivo: MotivoPlanInicial}); err != nil {
+				return err
+			}
+		}
+
+		fijas, pendientes, destino := dividirParadas(filas)
+
+		quitar := make(map[int]bool)
+		for _, id := range request.Quitar {
+			quitar[id] = true
+		}
+		esUrgente := urgentesSinAtender(fijas, request.Urgentes)
+
+		urgentes, resto := []Point{}, []Point{}
+		for _, p := range pendientes {
+			switch {
+			case quitar[p.IDTacho]:
+				delete(quitar, p.IDTacho)
+			case esUrgente[p.IDTacho]:
+				delete(esUrgente, p.IDTacho)
+				urgentes = append(urgentes, p)
+			default:
+				resto = append(resto, p)
+			}
+		}
+		for _, id := range request.Quitar {
+			if !quitar[id] {
+				continue
+			}
+			return fmt.Errorf("reoptimización inválida: el tacho %d no es una parada pendiente de la ruta", id)
+		}
+		for _, id := range request.Urgentes {
+			if esUrgente[id] {
+				urgentes = append(urgentes, urgentesNuevos[id])
+			}
+		}
+		for i := range urgentes {
+			urgentes[i].Urgencia = 1
+		}
+
+		posicion := Point{Tipo: TipoPuntoOrigen, Nombre: "Posición actual", Lat: *request.Lat, Lng: *request.Lng}
+		tramo := &Ruta{Puntos: append([]Point{posicion}, reordenarRestante(posicion, urgentes, resto, destino, plan.Algoritmo)...)}
+		// El camión sigue con lo que ya recolectó desde su última descarga
+		if plan.CapacidadLitros > 0 && len(centros) > 0 {
+			tramo = aplicarCapacidadDesde(tramo, plan.CapacidadLitros, centros, cargaActual(fijas))
+		}
+		completarRecorrido(tramo)
+
+		recorrido := 0.0
+		nuevas := make([]models.RutaParada, 0, len(fijas)+len(tramo.Puntos))
+		for i, fila := range fijas {
+			fila.Secuencia = i + 1
+			nuevas = append(nuevas, fila)
+			if fila.DistanciaAcumulada > recorrido {
+				recorrido = fila.DistanciaAcumulada
+			}
+		}
+		for _, p := range tramo.Puntos[1:] {
+			p.DistanciaAcumulada += recorrido
+			nuevas = append(nuevas, paradaDesdePunto(plan.IDRuta, len(nuevas)+1, p))
+		}
+
+		if err := tx.Where("id_ruta = ?", rutaID).Delete(&models.RutaParada{}).Error; err != nil {
+			return fmt.Errorf("error deleting paradas: %v", err)
+		}
+		if len(nuevas) > 0 {
+			if err := tx.CreateInBatches(nuevas, 200).Error; err != nil {
+				return fmt.Errorf("error inserting paradas: %v", err)
+			}
+		}
+
+		polilinea, err := json.Marshal(polilineaConRecorrido(fijas, tramo))
+		if err != nil {
+			return fmt.Errorf("error serializando polilínea: %v", err)
+		}
+		plan.Revision++
+		plan.DistanciaTotal = recorrido + tramo.DistanciaTotal
+		plan.TipoDistancia = tramo.TipoDistancia
+		plan.Polilinea = string(polilinea)
+		plan.Descargas = 0
+		for _, parada := range nuevas {
+			if parada.Tipo == TipoPuntoDescarga {
+				plan.Descargas++
+			}
+		}
+		cambios := map[string]interface{}{
+			"revision":           plan.Revision,
+			"distancia_total_km": plan.DistanciaTotal,
+			"tipo_distancia":     plan.TipoDistancia,
+			"polilinea":          plan.Polilinea,
+			"descargas":          plan.Descargas,
+		}
+		if err := tx.Model(&models.RutaPlan{}).Where("id_ruta = ?", rutaID).Updates(cambios).Error; err != nil {
+			return fmt.Errorf("error updating ruta: %v", err)
+		}
+
+		urgentesJSON, _ := json.Marshal(request.Urgentes)
+		quitadosJSON, _ := json.Marshal(request.Quitar)
+		if err := guardarRevision(tx, *plan, nuevas, models.RutaRevision{
+			Motivo:   request.Motivo,
+			Lat:      request.Lat,
+			Lng:      request.Lng,
+			Urgentes: string(urgentesJSON),
+			Quitados: string(quitadosJSON),
+		}); err != nil {
+			return err
+		}
+
+		if plan.Estado == EstadoEnCurso {
+			return actualizarAvancePlan(tx, plan, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPlanRuta(rutaID)
+}
+
+// ReoptimizarPorEmergencia reoptimiza la ruta en curso de la zona ante una
+// emergencia. El tacho indicado (id_tacho) pasa a urgente. Sin posición del
+// camión se usa la de la última parada atendida. Devuelve nil si la zona no
+// tiene una ruta en curso.
+func ReoptimizarPorEmergencia(zonaID, tachoID int, lat, lng *float64, motivo string) (*PlanRuta, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var planes []models.RutaPlan
+	if err := config.DB.Where("id_zona = ? AND estado = ?", zonaID, EstadoEnCurso).
+		Order("iniciada_en DESC").Limit(1).Find(&planes).Error; err != nil {
+		return nil, fmt.Errorf("error querying ruta en curso: %v", err)
+	}
+	if len(planes) == 0 {
+		return nil, nil
+	}
+	rutaID := int(planes[0].IDRuta)
+
+	if lat == nil || lng == nil {
+		var filas []models.RutaParada
+		if err := config.DB.Where("id_ruta = ?", rutaID).Order("secuencia").Find(&filas).Error; err != nil {
+			return nil, fmt.Errorf("error querying paradas: %v", err)
+		}
+		if len(filas) == 0 {
+			return nil, fmt.Errorf("transición inválida: la ruta %d no tiene paradas", rutaID)
+		}
+		ultima := ultimaParadaAtendida(filas)
+		lat, lng = &ultima.Lat, &ultima.Lng
+	}
+
+	request := ReoptimizarRequest{Lat: lat, Lng: lng, Motivo: motivo}
+	if tachoID > 0 {
+		request.Urgentes = []int{tachoID}
+	}
+	return ReoptimizarPlanRuta(rutaID, request)
+}
+
+// ultimaParadaAtendida es la última parada que el camión marcó, o la primera
+// de la ruta si todavía no atendió ninguna
+func ultimaParadaAtendida(filas []models.RutaParada) models.RutaParada {
+	ultima := filas[0]
+	for _, fila := range filas {
+		if fila.AtendidaEn != nil && (ultima.AtendidaEn == nil || fila.AtendidaEn.After(*ultima.AtendidaEn)) {
+			ultima = fila
+		}
+	}
+	return ultima
+}
+
+// GetRevisionesPlan devuelve todas las revisiones de una ruta, de la más vieja a la más nueva
+func GetRevisionesPlan(rutaID int) (*RevisionesResponse, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if _, err := getPlanModelo(config.DB, rutaID); err != nil {
+		return nil, err
+	}
+
+	var filas []models.RutaRevision
+	if err := config.DB.Where("id_ruta = ?", rutaID).Order("revision").Find(&filas).Error; err != nil {
+		return nil, fmt.Errorf("error querying revisiones: %v", err)
+	}
+
+	revisiones := make([]RevisionPlan, 0, len(filas))
+	for _, fila := range filas {
+		revision := RevisionPlan{
+			Revision:       fila.Revision,
+			Motivo:         fila.Motivo,
+			DistanciaTotal: fila.DistanciaTotal,
+			CreadaEn:       fila.CreadaEn,
+			Paradas:        []ParadaPlan{},
+		}
+		if fila.Lat != nil && fila.Lng != nil {
+			revision.Posicion = &[2]float64{*fila.Lat, *fila.Lng}
+		}
+		// Los campos JSON los escribe guardarRevision; un error deja el campo vacío
+		_ = json.Unmarshal([]byte(fila.Urgentes), &revision.Urgentes)
+		_ = json.Unmarshal([]byte(fila.Quitados), &revision.Quitados)
+		_ = json.Unmarshal([]byte(fila.Paradas), &revision.Paradas)
+		_ = json.Unmarshal([]byte(fila.Polilinea), &revision.Polilinea)
+		revisiones = append(revisiones, revision)
+	}
+
+	return &RevisionesResponse{IDRuta: rutaID, Revisiones: revisiones, Total: len(revisiones)}, nil
+}
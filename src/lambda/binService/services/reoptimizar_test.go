@@ -0,0 +1,123 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDividirParadas(t *testing.T) {
+	filas := []models.RutaParada{
+		{Secuencia: 1, Tipo: TipoPuntoOrigen},
+		{Secuencia: 2, Tipo: TipoPuntoTacho, IDTacho: 10, Estado: EstadoParadaRecolectada},
+		{Secuencia: 3, Tipo: TipoPuntoDescarga},
+		{Secuencia: 4, Tipo: TipoPuntoTacho, IDTacho: 11, Estado: EstadoParadaPendiente},
+		{Secuencia: 5, Tipo: TipoPuntoTacho, IDTacho: 12, Estado: EstadoParadaBloqueada},
+		{Secuencia: 6, Tipo: TipoPuntoDescarga},
+		{Secuencia: 7, Tipo: TipoPuntoTacho, IDTacho: 13, Estado: EstadoParadaPendiente},
+		{Secuencia: 8, Tipo: TipoPuntoDestino},
+	}
+
+	fijas, pendientes, destino := dividirParadas(filas)
+
+	var secuencias []int
+	for _, fila := range fijas {
+		secuencias = append(secuencias, fila.Secuencia)
+	}
+	assert.Equal(t, []int{1, 2, 3, 5}, secuencias, "se conservan lo ya recorrido y los tachos atendidos o bloqueados")
+	if assert.Len(t, pendientes, 2) {
+		assert.Equal(t, 11, pendientes[0].IDTacho)
+		assert.Equal(t, 13, pendientes[1].IDTacho)
+	}
+	if assert.NotNil(t, destino) {
+		assert.Equal(t, TipoPuntoDestino, destino.Tipo)
+	}
+}
+
+func TestReordenarRestanteUrgentesPrimero(t *testing.T) {
+	posicion := Point{Tipo: TipoPuntoOrigen, Lat: -34.60, Lng: -58.40}
+	destino := &Point{Tipo: TipoPuntoDestino, Lat: -34.60, Lng: -58.41}
+	// El urgente es el más lejano, igual se visita primero
+	urgentes := []Point{{IDTacho: 3, Tipo: TipoPuntoTacho, Lat: -34.70, Lng: -58.50}}
+	resto := []Point{
+		{IDTacho: 1, Tipo: TipoPuntoTacho, Lat: -34.601, Lng: -58.401},
+		{IDTacho: 2, Tipo: TipoPuntoTacho, Lat: -34.602, Lng: -58.402},
+	}
+
+	for _, algoritmo := range []string{AlgoritmoSimple, AlgoritmoCompleto} {
+		puntos := reordenarRestante(posicion, urgentes, resto, destino, algoritmo)
+		if assert.Len(t, puntos, 4, algoritmo) {
+			assert.Equal(t, 3, puntos[0].IDTacho, algoritmo)
+			assert.ElementsMatch(t, []int{1, 2}, []int{puntos[1].IDTacho, puntos[2].IDTacho}, algoritmo)
+			assert.Equal(t, TipoPuntoDestino, puntos[3].Tipo, algoritmo)
+		}
+	}
+
+	assert.Empty(t, reordenarRestante(posicion, nil, nil, nil, AlgoritmoCompleto))
+}
+
+func TestValidarReoptimizar(t *testing.T) {
+	lat, lng := -34.6, -58.4
+	fuera := 120.0
+
+	assert.NoError(t, ValidarReoptimizar(ReoptimizarRequest{Lat: &lat, Lng: &lng, Urgentes: []int{1}, Quitar: []int{2}}))
+	assert.Error(t, ValidarReoptimizar(ReoptimizarRequest{Lat: &lat}), "la posición es obligatoria")
+	assert.Error(t, ValidarReoptimizar(ReoptimizarRequest{Lat: &fuera, Lng: &lng}))
+	assert.Error(t, ValidarReoptimizar(ReoptimizarRequest{Lat: &lat, Lng: &lng, Urgentes: []int{1}, Quitar: []int{1}}))
+	assert.Error(t, ValidarReoptimizar(ReoptimizarRequest{Lat: &lat, Lng: &lng, Urgentes: []int{0}}))
+}
+
+func TestCargaActualDesdeLaUltimaDescarga(t *testing.T) {
+	fijas := []models.RutaParada{
+		{Tipo: TipoPuntoOrigen},
+		{Tipo: TipoPuntoTacho, Capacidad: 100, Estado: EstadoParadaRecolectada},
+		{Tipo: TipoPuntoDescarga},
+		{Tipo: TipoPuntoTacho, Capacidad: 50, Estado: EstadoParadaRecolectada},
+		{Tipo: TipoPuntoTacho, Capacidad: 100, Estado: EstadoParadaOmitida},
+		{Tipo: TipoPuntoTacho, Capacidad: 20, Estado: EstadoParadaRecolectada},
+	}
+
+	assert.InDelta(t, volumenTachoLitros*0.7, cargaActual(fijas), 1e-9, "solo cuenta lo recolectado después de descargar")
+	assert.Zero(t, cargaActual(fijas[:3]))
+}
+
+func TestPolilineaConRecorrido(t *testing.T) {
+	fijas := []models.RutaParada{
+		{Secuencia: 1, Tipo: TipoPuntoOrigen, Lat: -34.60, Lng: -58.40},
+		{Secuencia: 2, Tipo: TipoPuntoTacho, Estado: EstadoParadaRecolectada, Lat: -34.61, Lng: -58.41},
+	}
+	tramo := &Ruta{
+		Puntos:    []Point{{Tipo: TipoPuntoOrigen, Lat: -34.62, Lng: -58.42}, {Lat: -34.63, Lng: -58.43}},
+		Polilinea: [][2]float64{{-34.62, -58.42}, {-34.63, -58.43}},
+	}
+
+	assert.Equal(t, [][2]float64{{-34.60, -58.40}, {-34.61, -58.41}, {-34.62, -58.42}, {-34.63, -58.43}},
+		polilineaConRecorrido(fijas, tramo), "lo recorrido hasta la posición actual y después el tramo nuevo")
+	assert.Equal(t, tramo.Polilinea, polilineaConRecorrido(nil, tramo))
+}
+
+func TestUrgentesSinAtender(t *testing.T) {
+	fijas := []models.RutaParada{
+		{Secuencia: 1, Tipo: TipoPuntoOrigen},
+		{Secuencia: 2, Tipo: TipoPuntoTacho, IDTacho: 10, Estado: EstadoParadaRecolectada},
+		{Secuencia: 3, Tipo: TipoPuntoTacho, IDTacho: 12, Estado: EstadoParadaBloqueada},
+	}
+
+	assert.Equal(t, map[int]bool{11: true, 13: true}, urgentesSinAtender(fijas, []int{10, 11, 12, 13}))
+}
+
+func TestUltimaParadaAtendida(t *testing.T) {
+	antes := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	despues := antes.Add(10 * time.Minute)
+	filas := []models.RutaParada{
+		{Secuencia: 1, Tipo: TipoPuntoOrigen},
+		{Secuencia: 2, Tipo: TipoPuntoTacho, Estado: EstadoParadaRecolectada, AtendidaEn: &antes},
+		{Secuencia: 3, Tipo: TipoPuntoTacho, Estado: EstadoParadaOmitida, AtendidaEn: &despues},
+		{Secuencia: 4, Tipo: TipoPuntoTacho, Estado: EstadoParadaPendiente},
+	}
+
+	assert.Equal(t, 3, ultimaParadaAtendida(filas).Secuencia)
+	assert.Equal(t, 1, ultimaParadaAtendida(filas[:1]).Secuencia, "sin paradas atendidas sale del origen")
+}
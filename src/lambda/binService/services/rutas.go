@@ -2,20 +2,109 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
-	"os"
-	"sort"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"gorm.io/gorm"
 )
 
+// Point es una parada de una ruta. Para los tachos ID es el id_tacho de MySQL y
+// CustomID el id de Neo4j (direccion|barrio); para los centros ID es el id_centro.
 type Point struct {
-	ID  int     `json:"id"`
-	Lat float64 `json:"lat"`
-	Lng float64 `json:"lng"`
+	ID        int     `json:"id"`
+	Tipo      string  `json:"tipo,omitempty"`
+	Nombre    string  `json:"nombre,omitempty"`
+	IDTacho   int     `json:"id_tacho,omitempty"`
+	CustomID  string  `json:"id_neo,omitempty"`
+	Direccion string  `json:"direccion,omitempty"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	// Orden de visita y distancias por calles o en línea recta (ver Ruta.TipoDistancia)
+	Secuencia          int     `json:"secuencia,omitempty"`
+	DistanciaTramo     float64 `json:"distancia_tramo_km"`
+	DistanciaAcumulada float64 `json:"distancia_acumulada_km"`
+	// Llenado del tacho (%), prioridad y puntaje de urgencia combinado
+	Capacidad float64 `json:"capacidad,omitempty"`
+	Prioridad int     `json:"prioridad,omitempty"`
+	Urgencia  float64 `json:"urgencia,omitempty"`
+	// Estimación de carga del camión en litros
+	Volumen float64 `json:"volumen_litros,omitempty"`
+	Carga   float64 `json:"carga_litros,omitempty"`
+}
+
+// Tipos de punto dentro de una ruta
+const (
+	TipoPuntoTacho   = "tacho"
+	TipoPuntoOrigen  = "origen"
+	TipoPuntoDestino = "destino"
+)
+
+// RutaOpciones agrupa los parámetros con los que se calcula una ruta
+type RutaOpciones struct {
+	Algoritmo string
+	// Origen es el punto de partida (depósito o centro); nil arranca en el primer tacho
+	Origen *Point
+	// Destino es el centro de descarga final; nil deja el final libre
+	Destino *Point
+	// IDCamion activa el control de capacidad con el tipo de ese camión; 0 lo desactiva
+	IDCamion int
+	// MinCapacidad omite los tachos con menor llenado (%), salvo los urgentes
+	MinCapacidad float64
+	// Priorizar visita primero los tachos urgentes; PesoPrioridad (0-1) indica
+	// cuánto pesa la prioridad frente al llenado al calcular la urgencia
+	Priorizar     bool
+	PesoPrioridad float64
+	// matriz son las distancias precalculadas de la zona; no forma parte de la clave de caché
+	matriz *MatrizZona
+	// horario activa el control de ventanas y turno de los planes guardados;
+	// esas rutas no se cachean
+	horario *horarioRuta
+}
+
+// claveCache identifica las opciones dentro de la clave de Redis
+func (o RutaOpciones) claveCache() string {
+	clave := o.Algoritmo
+	if o.Origen != nil {
+		clave += fmt.Sprintf(":o=%.6f,%.6f", o.Origen.Lat, o.Origen.Lng)
+	}
+	if o.Destino != nil {
+		clave += fmt.Sprintf(":d=%.6f,%.6f", o.Destino.Lat, o.Destino.Lng)
+	}
+	if o.IDCamion > 0 {
+		clave += fmt.Sprintf(":c=%d", o.IDCamion)
+	}
+	if o.MinCapacidad > 0 {
+		clave += fmt.Sprintf(":min=%g", o.MinCapacidad)
+	}
+	if o.Priorizar {
+		clave += fmt.Sprintf(":p=%g", o.PesoPrioridad)
+	}
+	// Las rutas por calles y en línea recta no se mezclan en la caché
+	clave += ":" + TipoDistancia()
+	return clave
+}
+
+// Ruta representa una ruta ordenada con su distancia total en km
+type Ruta struct {
+	Algoritmo      string  `json:"algoritmo"`
+	Puntos         []Point `json:"puntos"`
+	DistanciaTotal float64 `json:"distancia_total_km"`
+	// Datos del control de capacidad (solo si la ruta se calculó para un camión)
+	CapacidadLitros float64 `json:"capacidad_camion_litros,omitempty"`
+	Descargas       int     `json:"descargas,omitempty"`
+	// Resultado de la selección de paradas
+	Urgentes int `json:"tachos_urgentes,omitempty"`
+	Omitidos int `json:"tachos_omitidos,omitempty"`
+	// TipoDistancia indica si la distancia es por calles (vial) o en línea recta;
+	// Polilinea es el recorrido como posiciones [lat, lng]
+	TipoDistancia string       `json:"tipo_distancia,omitempty"`
+	Polilinea     [][2]float64 `json:"polilinea,omitempty"`
+	// CalculadaEn es el momento del cálculo; en una ruta cacheada es anterior a la consulta
+	CalculadaEn *time.Time `json:"calculada_en,omitempty"`
 }
 
 // CreateTachoRequest representa la estructura de datos para crear un tacho
@@ -40,6 +129,45 @@ type CreateTachoResponse struct {
 	NeoNodeID string `json:"neo_node_id"`
 }
 
+// Resultado de eliminar un tacho en cada base
+const (
+	EliminacionEliminado    = "eliminado"
+	EliminacionNoEncontrado = "no_encontrado"
+	// EliminacionSeEliminaria es el resultado de una vista previa (dry run)
+	EliminacionSeEliminaria = "se_eliminaria"
+	// EliminacionPendiente es una baja en Neo4j que quedó en el outbox sin aplicar
+	EliminacionPendiente = "pendiente"
+	// EliminacionConservado es un nodo que sigue usando otra fila de MySQL
+	EliminacionConservado = "conservado"
+	EliminacionError      = "error"
+)
+
+// DeleteTachoRequest identifica el tacho a eliminar por id_tacho o por custom id exacto
+type DeleteTachoRequest struct {
+	IDTacho  int
+	CustomID string
+	// DryRun solo informa qué se borraría
+	DryRun bool
+}
+
+// ResultadoEliminacion es lo que pasó (o pasaría) en una de las bases
+type ResultadoEliminacion struct {
+	Estado  string   `json:"estado"`
+	IDs     []int    `json:"ids_tacho,omitempty"`
+	NodeIDs []string `json:"node_ids,omitempty"`
+	Detalle string   `json:"detalle,omitempty"`
+}
+
+// DeleteTachoResponse informa el resultado de la eliminación en cada base
+type DeleteTachoResponse struct {
+	CustomID string               `json:"custom_id"`
+	DryRun   bool                 `json:"dry_run"`
+	MySQL    ResultadoEliminacion `json:"mysql"`
+	Neo4j    ResultadoEliminacion `json:"neo4j"`
+	// Pendiente indica que la baja en Neo4j todavía no se aplicó
+	Pendiente bool `json:"sincronizacion_pendiente"`
+}
+
 // Haversine calculates the distance between two coordinates
 func haversine(lat1, lon1, lat2, lon2 float64) float64 {
 	const R = 6371 // Radius of the Earth en km
@@ -55,90 +183,107 @@ func haversine(lat1, lon1, lat2, lon2 float64) float64 {
 	return R * c
 }
 
-// GetDistances gets the 'tachos' in an 'zona' and sorts them by distance
-func GetDistances(zonaID int) ([]Point, error) {
-	driver, err := config.ConnectNeo()
+// GetDistances obtiene los tachos de una zona y los ordena con el algoritmo indicado
+func GetDistances(zonaID int, opciones RutaOpciones) (*Ruta, error) {
+	algoritmo, err := ValidarAlgoritmo(opciones.Algoritmo)
 	if err != nil {
-		return nil, fmt.Errorf("no se pudo conectar a Neo4j: %v", err)
-	}
-	defer driver.Close(context.Background())
-
-	session := driver.NewSession(context.Background(), neo4j.SessionConfig{
-		DatabaseName: os.Getenv("NEO4J_DATABASE"),
-	})
-	defer session.Close(context.Background())
-
-	// Mapping zonaID -> barrio
-	zonaToBarrio := map[int]string{
-		1: "CHACARITA",
-		2: "MONTE CASTRO",
-		3: "BOEDO",
-		4: "VILLA CRESPO",
+		return nil, err
 	}
+	opciones.Algoritmo = algoritmo
 
-	barrio, ok := zonaToBarrio[zonaID]
-	if !ok {
-		return nil, fmt.Errorf("zonaID desconocido")
+	points, err := getTachoPointsByZona(zonaID)
+	if err != nil {
+		return nil, err
 	}
+	opciones.matriz = matrizParaRuta(zonaID, points)
 
-	// Query to fetch Neo4j nodes by barrio
-	query := `
-	MATCH (t:Tacho)
-	WHERE t.barrio = $barrio
-	RETURN t.id AS id, t.location.latitude AS lat, t.location.longitude AS lng
-	`
-
-	result, err := session.ExecuteRead(context.Background(), func(tx neo4j.ManagedTransaction) (any, error) {
-		records, err := tx.Run(context.Background(), query, map[string]any{
-			"barrio": barrio,
-		})
-		if err != nil {
+	points, omitidos := SeleccionarParadas(points, opciones.MinCapacidad)
+	ruta := ordenarParadas(points, opciones)
+	ruta.Omitidos = omitidos
+	if opciones.horario != nil {
+		if err := ajustarAHorario(ruta, *opciones.horario, opciones.matriz); err != nil {
 			return nil, err
 		}
+	}
+	if opciones.IDCamion <= 0 {
+		completarRecorrido(ruta)
+		return ruta, nil
+	}
 
-		points := []Point{}
-		idCounter := 1
+	// Control de capacidad con el tipo del camión indicado
+	camion, err := GetCamionByID(opciones.IDCamion)
+	if err != nil {
+		return nil, err
+	}
 
-		for records.Next(context.Background()) {
-			rec := records.Record()
-			latVal, _ := rec.Get("lat")
-			lngVal, _ := rec.Get("lng")
+	tipos, err := getTiposCamion()
+	if err != nil {
+		return nil, err
+	}
 
-			lat, ok1 := latVal.(float64)
-			lng, ok2 := lngVal.(float64)
-			if !ok1 || !ok2 {
-				continue
-			}
+	centros, err := getCentrosDescarga()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo centros de descarga: %v", err)
+	}
 
-			points = append(points, Point{
-				ID:  idCounter,
-				Lat: lat,
-				Lng: lng,
-			})
-			idCounter++
-		}
+	tipo := tipoCamionOrDefault(tipos, camion.Camion.IDTipo)
+	ruta = AplicarCapacidad(ruta, tipo.CapacidadLitros, centrosCompatibles(centros, tipo.NombreTipo))
+	completarRecorrido(ruta)
+	return ruta, nil
+}
 
-		return points, records.Err()
-	})
+// getTachoPointsByZona obtiene las ubicaciones de los tachos de una zona
+func getTachoPointsByZona(zonaID int) ([]Point, error) {
+	barrios, err := GetBarriosByZona(zonaID)
 	if err != nil {
 		return nil, err
 	}
 
-	points := result.([]Point)
+	// Si la zona tiene límite se usan los tachos asignados por ubicación
+	asignados, conLimite, err := getTachosAsignados(zonaID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Order by distance from the first using sort.Slice
-	if len(points) > 1 {
-		ref := points[0]
-		sort.Slice(points[1:], func(i, j int) bool {
-			distI := haversine(ref.Lat, ref.Lng, points[i+1].Lat, points[i+1].Lng)
-			distJ := haversine(ref.Lat, ref.Lng, points[j+1].Lat, points[j+1].Lng)
-			return distI < distJ
-		})
+	var points []Point
+	if conLimite {
+		points, err = getTachoPointsByIDs(asignados)
+	} else {
+		points, err = getTachoPointsByBarrios(barrios)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Agregar id_tacho y llenado de cada tacho desde MySQL
+	if err := completarDatosMySQL(points); err != nil {
+		return nil, err
 	}
 
 	return points, nil
 }
 
+// GetCentroPoint obtiene la ubicación de un centro para usarlo como origen o destino de una ruta
+func GetCentroPoint(centroID int, tipo string) (*Point, error) {
+	response, err := GetCentroByID(centroID)
+	if err != nil {
+		return nil, err
+	}
+
+	centro := response.Centro
+	if centro.Latitud == 0 && centro.Longitud == 0 {
+		return nil, fmt.Errorf("centro with ID %d has no location", centroID)
+	}
+
+	return &Point{
+		ID:     centro.IDCentro,
+		Tipo:   tipo,
+		Nombre: centro.Nombre,
+		Lat:    centro.Latitud,
+		Lng:    centro.Longitud,
+	}, nil
+}
+
 // GetUserByEmail obtiene el número de persona asociado a un email desde Redis
 func GetUserByEmail(email string) (string, error) {
 	if config.RedisClient == nil {
@@ -179,57 +324,188 @@ func GetPersonaByKey(personaKey string) (map[string]interface{}, error) {
 	return persona, nil
 }
 
-// CreateTacho crea un tacho en MySQL y Neo4j
+// CreateTacho crea un tacho en MySQL y encola su alta en Neo4j en la misma
+// transacción (ver outbox). El nodo se crea enseguida si Neo4j responde; si
+// no, lo crea el worker del outbox con reintentos.
 func CreateTacho(request CreateTachoRequest) (*CreateTachoResponse, error) {
-	// Generar el ID personalizado (direccion|barrio)
-	customID := fmt.Sprintf("%s|%s", request.Direccion, request.Barrio)
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
 
-	// Primero crear en Neo4j para obtener el ID del nodo
-	neoNodeID, err := createTachoInNeo4j(request)
-	if err != nil {
-		return nil, fmt.Errorf("error creando tacho en Neo4j: %v", err)
+	// Generar el ID personalizado (direccion|barrio)
+	tacho := TachoNeo4j{
+		Direccion: request.Direccion,
+		Barrio:    request.Barrio,
+		Latitude:  request.Latitude,
+		Longitude: request.Longitude,
+		Prioridad: request.Prioridad,
 	}
+	normalizarUbicacion(&tacho)
+	customID := tacho.ID
 
-	// Luego crear en MySQL usando el ID personalizado en lugar del ID interno de Neo4j
-	tachoID, err := createTachoInMySQL(request, customID)
+	// El nodo se guarda con MERGE por custom id: otro tacho en la misma
+	// dirección pisaría su nodo
+	if err := verificarCustomIDEnNeo4j(customID); err != nil {
+		return nil, err
+	}
+	var tachoID int
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := verificarCustomIDLibre(tx, customID); err != nil {
+			return err
+		}
+		var err error
+		tachoID, err = createTachoInMySQL(tx, request, customID)
+		if err != nil {
+			return err
+		}
+		return encolarOutbox(tx, EntidadOutboxTacho, strconv.Itoa(tachoID), outboxGuardar, outboxGuardarTachoPayload{
+			IDActual: customID,
+			Tacho:    tacho,
+		})
+	})
 	if err != nil {
-		// Si falla MySQL, intentar limpiar Neo4j (rollback)
-		// TODO: Implementar rollback en Neo4j si es necesario
+		if errors.Is(err, ErrCustomIDDuplicado) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("error creando tacho en MySQL: %v", err)
 	}
+	despacharOutbox(EntidadOutboxTacho, strconv.Itoa(tachoID))
 
-	return &CreateTachoResponse{
-		Message:   "Tacho creado exitosamente",
-		TachoID:   tachoID,
-		NeoNodeID: neoNodeID,
-	}, nil
+	// Asignar la zona según la ubicación (no bloquea la creación)
+	if err := asignarTacho(customID, request.Latitude, request.Longitude); err != nil {
+		fmt.Printf("Warning asignando zona al tacho %s: %v\n", customID, err)
+	}
+	InvalidarRutasTacho(customID, MotivoTachoCreado)
+	agregarTachoAMatrices(Point{Tipo: TipoPuntoTacho, CustomID: customID, Lat: request.Latitude, Lng: request.Longitude})
+
+	response := &CreateTachoResponse{
+		Message: "Tacho creado exitosamente",
+		TachoID: tachoID,
+	}
+	if nodos, err := buscarTachosNeo4j(customID); err == nil && len(nodos) > 0 {
+		response.NeoNodeID = nodos[0].NodeID
+	} else {
+		response.Message = "Tacho creado; la sincronización con Neo4j quedó pendiente"
+	}
+	return response, nil
 }
 
-// DeleteTacho elimina un tacho de MySQL y Neo4j usando el ID personalizado
-func DeleteTacho(customID string) error {
-	var errorsFound []string
+// DeleteTacho elimina un tacho por id_tacho o por custom id exacto. En MySQL
+// se borra en una transacción que encola la baja en Neo4j (ver outbox); si el
+// tacho solo existe en Neo4j se borra el nodo directamente. Al borrar por
+// id_tacho, el nodo se conserva si otra fila usa el mismo custom id. Con
+// DryRun solo informa qué se borraría.
+func DeleteTacho(request DeleteTachoRequest) (*DeleteTachoResponse, error) {
+	if request.IDTacho <= 0 && request.CustomID == "" {
+		return nil, fmt.Errorf("eliminación inválida: debe indicar id_tacho o custom_id")
+	}
 
-	// Intentar eliminar de MySQL
-	err := deleteTachoFromMySQL(0, customID)
+	filas, err := getTachosParaBorrar(request.IDTacho, request.CustomID)
 	if err != nil {
-		errorsFound = append(errorsFound, fmt.Sprintf("MySQL: %v", err))
+		return nil, err
+	}
+	customID := request.CustomID
+	otras := 0
+	if request.IDTacho > 0 {
+		if len(filas) == 0 {
+			return nil, fmt.Errorf("tacho with ID %d not found", request.IDTacho)
+		}
+		customID = filas[0].IdNeo
+		if otras, err = contarOtrosTachosConIDNeo(customID, request.IDTacho); err != nil {
+			return nil, err
+		}
 	}
 
-	// Intentar eliminar de Neo4j (siempre, sin importar si falló MySQL)
-	err = deleteTachoFromNeo4j("", customID)
-	if err != nil {
-		errorsFound = append(errorsFound, fmt.Sprintf("Neo4j: %v", err))
+	nodos, errNeo := buscarTachosNeo4j(customID)
+	if len(filas) == 0 {
+		if errNeo != nil {
+			return nil, errNeo
+		}
+		if len(nodos) == 0 {
+			return nil, fmt.Errorf("tacho %s not found", customID)
+		}
 	}
 
-	// Si ambos fallaron, retornar error
-	if len(errorsFound) == 2 {
-		return fmt.Errorf("no se pudo eliminar de ninguna base de datos: %s", strings.Join(errorsFound, "; "))
+	response := &DeleteTachoResponse{
+		CustomID: customID,
+		DryRun:   request.DryRun,
+		MySQL:    ResultadoEliminacion{Estado: EliminacionSeEliminaria, IDs: idsTachos(filas)},
+		Neo4j:    ResultadoEliminacion{Estado: EliminacionSeEliminaria, NodeIDs: nodeIDsTachos(nodos)},
+	}
+	if len(filas) == 0 {
+		response.MySQL.Estado = EliminacionNoEncontrado
+	}
+	switch {
+	case errNeo != nil:
+		response.Neo4j = ResultadoEliminacion{Estado: EliminacionError, Detalle: errNeo.Error()}
+	case len(nodos) == 0:
+		response.Neo4j.Estado = EliminacionNoEncontrado
+	case otras > 0:
+		response.Neo4j.Estado = EliminacionConservado
+		response.Neo4j.Detalle = fmt.Sprintf("otras %d filas de MySQL usan el mismo custom id", otras)
+	}
+	if request.DryRun {
+		return response, nil
+	}
+
+	// Las zonas se buscan antes de borrar, mientras el tacho sigue asignado
+	zonas, errZonas := zonasDeTacho(customID)
+	borrarNodo := otras == 0 && (errNeo != nil || len(nodos) > 0)
+
+	if len(filas) > 0 {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := deleteTachoFromMySQL(tx, request.IDTacho, customID); err != nil {
+				return err
+			}
+			if !borrarNodo {
+				return nil
+			}
+			// Todas las filas comparten el custom id: una sola baja borra sus nodos
+			return encolarOutbox(tx, EntidadOutboxTacho, strconv.Itoa(filas[0].ID), outboxEliminar,
+				outboxEliminarTachoPayload{ID: customID})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error eliminando tacho de MySQL: %v", err)
+		}
+		response.MySQL.Estado = EliminacionEliminado
+
+		if borrarNodo {
+			despacharOutbox(EntidadOutboxTacho, strconv.Itoa(filas[0].ID))
+			response.Neo4j = resultadoBajaNeo4j(customID, nodos)
+		}
+	} else {
+		if err := deleteTachoFromNeo4j("", customID); err != nil {
+			return nil, fmt.Errorf("error eliminando tacho de Neo4j: %v", err)
+		}
+		response.Neo4j.Estado = EliminacionEliminado
 	}
+	response.Pendiente = response.Neo4j.Estado == EliminacionPendiente
 
-	// Si al menos uno funcionó, es éxito (aunque logueamos warnings)
-	if len(errorsFound) == 1 {
-		fmt.Printf("Warning durante eliminación: %s\n", errorsFound[0])
+	if otras == 0 {
+		if err := desasignarTacho(customID); err != nil {
+			fmt.Printf("Warning quitando la zona del tacho %s: %v\n", customID, err)
+		}
+		if err := borrarVentanasTacho(customID); err != nil {
+			fmt.Printf("Warning quitando las ventanas del tacho %s: %v\n", customID, err)
+		}
+		quitarTachoDeMatrices(zonas, customID)
+	}
+	if errZonas != nil || len(zonas) > 0 {
+		InvalidarRutasZonas(zonas, MotivoTachoEliminado)
 	}
 
-	return nil
+	return response, nil
+}
+
+// resultadoBajaNeo4j revisa si el despacho del outbox ya borró el nodo
+func resultadoBajaNeo4j(customID string, nodos []TachoNeo4j) ResultadoEliminacion {
+	restantes, err := buscarTachosNeo4j(customID)
+	if err == nil && len(restantes) == 0 {
+		return ResultadoEliminacion{Estado: EliminacionEliminado, NodeIDs: nodeIDsTachos(nodos)}
+	}
+	return ResultadoEliminacion{
+		Estado:  EliminacionPendiente,
+		NodeIDs: nodeIDsTachos(nodos),
+		Detalle: "la baja quedó en el outbox y se reintentará (ver /admin/outbox)",
+	}
 }
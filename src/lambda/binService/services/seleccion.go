@@ -0,0 +1,93 @@
+package services
+
+import "fmt"
+
+// maxPrioridad es el valor más alto de prioridad de un tacho (escala 1-5)
+const maxPrioridad = 5
+
+// PrioridadUrgente es la prioridad a partir de la cual un tacho nunca se omite
+// por tener poco llenado
+const PrioridadUrgente = 4
+
+// UmbralUrgencia es el puntaje a partir del cual un tacho se visita antes que el resto
+const UmbralUrgencia = 0.8
+
+// ValidarSeleccion verifica los parámetros de selección de paradas
+func ValidarSeleccion(minCapacidad, pesoPrioridad float64) error {
+	if minCapacidad < 0 || minCapacidad > 100 {
+		return fmt.Errorf("min_capacidad debe estar entre 0 y 100")
+	}
+	if pesoPrioridad < 0 || pesoPrioridad > 1 {
+		return fmt.Errorf("peso_prioridad debe estar entre 0 y 1")
+	}
+	return nil
+}
+
+// urgencia combina llenado y prioridad en un puntaje entre 0 y 1. peso indica
+// cuánto pesa la prioridad frente al llenado.
+func urgencia(p Point, peso float64) float64 {
+	llenado := p.Capacidad / 100
+	if llenado > 1 {
+		llenado = 1
+	}
+	prioridad := float64(p.Prioridad) / maxPrioridad
+	if prioridad > 1 {
+		prioridad = 1
+	}
+	return (1-peso)*llenado + peso*prioridad
+}
+
+// SeleccionarParadas descarta los tachos con llenado menor a minCapacidad,
+// salvo los de prioridad urgente. Devuelve los tachos a visitar y cuántos se omitieron.
+func SeleccionarParadas(points []Point, minCapacidad float64) ([]Point, int) {
+	if minCapacidad <= 0 {
+		return points, 0
+	}
+
+	seleccionados := make([]Point, 0, len(points))
+	for _, p := range points {
+		if p.Capacidad >= minCapacidad || p.Prioridad >= PrioridadUrgente {
+			seleccionados = append(seleccionados, p)
+		}
+	}
+	return seleccionados, len(points) - len(seleccionados)
+}
+
+// ordenarParadas arma la ruta de los tachos seleccionados. Si se pide priorizar,
+// primero recorre los tachos urgentes y luego el resto, optimizando cada tramo.
+func ordenarParadas(points []Point, opciones RutaOpciones) *Ruta {
+	if !opciones.Priorizar {
+		return optimizarConMatriz(points, opciones.Algoritmo, opciones.Origen, opciones.Destino, opciones.matriz)
+	}
+
+	urgentes := []Point{}
+	resto := []Point{}
+	for _, p := range points {
+		p.Urgencia = urgencia(p, opciones.PesoPrioridad)
+		if p.Urgencia >= UmbralUrgencia {
+			urgentes = append(urgentes, p)
+		} else {
+			resto = append(resto, p)
+		}
+	}
+
+	if len(urgentes) == 0 || len(resto) == 0 {
+		ruta := optimizarConMatriz(append(urgentes, resto...), opciones.Algoritmo, opciones.Origen, opciones.Destino, opciones.matriz)
+		ruta.Urgentes = len(urgentes)
+		return ruta
+	}
+
+	// Primer tramo: urgentes con final libre
+	primero := optimizarConMatriz(urgentes, opciones.Algoritmo, opciones.Origen, nil, opciones.matriz)
+
+	// Segundo tramo: arranca donde terminó el primero
+	ultimo := primero.Puntos[len(primero.Puntos)-1]
+	segundo := optimizarConMatriz(resto, opciones.Algoritmo, &ultimo, opciones.Destino, opciones.matriz)
+
+	return &Ruta{
+		Algoritmo:      opciones.Algoritmo,
+		Puntos:         append(primero.Puntos, segundo.Puntos[1:]...),
+		DistanciaTotal: primero.DistanciaTotal + segundo.DistanciaTotal,
+		Urgentes:       len(urgentes),
+	}
+}
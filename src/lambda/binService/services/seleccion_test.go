@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeleccionarParadas(t *testing.T) {
+	points := []Point{
+		{ID: 1, Capacidad: 95, Prioridad: 1},
+		{ID: 2, Capacidad: 5, Prioridad: 1},
+		{ID: 3, Capacidad: 5, Prioridad: PrioridadUrgente},
+		{ID: 4, Capacidad: 40, Prioridad: 2},
+	}
+
+	seleccionados, omitidos := SeleccionarParadas(points, 30)
+
+	ids := []int{}
+	for _, p := range seleccionados {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []int{1, 3, 4}, ids, "el tacho urgente no se omite aunque esté casi vacío")
+	assert.Equal(t, 1, omitidos)
+
+	todos, omitidos := SeleccionarParadas(points, 0)
+	assert.Len(t, todos, len(points))
+	assert.Equal(t, 0, omitidos)
+}
+
+func TestOrdenarParadas_UrgentesPrimero(t *testing.T) {
+	// El tacho lleno está en la otra punta de la calle
+	points := []Point{
+		{ID: 1, Tipo: TipoPuntoTacho, Capacidad: 20, Lat: -34.6, Lng: -58.400},
+		{ID: 2, Tipo: TipoPuntoTacho, Capacidad: 20, Lat: -34.6, Lng: -58.401},
+		{ID: 3, Tipo: TipoPuntoTacho, Capacidad: 20, Lat: -34.6, Lng: -58.402},
+		{ID: 4, Tipo: TipoPuntoTacho, Capacidad: 95, Lat: -34.6, Lng: -58.403},
+	}
+	origen := &Point{Tipo: TipoPuntoOrigen, Lat: -34.6, Lng: -58.399}
+
+	sinPrioridad := ordenarParadas(points, RutaOpciones{Algoritmo: AlgoritmoCompleto, Origen: origen})
+	assert.Equal(t, 4, sinPrioridad.Puntos[len(sinPrioridad.Puntos)-1].ID)
+
+	conPrioridad := ordenarParadas(points, RutaOpciones{Algoritmo: AlgoritmoCompleto, Origen: origen, Priorizar: true})
+	assert.Equal(t, TipoPuntoOrigen, conPrioridad.Puntos[0].Tipo)
+	assert.Equal(t, 4, conPrioridad.Puntos[1].ID)
+	assert.Equal(t, 1, conPrioridad.Urgentes)
+	assert.Len(t, conPrioridad.Puntos, len(points)+1)
+	assert.Greater(t, conPrioridad.DistanciaTotal, sinPrioridad.DistanciaTotal)
+}
+
+func TestUrgencia_PesoPrioridad(t *testing.T) {
+	p := Point{Capacidad: 20, Prioridad: maxPrioridad}
+
+	assert.InDelta(t, 0.2, urgencia(p, 0), 1e-9)
+	assert.InDelta(t, 1.0, urgencia(p, 1), 1e-9)
+	assert.Error(t, ValidarSeleccion(120, 0))
+	assert.Error(t, ValidarSeleccion(10, 1.5))
+	assert.NoError(t, ValidarSeleccion(10, 0.5))
+}
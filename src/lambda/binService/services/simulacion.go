@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+)
+
+// Escenario son los datos de una simulación de rutas. Se cargan de archivos
+// (ver cmd/simular) y no se consulta ninguna base.
+type Escenario struct {
+	Tachos   []Point
+	Centros  []Centro
+	Camiones []config.CamionOperativo
+	// Tipos da la capacidad de cada tipo de camión; los que falten usan la capacidad por defecto
+	Tipos map[int]TipoCamion
+}
+
+// BalanceCamion resume la carga de trabajo de un camión en la simulación
+type BalanceCamion struct {
+	IDCamion  int     `json:"id_camion"`
+	Tachos    int     `json:"cantidad_tachos"`
+	Descargas int     `json:"descargas"`
+	Distancia float64 `json:"distancia_km"`
+	// Desvio es la diferencia (%) contra la distancia promedio de los camiones
+	Desvio float64 `json:"desvio_pct"`
+}
+
+// ResultadoSimulacion es el reparto simulado con los totales para comparar estrategias
+type ResultadoSimulacion struct {
+	Flota    *RutasFlota     `json:"flota"`
+	Paradas  int             `json:"paradas"`
+	Promedio float64         `json:"distancia_promedio_km"`
+	Balance  []BalanceCamion `json:"balance"`
+}
+
+// SimularFlota reparte los tachos del escenario entre sus camiones con las
+// mismas reglas que GetRutasFlota (selección, balanceo y capacidad).
+// Los camiones con estado distinto de operativo (1) no se usan; 0 es sin informar.
+func SimularFlota(escenario Escenario, opciones RutaOpciones, maxCamiones int) (*ResultadoSimulacion, error) {
+	algoritmo, err := ValidarAlgoritmo(opciones.Algoritmo)
+	if err != nil {
+		return nil, err
+	}
+	opciones.Algoritmo = algoritmo
+	if err := ValidarSeleccion(opciones.MinCapacidad, opciones.PesoPrioridad); err != nil {
+		return nil, err
+	}
+
+	camiones := []config.CamionOperativo{}
+	for _, camion := range escenario.Camiones {
+		if camion.Estado == 0 || camion.Estado == 1 {
+			camiones = append(camiones, camion)
+		}
+	}
+	if len(camiones) == 0 {
+		return nil, fmt.Errorf("no hay camiones operativos")
+	}
+	if maxCamiones > 0 && maxCamiones < len(camiones) {
+		camiones = camiones[:maxCamiones]
+	}
+
+	tachos := make([]Point, len(escenario.Tachos))
+	for i, p := range escenario.Tachos {
+		p.Tipo = TipoPuntoTacho
+		tachos[i] = p
+	}
+
+	flota := armarFlota(tachos, camiones, escenario.Tipos, escenario.Centros, opciones)
+	resultado := &ResultadoSimulacion{Flota: flota, Balance: make([]BalanceCamion, 0, len(flota.Rutas))}
+	if len(flota.Rutas) > 0 {
+		resultado.Promedio = flota.DistanciaTotal / float64(len(flota.Rutas))
+	}
+	for _, ruta := range flota.Rutas {
+		resultado.Paradas += ruta.Tachos
+		balance := BalanceCamion{
+			IDCamion:  ruta.IDCamion,
+			Tachos:    ruta.Tachos,
+			Descargas: ruta.Descargas,
+			Distancia: ruta.DistanciaTotal,
+		}
+		if resultado.Promedio > 0 {
+			balance.Desvio = math.Round((ruta.DistanciaTotal/resultado.Promedio-1)*1000) / 10
+		}
+		resultado.Balance = append(resultado.Balance, balance)
+	}
+
+	return resultado, nil
+}
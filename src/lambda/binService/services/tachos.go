@@ -0,0 +1,466 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TachoDetalle es un tacho con los datos de MySQL y de Neo4j combinados
+type TachoDetalle struct {
+	IDTacho   int     `json:"id_tacho"`
+	CustomID  string  `json:"custom_id"`
+	IDTipo    int     `json:"id_tipo"`
+	IDEstado  int     `json:"id_estado"`
+	Estado    string  `json:"estado,omitempty"`
+	Capacidad float64 `json:"capacidad"`
+	// Datos de Neo4j (vacíos si el tacho no tiene nodo)
+	NodeID    string  `json:"node_id,omitempty"`
+	Barrio    string  `json:"barrio"`
+	Direccion string  `json:"direccion"`
+	Latitud   float64 `json:"latitud"`
+	Longitud  float64 `json:"longitud"`
+	Prioridad int     `json:"prioridad"`
+	EnNeo4j   bool    `json:"en_neo4j"`
+	// SincronizacionPendiente indica cambios en el outbox que Neo4j todavía no tiene
+	SincronizacionPendiente bool `json:"sincronizacion_pendiente"`
+}
+
+// UpdateTachoRequest son los campos a cambiar; los que no se envían quedan igual
+type UpdateTachoRequest struct {
+	IDTipo    *int     `json:"id_tipo,omitempty"`
+	IDEstado  *int     `json:"id_estado,omitempty"`
+	Capacidad *float64 `json:"capacidad,omitempty"`
+	Prioridad *int     `json:"prioridad,omitempty"`
+	// Direccion cambia también el custom id (direccion|barrio)
+	Direccion *string `json:"direccion,omitempty"`
+}
+
+// ReubicarTachoRequest es la nueva ubicación del tacho. Si se indican
+// dirección o barrio, el custom id cambia con ellos.
+type ReubicarTachoRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Direccion string  `json:"direccion,omitempty"`
+	Barrio    string  `json:"barrio,omitempty"`
+}
+
+// ErrCustomIDDuplicado indica que otro tacho ya usa el custom id (direccion|barrio)
+var ErrCustomIDDuplicado = errors.New("ya existe un tacho con ese custom id")
+
+// validarParteCustomID revisa una dirección o barrio que forma parte del custom id
+func validarParteCustomID(campo, valor string) error {
+	if strings.TrimSpace(valor) == "" {
+		return fmt.Errorf("actualización inválida: %s vacío", campo)
+	}
+	if strings.Contains(valor, "|") {
+		return fmt.Errorf("actualización inválida: %s no puede contener '|'", campo)
+	}
+	return nil
+}
+
+// normalizarUbicacion deja la dirección sin espacios en los bordes y el barrio
+// en mayúsculas, y arma con ellos el custom id (direccion|barrio). El alta y
+// los cambios de ubicación lo usan para que el mismo lugar dé el mismo id.
+func normalizarUbicacion(nodo *TachoNeo4j) {
+	nodo.Direccion = strings.TrimSpace(nodo.Direccion)
+	nodo.Barrio = strings.ToUpper(strings.TrimSpace(nodo.Barrio))
+	nodo.ID = nodo.Direccion + "|" + nodo.Barrio
+}
+
+// ValidarUpdateTacho revisa los campos de un PATCH
+func ValidarUpdateTacho(request UpdateTachoRequest) error {
+	if request.IDTipo == nil && request.IDEstado == nil && request.Capacidad == nil &&
+		request.Prioridad == nil && request.Direccion == nil {
+		return fmt.Errorf("actualización inválida: no se indicó ningún campo")
+	}
+	if request.IDTipo != nil && *request.IDTipo <= 0 {
+		return fmt.Errorf("actualización inválida: id_tipo debe ser mayor a 0")
+	}
+	if request.IDEstado != nil && *request.IDEstado <= 0 {
+		return fmt.Errorf("actualización inválida: id_estado debe ser mayor a 0")
+	}
+	if request.Capacidad != nil && (*request.Capacidad < 0 || *request.Capacidad > 100) {
+		return fmt.Errorf("actualización inválida: capacidad fuera de rango (0-100)")
+	}
+	if request.Prioridad != nil && (*request.Prioridad < 1 || *request.Prioridad > maxPrioridad) {
+		return fmt.Errorf("actualización inválida: prioridad fuera de rango (1-%d)", maxPrioridad)
+	}
+	if request.Direccion != nil {
+		return validarParteCustomID("direccion", *request.Direccion)
+	}
+	return nil
+}
+
+// ValidarReubicarTacho revisa la nueva ubicación y, si vienen, dirección y barrio
+func ValidarReubicarTacho(request ReubicarTachoRequest) error {
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		return fmt.Errorf("actualización inválida: coordenadas fuera de rango")
+	}
+	if request.Latitude == 0 && request.Longitude == 0 {
+		return fmt.Errorf("actualización inválida: falta la ubicación")
+	}
+	if request.Direccion != "" {
+		if err := validarParteCustomID("direccion", request.Direccion); err != nil {
+			return err
+		}
+	}
+	if request.Barrio != "" {
+		return validarParteCustomID("barrio", request.Barrio)
+	}
+	return nil
+}
+
+// getFilaTacho obtiene la fila de MySQL de un tacho
+func getFilaTacho(tachoID int) (*models.Tacho, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var fila models.Tacho
+	result := config.DB.Where("id_tacho = ?", tachoID).Limit(1).Find(&fila)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying tacho: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("tacho with ID %d not found", tachoID)
+	}
+	return &fila, nil
+}
+
+// getPendienteOutbox devuelve los datos del último cambio del tacho que
+// todavía no se aplicó en Neo4j
+func getPendienteOutbox(tachoID int) (*TachoNeo4j, error) {
+	var eventos []models.OutboxEvento
+	if err := config.DB.Where("entidad = ? AND clave = ? AND operacion = ? AND estado = ?",
+		EntidadOutboxTacho, strconv.Itoa(tachoID), outboxGuardar, OutboxPendiente).
+		Order("id_evento DESC").Limit(1).Find(&eventos).Error; err != nil {
+		return nil, fmt.Errorf("error querying outbox: %v", err)
+	}
+	if len(eventos) == 0 {
+		return nil, nil
+	}
+
+	var payload outboxGuardarTachoPayload
+	if err := json.Unmarshal([]byte(eventos[0].Payload), &payload); err != nil {
+		return nil, fmt.Errorf("error decoding outbox payload: %v", err)
+	}
+	return &payload.Tacho, nil
+}
+
+// estadoNodoTacho devuelve los datos vigentes del nodo: los del último cambio
+// pendiente en el outbox o, si no hay, los de Neo4j. Así dos cambios seguidos
+// no se pisan aunque el primero no se haya aplicado todavía.
+func estadoNodoTacho(fila *models.Tacho) (TachoNeo4j, error) {
+	pendiente, err := getPendienteOutbox(int(fila.IDTacho))
+	if err != nil {
+		return TachoNeo4j{}, err
+	}
+	if pendiente != nil {
+		return *pendiente, nil
+	}
+
+	nodos, err := buscarTachosNeo4j(fila.IDNeo)
+	if err != nil {
+		return TachoNeo4j{}, err
+	}
+	if len(nodos) == 0 {
+		return TachoNeo4j{}, fmt.Errorf("transición inválida: el tacho %d no tiene nodo en Neo4j (ver /admin/reconciliacion)", fila.IDTacho)
+	}
+	nodo := nodos[0]
+	nodo.NodeID = ""
+	return nodo, nil
+}
+
+// GetTacho obtiene un tacho por su ID combinando MySQL y Neo4j
+func GetTacho(tachoID int) (*TachoDetalle, error) {
+	fila, err := getFilaTacho(tachoID)
+	if err != nil {
+		return nil, err
+	}
+
+	detalle := &TachoDetalle{
+		IDTacho:   int(fila.IDTacho),
+		CustomID:  fila.IDNeo,
+		IDTipo:    int(fila.IDTipo),
+		IDEstado:  int(fila.IDEstado),
+		Estado:    getNombreEstadoTacho(int(fila.IDEstado)),
+		Capacidad: fila.Capacidad,
+	}
+	// Sin nodo se muestran dirección y barrio a partir del custom id
+	if partes := strings.SplitN(fila.IDNeo, "|", 2); len(partes) == 2 {
+		detalle.Direccion, detalle.Barrio = partes[0], partes[1]
+	}
+
+	nodos, err := buscarTachosNeo4j(fila.IDNeo)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodos) > 0 {
+		nodo := nodos[0]
+		detalle.NodeID = nodo.NodeID
+		detalle.Barrio = nodo.Barrio
+		detalle.Direccion = nodo.Direccion
+		detalle.Latitud = nodo.Latitude
+		detalle.Longitud = nodo.Longitude
+		detalle.Prioridad = nodo.Prioridad
+		detalle.EnNeo4j = true
+	}
+
+	pendiente, err := getPendienteOutbox(tachoID)
+	if err != nil {
+		return nil, err
+	}
+	detalle.SincronizacionPendiente = pendiente != nil
+	return detalle, nil
+}
+
+// getNombreEstadoTacho busca el nombre del estado; vacío si no se encuentra
+func getNombreEstadoTacho(idEstado int) string {
+	var nombre string
+	if err := config.DB.Raw("SELECT tipo_estado FROM Estado_tacho WHERE id_estado = ?", idEstado).
+		Scan(&nombre).Error; err != nil {
+		return ""
+	}
+	return nombre
+}
+
+// tablasConCustomID son las tablas que guardan el custom id de un tacho y se
+// renombran con él: la zona asignada, las ventanas horarias y las paradas de
+// los planes (así la ejecución y el historial siguen apuntando al tacho)
+var tablasConCustomID = []string{"Tacho_zona", "Tacho_ventana", "Ruta_parada"}
+
+// escrituraTacho guarda los cambios de un tacho; las funciones se reemplazan en los tests
+type escrituraTacho struct {
+	transaccion     func(fn func(tx *gorm.DB) error) error
+	customIDEnNeo4j func(customID string) error
+	customIDLibre   func(tx *gorm.DB, customID string) error
+	actualizar      func(tx *gorm.DB, tabla, columna string, valor interface{}, campos map[string]interface{}) error
+	encolar         func(tx *gorm.DB, clave string, payload outboxGuardarTachoPayload) error
+	despachar       func(clave string)
+}
+
+func nuevaEscrituraTacho() escrituraTacho {
+	return escrituraTacho{
+		transaccion:     func(fn func(tx *gorm.DB) error) error { return config.DB.Transaction(fn) },
+		customIDEnNeo4j: verificarCustomIDEnNeo4j,
+		customIDLibre:   verificarCustomIDLibre,
+		actualizar: func(tx *gorm.DB, tabla, columna string, valor interface{}, campos map[string]interface{}) error {
+			if err := tx.Table(tabla).Where(columna+" = ?", valor).Updates(campos).Error; err != nil {
+				return fmt.Errorf("error updating %s: %v", tabla, err)
+			}
+			return nil
+		},
+		encolar: func(tx *gorm.DB, clave string, payload outboxGuardarTachoPayload) error {
+			return encolarOutbox(tx, EntidadOutboxTacho, clave, outboxGuardar, payload)
+		},
+		despachar: func(clave string) { despacharOutbox(EntidadOutboxTacho, clave) },
+	}
+}
+
+// guardar actualiza la fila y, si se indica nodo, encola su nuevo estado en la
+// misma transacción. Si cambia el custom id se verifica que esté libre (en
+// Neo4j antes de abrir la transacción) y se renombra en las tablas que lo
+// guardan, todo dentro de la transacción.
+func (e escrituraTacho) guardar(fila *models.Tacho, campos map[string]interface{}, nodo *TachoNeo4j) error {
+	clave := strconv.FormatInt(fila.IDTacho, 10)
+	renombrar := nodo != nil && nodo.ID != fila.IDNeo
+	if renombrar {
+		if err := e.customIDEnNeo4j(nodo.ID); err != nil {
+			return err
+		}
+	}
+	err := e.transaccion(func(tx *gorm.DB) error {
+		if renombrar {
+			if err := e.customIDLibre(tx, nodo.ID); err != nil {
+				return err
+			}
+			campos["id_neo"] = nodo.ID
+		}
+		if len(campos) > 0 {
+			if err := e.actualizar(tx, "Tacho", "id_tacho", fila.IDTacho, campos); err != nil {
+				return err
+			}
+		}
+		if nodo == nil {
+			return nil
+		}
+		if renombrar {
+			for _, tabla := range tablasConCustomID {
+				if err := e.actualizar(tx, tabla, "id_neo", fila.IDNeo, map[string]interface{}{"id_neo": nodo.ID}); err != nil {
+					return err
+				}
+			}
+		}
+		datos := *nodo
+		datos.NodeID = ""
+		return e.encolar(tx, clave, outboxGuardarTachoPayload{IDActual: fila.IDNeo, Tacho: datos})
+	})
+	if err != nil {
+		return err
+	}
+
+	if nodo != nil {
+		e.despachar(clave)
+	}
+	return nil
+}
+
+// verificarCustomIDEnNeo4j evita reusar el custom id de un nodo sin fila en
+// MySQL. Se llama antes de abrir la transacción para no retener el bloqueo de
+// MySQL mientras se espera a Neo4j.
+func verificarCustomIDEnNeo4j(customID string) error {
+	nodos, err := buscarTachosNeo4j(customID)
+	if err != nil {
+		return err
+	}
+	if len(nodos) > 0 {
+		return fmt.Errorf("%w: %s", ErrCustomIDDuplicado, customID)
+	}
+	return nil
+}
+
+// verificarCustomIDLibre evita que dos tachos queden con el mismo custom id.
+// Se llama dentro de la transacción de la escritura: el FOR UPDATE retiene a
+// otra escritura con el mismo custom id hasta que esta termine.
+func verificarCustomIDLibre(tx *gorm.DB, customID string) error {
+	var filas int64
+	if err := tx.Model(&models.Tacho{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id_neo = ?", customID).Count(&filas).Error; err != nil {
+		return fmt.Errorf("error counting tachos: %v", err)
+	}
+	if filas > 0 {
+		return fmt.Errorf("%w: %s", ErrCustomIDDuplicado, customID)
+	}
+	return nil
+}
+
+// UpdateTacho cambia tipo, estado, capacidad, prioridad o dirección de un
+// tacho. MySQL se actualiza en una transacción que encola el cambio para
+// Neo4j (ver outbox).
+func UpdateTacho(tachoID int, request UpdateTachoRequest) (*TachoDetalle, error) {
+	if err := ValidarUpdateTacho(request); err != nil {
+		return nil, err
+	}
+
+	fila, err := getFilaTacho(tachoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Solo prioridad y dirección viven en el nodo de Neo4j
+	var nodo *TachoNeo4j
+	if request.Prioridad != nil || request.Direccion != nil {
+		actual, err := estadoNodoTacho(fila)
+		if err != nil {
+			return nil, err
+		}
+		nodo = &actual
+	}
+
+	campos := map[string]interface{}{}
+	if request.IDTipo != nil {
+		campos["id_tipo"] = *request.IDTipo
+	}
+	if request.IDEstado != nil {
+		campos["id_estado"] = *request.IDEstado
+	}
+	if request.Capacidad != nil {
+		campos["capacidad"] = *request.Capacidad
+	}
+	if request.Prioridad != nil {
+		nodo.Prioridad = *request.Prioridad
+	}
+	if request.Direccion != nil {
+		nodo.Direccion = *request.Direccion
+		normalizarUbicacion(nodo)
+	}
+
+	zonas, errZonas := zonasDeTacho(fila.IDNeo)
+	if err := nuevaEscrituraTacho().guardar(fila, campos, nodo); err != nil {
+		return nil, err
+	}
+
+	if errZonas != nil || len(zonas) > 0 {
+		InvalidarRutasZonas(zonas, motivoUpdateTacho(request))
+	}
+	if nodo != nil && nodo.ID != fila.IDNeo {
+		quitarTachoDeMatrices(zonas, fila.IDNeo)
+		agregarTachoAMatrices(Point{Tipo: TipoPuntoTacho, CustomID: nodo.ID, Lat: nodo.Latitude, Lng: nodo.Longitude})
+	}
+
+	return GetTacho(tachoID)
+}
+
+// motivoUpdateTacho es el motivo con el que se invalidan las rutas según el
+// campo que cambió. Si cambian varios se usa el primero en este orden: las
+// rutas se borran una sola vez.
+func motivoUpdateTacho(request UpdateTachoRequest) string {
+	switch {
+	case request.Prioridad != nil:
+		return MotivoPrioridad
+	case request.Capacidad != nil:
+		return MotivoCapacidad
+	case request.Direccion != nil:
+		return MotivoDireccion
+	case request.IDEstado != nil:
+		return MotivoEstado
+	default:
+		return MotivoTipo
+	}
+}
+
+// ReubicarTacho mueve el tacho a otra ubicación. Si cambian dirección o barrio
+// el custom id se actualiza en MySQL, en Neo4j y en las tablas que lo guardan
+// (ver tablasConCustomID); después se recalcula su zona por la nueva ubicación.
+func ReubicarTacho(tachoID int, request ReubicarTachoRequest) (*TachoDetalle, error) {
+	if err := ValidarReubicarTacho(request); err != nil {
+		return nil, err
+	}
+
+	fila, err := getFilaTacho(tachoID)
+	if err != nil {
+		return nil, err
+	}
+	nodo, err := estadoNodoTacho(fila)
+	if err != nil {
+		return nil, err
+	}
+
+	nodo.Latitude = request.Latitude
+	nodo.Longitude = request.Longitude
+	if request.Direccion != "" {
+		nodo.Direccion = request.Direccion
+	}
+	if request.Barrio != "" {
+		nodo.Barrio = request.Barrio
+	}
+	normalizarUbicacion(&nodo)
+
+	// Las rutas de la zona anterior y de la nueva cambian
+	zonas, errZonas := zonasDeTacho(fila.IDNeo)
+	if err := nuevaEscrituraTacho().guardar(fila, map[string]interface{}{}, &nodo); err != nil {
+		return nil, err
+	}
+
+	if err := asignarTacho(nodo.ID, nodo.Latitude, nodo.Longitude); err != nil {
+		fmt.Printf("Warning asignando zona al tacho %s: %v\n", nodo.ID, err)
+	}
+	nuevas, errNuevas := zonasDeTacho(nodo.ID)
+	if errZonas != nil || errNuevas != nil {
+		InvalidarRutasZonas(nil, MotivoAsignacion)
+	} else {
+		InvalidarRutasZonas(append(zonas, nuevas...), MotivoAsignacion)
+	}
+	quitarTachoDeMatrices(zonas, fila.IDNeo)
+	agregarTachoAMatrices(Point{Tipo: TipoPuntoTacho, CustomID: nodo.ID, Lat: nodo.Latitude, Lng: nodo.Longitude})
+
+	return GetTacho(tachoID)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestValidarUpdateTacho(t *testing.T) {
+	capacidad := 50.0
+	prioridad := 3
+	direccion := "Calle 2"
+	assert.NoError(t, ValidarUpdateTacho(UpdateTachoRequest{Capacidad: &capacidad, Prioridad: &prioridad, Direccion: &direccion}))
+
+	cero := 0
+	vacia := " "
+	assert.ErrorContains(t, ValidarUpdateTacho(UpdateTachoRequest{}), "ningún campo")
+	assert.ErrorContains(t, ValidarUpdateTacho(UpdateTachoRequest{IDTipo: &cero}), "id_tipo")
+	assert.ErrorContains(t, ValidarUpdateTacho(UpdateTachoRequest{Prioridad: &cero}), "prioridad")
+	assert.ErrorContains(t, ValidarUpdateTacho(UpdateTachoRequest{Direccion: &vacia}), "direccion vacío")
+}
+
+func TestValidarReubicarTacho(t *testing.T) {
+	assert.NoError(t, ValidarReubicarTacho(ReubicarTachoRequest{Latitude: -34.6, Longitude: -58.4, Barrio: "BOEDO"}))
+
+	assert.ErrorContains(t, ValidarReubicarTacho(ReubicarTachoRequest{}), "falta la ubicación")
+	assert.ErrorContains(t, ValidarReubicarTacho(ReubicarTachoRequest{Latitude: -134.6, Longitude: -58.4}), "fuera de rango")
+	assert.ErrorContains(t, ValidarReubicarTacho(ReubicarTachoRequest{Latitude: -34.6, Longitude: -58.4, Barrio: "A|B"}), "'|'")
+}
+
+func TestNormalizarUbicacion(t *testing.T) {
+	nodo := TachoNeo4j{Direccion: " Calle 2 ", Barrio: " boedo"}
+
+	normalizarUbicacion(&nodo)
+
+	assert.Equal(t, "Calle 2", nodo.Direccion)
+	assert.Equal(t, "BOEDO", nodo.Barrio)
+	assert.Equal(t, "Calle 2|BOEDO", nodo.ID)
+}
+
+// escrituraDePrueba registra las escrituras y rechaza los custom ids ocupados
+func escrituraDePrueba(ocupados ...string) (escrituraTacho, *[]string, *[]outboxGuardarTachoPayload, *bool) {
+	escrituras := []string{}
+	encolados := []outboxGuardarTachoPayload{}
+	despachado := false
+	e := escrituraTacho{
+		transaccion:     func(fn func(tx *gorm.DB) error) error { return fn(nil) },
+		customIDEnNeo4j: func(customID string) error { return nil },
+		customIDLibre: func(tx *gorm.DB, customID string) error {
+			for _, ocupado := range ocupados {
+				if customID == ocupado {
+					return fmt.Errorf("%w: %s", ErrCustomIDDuplicado, customID)
+				}
+			}
+			return nil
+		},
+		actualizar: func(tx *gorm.DB, tabla, columna string, valor interface{}, campos map[string]interface{}) error {
+			escrituras = append(escrituras, fmt.Sprintf("%s %s=%v %v", tabla, columna, valor, campos))
+			return nil
+		},
+		encolar: func(tx *gorm.DB, clave string, payload outboxGuardarTachoPayload) error {
+			encolados = append(encolados, payload)
+			return nil
+		},
+		despachar: func(clave string) { despachado = true },
+	}
+	return e, &escrituras, &encolados, &despachado
+}
+
+func TestEscrituraTachoRenombraElCustomID(t *testing.T) {
+	e, escrituras, encolados, despachado := escrituraDePrueba()
+	fila := &models.Tacho{IDTacho: 7, IDNeo: "Calle 1|BOEDO"}
+	nodo := &TachoNeo4j{NodeID: "n7", ID: "Calle 2|BOEDO", Direccion: "Calle 2", Barrio: "BOEDO"}
+
+	require.NoError(t, e.guardar(fila, map[string]interface{}{"capacidad": 40.0}, nodo))
+
+	assert.Equal(t, []string{
+		"Tacho id_tacho=7 map[capacidad:40 id_neo:Calle 2|BOEDO]",
+		"Tacho_zona id_neo=Calle 1|BOEDO map[id_neo:Calle 2|BOEDO]",
+		"Tacho_ventana id_neo=Calle 1|BOEDO map[id_neo:Calle 2|BOEDO]",
+		"Ruta_parada id_neo=Calle 1|BOEDO map[id_neo:Calle 2|BOEDO]",
+	}, *escrituras)
+	require.Len(t, *encolados, 1)
+	assert.Equal(t, "Calle 1|BOEDO", (*encolados)[0].IDActual, "el nodo se busca por el custom id anterior")
+	assert.Equal(t, "Calle 2|BOEDO", (*encolados)[0].Tacho.ID)
+	assert.Empty(t, (*encolados)[0].Tacho.NodeID)
+	assert.True(t, *despachado)
+}
+
+func TestEscrituraTachoCustomIDOcupado(t *testing.T) {
+	e, escrituras, encolados, despachado := escrituraDePrueba("Calle 2|BOEDO")
+	fila := &models.Tacho{IDTacho: 7, IDNeo: "Calle 1|BOEDO"}
+	nodo := &TachoNeo4j{ID: "Calle 2|BOEDO"}
+
+	err := e.guardar(fila, map[string]interface{}{}, nodo)
+
+	assert.ErrorIs(t, err, ErrCustomIDDuplicado)
+	assert.Empty(t, *escrituras)
+	assert.Empty(t, *encolados)
+	assert.False(t, *despachado)
+}
+
+func TestEscrituraTachoVerificaNeo4jAntesDeLaTransaccion(t *testing.T) {
+	e, _, encolados, _ := escrituraDePrueba()
+	pasos := []string{}
+	transaccion := e.transaccion
+	e.transaccion = func(fn func(tx *gorm.DB) error) error {
+		pasos = append(pasos, "transaccion")
+		return transaccion(fn)
+	}
+	e.customIDEnNeo4j = func(customID string) error {
+		pasos = append(pasos, "neo4j "+customID)
+		return fmt.Errorf("%w: %s", ErrCustomIDDuplicado, customID)
+	}
+
+	err := e.guardar(&models.Tacho{IDTacho: 7, IDNeo: "Calle 1|BOEDO"}, map[string]interface{}{}, &TachoNeo4j{ID: "Calle 2|BOEDO"})
+
+	assert.ErrorIs(t, err, ErrCustomIDDuplicado)
+	assert.Equal(t, []string{"neo4j Calle 2|BOEDO"}, pasos, "no se abre la transacción")
+	assert.Empty(t, *encolados)
+}
+
+func TestEscrituraTachoSinNodoNoEncola(t *testing.T) {
+	e, escrituras, encolados, despachado := escrituraDePrueba()
+	fila := &models.Tacho{IDTacho: 7, IDNeo: "Calle 1|BOEDO"}
+
+	require.NoError(t, e.guardar(fila, map[string]interface{}{"id_estado": 2}, nil))
+
+	assert.Equal(t, []string{"Tacho id_tacho=7 map[id_estado:2]"}, *escrituras)
+	assert.Empty(t, *encolados)
+	assert.False(t, *despachado)
+}
+
+func TestEscrituraTachoFallaLaTransaccion(t *testing.T) {
+	e, _, _, despachado := escrituraDePrueba()
+	e.encolar = func(tx *gorm.DB, clave string, payload outboxGuardarTachoPayload) error {
+		return errors.New("outbox caído")
+	}
+
+	err := e.guardar(&models.Tacho{IDTacho: 7, IDNeo: "Calle 1|BOEDO"}, map[string]interface{}{}, &TachoNeo4j{ID: "Calle 1|BOEDO"})
+
+	assert.EqualError(t, err, "outbox caído")
+	assert.False(t, *despachado, "sin commit no se despacha")
+}
+
+func TestMotivoUpdateTacho(t *testing.T) {
+	uno := 1
+	capacidad := 30.0
+	assert.Equal(t, MotivoTipo, motivoUpdateTacho(UpdateTachoRequest{IDTipo: &uno}))
+	assert.Equal(t, MotivoEstado, motivoUpdateTacho(UpdateTachoRequest{IDTipo: &uno, IDEstado: &uno}))
+	assert.Equal(t, MotivoCapacidad, motivoUpdateTacho(UpdateTachoRequest{IDEstado: &uno, Capacidad: &capacidad}))
+	assert.Equal(t, MotivoPrioridad, motivoUpdateTacho(UpdateTachoRequest{Prioridad: &uno, Capacidad: &capacidad}))
+}
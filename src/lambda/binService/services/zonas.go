@@ -1 +1,416 @@
 package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/config"
+	"github.com/ezequielNavarrete/IntegracionDeAplicaciones2/src/lambda/binService/models"
+	"gorm.io/gorm"
+)
+
+// GetBarriosByZona obtiene los barrios que cubre una zona desde MySQL.
+// Si la zona no tiene barrios cargados en Zona_barrio se usa su nombre.
+func GetBarriosByZona(zonaID int) ([]string, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var zona config.ZonaDisponible
+	result := config.DB.Raw("SELECT id_zona, nombre FROM Zona WHERE id_zona = ?", zonaID).Scan(&zona)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying zona: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("zona with ID %d not found", zonaID)
+	}
+
+	var barrios []string
+	if err := config.DB.Raw("SELECT barrio FROM Zona_barrio WHERE id_zona = ? ORDER BY barrio", zonaID).Scan(&barrios).Error; err != nil {
+		return nil, fmt.Errorf("error querying barrios de la zona: %v", err)
+	}
+
+	if len(barrios) == 0 && strings.TrimSpace(zona.Nombre) != "" {
+		barrios = []string{strings.ToUpper(strings.TrimSpace(zona.Nombre))}
+	}
+
+	return barrios, nil
+}
+
+// Zona representa una zona con sus barrios, límite y camiones asignados
+type Zona struct {
+	IDZona   int             `json:"id_zona"`
+	Nombre   string          `json:"nombre"`
+	Barrios  []string        `json:"barrios"`
+	Limite   json.RawMessage `json:"limite,omitempty" swaggertype:"object"`
+	Camiones []int           `json:"camiones"`
+}
+
+// ZonaRequest representa los datos para crear o reemplazar una zona
+type ZonaRequest struct {
+	Nombre   string          `json:"nombre" example:"Chacarita"`
+	Barrios  []string        `json:"barrios" example:"CHACARITA"`
+	Limite   json.RawMessage `json:"limite,omitempty" swaggertype:"object"`
+	Camiones []int           `json:"camiones" example:"1"`
+}
+
+// Estructura para respuesta de zonas
+type ZonasResponse struct {
+	Zonas []Zona `json:"zonas"`
+	Total int    `json:"total"`
+}
+
+// Estructura para respuesta de una zona individual
+type ZonaResponse struct {
+	Zona Zona `json:"zona"`
+}
+
+// ZonaResumen representa el estado operativo de una zona
+type ZonaResumen struct {
+	Zona
+	CantidadTachos    int      `json:"cantidad_tachos"`
+	CapacidadPromedio float64  `json:"capacidad_promedio"`
+	CantidadPersonas  int      `json:"cantidad_personas"`
+	Personas          []string `json:"personas"`
+}
+
+// GetAllZonas obtiene todas las zonas con sus barrios, límites y camiones
+func GetAllZonas() (*ZonasResponse, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var rows []config.ZonaDisponible
+	if err := config.DB.Raw("SELECT id_zona, nombre FROM Zona ORDER BY id_zona").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("error querying zonas: %v", err)
+	}
+
+	zonas := []Zona{}
+	for _, row := range rows {
+		zona, err := completarZona(row)
+		if err != nil {
+			return nil, err
+		}
+		zonas = append(zonas, *zona)
+	}
+
+	return &ZonasResponse{
+		Zonas: zonas,
+		Total: len(zonas),
+	}, nil
+}
+
+// GetZonaByID obtiene una zona específica por ID
+func GetZonaByID(zonaID int) (*ZonaResponse, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+
+	var row config.ZonaDisponible
+	result := config.DB.Raw("SELECT id_zona, nombre FROM Zona WHERE id_zona = ?", zonaID).Scan(&row)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying zona: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("zona with ID %d not found", zonaID)
+	}
+
+	zona, err := completarZona(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZonaResponse{Zona: *zona}, nil
+}
+
+// completarZona agrega barrios, límite y camiones a una fila de la tabla Zona
+func completarZona(row config.ZonaDisponible) (*Zona, error) {
+	zona := &Zona{
+		IDZona:   row.ID,
+		Nombre:   row.Nombre,
+		Barrios:  []string{},
+		Camiones: []int{},
+	}
+
+	if err := config.DB.Raw("SELECT barrio FROM Zona_barrio WHERE id_zona = ? ORDER BY barrio", row.ID).Scan(&zona.Barrios).Error; err != nil {
+		return nil, fmt.Errorf("error querying barrios de la zona %d: %v", row.ID, err)
+	}
+
+	if err := config.DB.Raw("SELECT id_camion FROM Zona_camion WHERE id_zona = ? ORDER BY id_camion", row.ID).Scan(&zona.Camiones).Error; err != nil {
+		return nil, fmt.Errorf("error querying camiones de la zona %d: %v", row.ID, err)
+	}
+
+	var limite models.ZonaLimite
+	result := config.DB.Where("id_zona = ?", row.ID).Limit(1).Find(&limite)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error querying límite de la zona %d: %v", row.ID, result.Error)
+	}
+	if result.RowsAffected > 0 && limite.GeoJSON != "" {
+		zona.Limite = json.RawMessage(limite.GeoJSON)
+	}
+
+	return zona, nil
+}
+
+// ValidarZonaRequest normaliza y valida los datos de una zona
+func ValidarZonaRequest(request *ZonaRequest) error {
+	request.Nombre = strings.TrimSpace(request.Nombre)
+	if request.Nombre == "" {
+		return fmt.Errorf("el nombre de la zona es requerido")
+	}
+
+	// Los barrios se guardan igual que en Neo4j: en mayúsculas y sin repetir
+	vistos := make(map[string]bool)
+	barrios := []string{}
+	for _, barrio := range request.Barrios {
+		barrio = strings.ToUpper(strings.TrimSpace(barrio))
+		if barrio == "" || vistos[barrio] {
+			continue
+		}
+		vistos[barrio] = true
+		barrios = append(barrios, barrio)
+	}
+	request.Barrios = barrios
+
+	if len(request.Limite) > 0 && string(request.Limite) != "null" {
+		if _, err := parseLimite(request.Limite); err != nil {
+			return err
+		}
+	} else {
+		request.Limite = nil
+	}
+
+	return nil
+}
+
+// validarCamiones verifica que todos los camiones existan en MySQL
+func validarCamiones(tx *gorm.DB, camiones []int) error {
+	if len(camiones) == 0 {
+		return nil
+	}
+
+	var existentes []int
+	if err := tx.Raw("SELECT id_camion FROM Camiones WHERE id_camion IN ?", camiones).Scan(&existentes).Error; err != nil {
+		return fmt.Errorf("error querying camiones: %v", err)
+	}
+
+	encontrados := make(map[int]bool)
+	for _, id := range existentes {
+		encontrados[id] = true
+	}
+	for _, id := range camiones {
+		if !encontrados[id] {
+			return fmt.Errorf("camion with ID %d not found", id)
+		}
+	}
+	return nil
+}
+
+// guardarDetalleZona reemplaza barrios, límite y camiones de una zona
+func guardarDetalleZona(tx *gorm.DB, zonaID int, request ZonaRequest) error {
+	if err := validarCamiones(tx, request.Camiones); err != nil {
+		return err
+	}
+
+	id := int64(zonaID)
+	if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaBarrio{}).Error; err != nil {
+		return fmt.Errorf("error deleting barrios: %v", err)
+	}
+	for _, barrio := range request.Barrios {
+		if err := tx.Create(&models.ZonaBarrio{IDZona: id, Barrio: barrio}).Error; err != nil {
+			return fmt.Errorf("error inserting barrio %s: %v", barrio, err)
+		}
+	}
+
+	if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaCamion{}).Error; err != nil {
+		return fmt.Errorf("error deleting camiones: %v", err)
+	}
+	vistos := make(map[int]bool)
+	for _, camionID := range request.Camiones {
+		if vistos[camionID] {
+			continue
+		}
+		vistos[camionID] = true
+		if err := tx.Create(&models.ZonaCamion{IDZona: id, IDCamion: int64(camionID)}).Error; err != nil {
+			return fmt.Errorf("error inserting camion %d: %v", camionID, err)
+		}
+	}
+
+	if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaLimite{}).Error; err != nil {
+		return fmt.Errorf("error deleting límite: %v", err)
+	}
+	if request.Limite != nil {
+		if err := tx.Create(&models.ZonaLimite{IDZona: id, GeoJSON: string(request.Limite)}).Error; err != nil {
+			return fmt.Errorf("error inserting límite: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateZona crea una zona con sus barrios, límite y camiones
+func CreateZona(request ZonaRequest) (*ZonaResponse, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if err := ValidarZonaRequest(&request); err != nil {
+		return nil, err
+	}
+
+	var zonaID int64
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO Zona (nombre) VALUES (?)", request.Nombre).Error; err != nil {
+			return fmt.Errorf("error inserting zona: %v", err)
+		}
+		if err := tx.Raw("SELECT LAST_INSERT_ID()").Scan(&zonaID).Error; err != nil {
+			return fmt.Errorf("error getting inserted ID: %v", err)
+		}
+		return guardarDetalleZona(tx, int(zonaID), request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if request.Limite != nil {
+		reasignarTachos()
+	}
+
+	return GetZonaByID(int(zonaID))
+}
+
+// UpdateZona reemplaza los datos de una zona existente
+func UpdateZona(zonaID int, request ZonaRequest) (*ZonaResponse, error) {
+	if config.DB == nil {
+		return nil, fmt.Errorf("database connection not available")
+	}
+	if err := ValidarZonaRequest(&request); err != nil {
+		return nil, err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Raw("SELECT COUNT(*) FROM Zona WHERE id_zona = ?", zonaID).Scan(&count).Error; err != nil {
+			return fmt.Errorf("error querying zona: %v", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("zona with ID %d not found", zonaID)
+		}
+
+		if err := tx.Exec("UPDATE Zona SET nombre = ? WHERE id_zona = ?", request.Nombre, zonaID).Error; err != nil {
+			return fmt.Errorf("error updating zona: %v", err)
+		}
+		return guardarDetalleZona(tx, zonaID, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	reasignarTachos()
+
+	return GetZonaByID(zonaID)
+}
+
+// DeleteZona elimina una zona junto con sus barrios, límite y camiones asignados
+func DeleteZona(zonaID int) error {
+	if config.DB == nil {
+		return fmt.Errorf("database connection not available")
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		id := int64(zonaID)
+		if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaBarrio{}).Error; err != nil {
+			return fmt.Errorf("error deleting barrios: %v", err)
+		}
+		if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaCamion{}).Error; err != nil {
+			return fmt.Errorf("error deleting camiones: %v", err)
+		}
+		if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaLimite{}).Error; err != nil {
+			return fmt.Errorf("error deleting límite: %v", err)
+		}
+		if err := tx.Where("id_zona = ?", id).Delete(&models.TachoZona{}).Error; err != nil {
+			return fmt.Errorf("error deleting tachos asignados: %v", err)
+		}
+		if err := tx.Where("id_zona = ?", id).Delete(&models.ZonaCalendario{}).Error; err != nil {
+			return fmt.Errorf("error deleting calendario: %v", err)
+		}
+		if err := tx.Where("id_zona = ?", id).Delete(&models.Feriado{}).Error; err != nil {
+			return fmt.Errorf("error deleting feriados: %v", err)
+		}
+
+		result := tx.Exec("DELETE FROM Zona WHERE id_zona = ?", zonaID)
+		if result.Error != nil {
+			return fmt.Errorf("error deleting zona: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("zona with ID %d not found", zonaID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Los tachos que estaban en la superposición pueden quedar en una sola zona
+	reasignarTachos()
+	return nil
+}
+
+// GetZonaResumen obtiene la zona con cantidad de tachos, llenado promedio y personas asignadas
+func GetZonaResumen(zonaID int) (*ZonaResumen, error) {
+	response, err := GetZonaByID(zonaID)
+	if err != nil {
+		return nil, err
+	}
+
+	resumen := &ZonaResumen{Zona: response.Zona, Personas: []string{}}
+
+	points, err := getTachoPointsByZona(zonaID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting tachos de la zona: %v", err)
+	}
+
+	resumen.CantidadTachos = len(points)
+	if len(points) > 0 {
+		total := 0.0
+		for _, p := range points {
+			total += p.Capacidad
+		}
+		resumen.CapacidadPromedio = total / float64(len(points))
+	}
+
+	personas, err := getPersonasByZona(zonaID)
+	if err != nil {
+		return nil, err
+	}
+	resumen.Personas = personas
+	resumen.CantidadPersonas = len(personas)
+
+	return resumen, nil
+}
+
+// getPersonasByZona obtiene los IDs de las personas de Redis asignadas a una zona
+func getPersonasByZona(zonaID int) ([]string, error) {
+	if config.RedisClient == nil {
+		return nil, fmt.Errorf("redis client not available")
+	}
+
+	ctx := context.Background()
+	personaKeys, err := config.RedisClient.LRange(ctx, "personas", 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo personas: %v", err)
+	}
+
+	personas := []string{}
+	for _, personaKey := range personaKeys {
+		personData, err := config.RedisClient.HGetAll(ctx, personaKey).Result()
+		if err != nil {
+			continue
+		}
+		if personData["zona_id"] == strconv.Itoa(zonaID) {
+			personas = append(personas, personData["id"])
+		}
+	}
+
+	return personas, nil
+}